@@ -0,0 +1,85 @@
+package ast
+
+import (
+	"fmt"
+
+	"github.com/perlmonger42/go-lox/token"
+)
+
+func ExampleApply() {
+	tree := program()
+	// Replace b by 2, then, after visiting its children, each Print
+	// statement by the Expression statement of its expression.
+	result := Apply(tree, func(c *Cursor) bool {
+		if v, ok := c.Node().(*Variable); ok && v.Name.Lexeme() == "b" {
+			c.Replace(&Literal{Value: token.NumberValue{2}})
+		}
+		return true
+	}, func(c *Cursor) bool {
+		if p, ok := c.Node().(*Print); ok {
+			c.Replace(&Expression{Expression: p.Expression})
+			fmt.Printf("replaced %T.%s\n", c.Parent(), c.Name())
+		}
+		return true
+	})
+	fmt.Println(result == tree)
+	fmt.Println(ToString(result))
+	// Output:
+	// replaced *ast.If.ThenBranch
+	// true
+	// fun (a) {
+	//   if (a)
+	//     (+ a 2);
+	// else
+	//     return;
+	// }
+}
+
+func ExampleApply_delete() {
+	block := &Block{Statements: []Stmt{
+		&Print{Expression: &Variable{name("a")}},
+		&Noop{},
+		&Noop{},
+		&Print{Expression: &Variable{name("b")}},
+	}}
+	Apply(block, func(c *Cursor) bool {
+		if _, ok := c.Node().(*Noop); ok {
+			fmt.Println("deleting", c.Name(), c.Index())
+			c.Delete()
+		}
+		return true
+	}, nil)
+	fmt.Println(ToString(block))
+	// Output:
+	// deleting Statements 1
+	// deleting Statements 1
+	// {
+	//   print a;
+	//   print b;
+	// }
+}
+
+func ExampleClone() {
+	tree := program()
+	clone := Clone(tree)
+	fmt.Println(clone != tree, Equal(clone, tree))
+
+	// Changing the clone leaves the original alone.
+	Inspect(clone, func(n Node) bool {
+		if v, ok := n.(*Variable); ok {
+			v.Name = name("z")
+		}
+		return true
+	})
+	fmt.Println(Equal(clone, tree))
+	fmt.Println(ToString(tree))
+	// Output:
+	// true true
+	// false
+	// fun (a) {
+	//   if (a)
+	//     print (+ a b);
+	// else
+	//     return;
+	// }
+}
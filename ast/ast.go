@@ -1,5 +1,47 @@
 package ast
 
+//go:generate go run ../cmd/generate-ast
+
+import (
+	"github.com/perlmonger42/go-lox/token"
+)
+
 type Node interface {
 	AsNode() Node // does nothing but prevent non-Nodes from looking like a Node
 }
+
+// FunctionKind distinguishes ordinary functions and methods from property
+// accessors, which are invoked implicitly by reading or assigning a property.
+type FunctionKind int
+
+const (
+	OrdinaryFunction FunctionKind = iota // `name(params) { ... }`
+	GetterFunction                       // `name { ... }`
+	SetterFunction                       // `set name(value) { ... }`
+)
+
+// MemberName returns the name under which a class or trait keeps method f.
+// A setter's name is prefixed with "set ", since it may share its name with
+// a getter.
+func (f *Function) MemberName() string {
+	if f.Kind == SetterFunction {
+		return "set " + f.Name.Lexeme()
+	}
+	return f.Name.Lexeme()
+}
+
+// equalTokens reports whether two tokens are the same, wherever they are.
+func equalTokens(a, b token.T) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Type() == b.Type() && a.Lexeme() == b.Lexeme()
+}
+
+// equalValues reports whether two literal values are the same.
+func equalValues(a, b token.Value) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.TypeName() == b.TypeName() && a.IsEqualTo(b)
+}
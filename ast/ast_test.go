@@ -10,9 +10,9 @@ func ExampleParens() {
 		token.New(token.Star, "*", nil, token.NewPos(1)),
 		&Unary{
 			token.New(token.Minus, "-", nil, token.NewPos(1)),
-			&Literal{&token.NumberValue{123.0}},
+			&Literal{Value: &token.NumberValue{123.0}},
 		},
-		&Grouping{&Literal{&token.NumberValue{45.67}}},
+		&Grouping{Expression: &Literal{Value: &token.NumberValue{45.67}}},
 	}
 
 	fmt.Println(ToString(expression))
@@ -22,8 +22,8 @@ func ExampleParens() {
 
 func ExampleIf() {
 	stmt := &If{
-		Condition:  &Literal{&token.BooleanValue{true}},
-		ThenBranch: &Expression{&Literal{&token.NumberValue{1.0}}},
+		Condition:  &Literal{Value: &token.BooleanValue{true}},
+		ThenBranch: &Expression{Expression: &Literal{Value: &token.NumberValue{1.0}}},
 		ElseBranch: nil,
 	}
 
@@ -35,9 +35,9 @@ func ExampleIf() {
 
 func ExampleIfElse() {
 	stmt := &If{
-		Condition:  &Literal{&token.BooleanValue{true}},
-		ThenBranch: &Expression{&Literal{&token.NumberValue{1.0}}},
-		ElseBranch: &Expression{&Literal{&token.NumberValue{2.0}}},
+		Condition:  &Literal{Value: &token.BooleanValue{true}},
+		ThenBranch: &Expression{Expression: &Literal{Value: &token.NumberValue{1.0}}},
+		ElseBranch: &Expression{Expression: &Literal{Value: &token.NumberValue{2.0}}},
 	}
 
 	fmt.Println(ToString(stmt))
@@ -50,11 +50,11 @@ func ExampleIfElse() {
 
 func ExampleIfElseIf() {
 	stmt := &If{
-		Condition:  &Literal{&token.BooleanValue{true}},
-		ThenBranch: &Expression{&Literal{&token.NumberValue{1.0}}},
+		Condition:  &Literal{Value: &token.BooleanValue{true}},
+		ThenBranch: &Expression{Expression: &Literal{Value: &token.NumberValue{1.0}}},
 		ElseBranch: &If{
-			Condition:  &Literal{&token.BooleanValue{false}},
-			ThenBranch: &Expression{&Literal{&token.NumberValue{2.0}}},
+			Condition:  &Literal{Value: &token.BooleanValue{false}},
+			ThenBranch: &Expression{Expression: &Literal{Value: &token.NumberValue{2.0}}},
 			ElseBranch: nil,
 		},
 	}
@@ -69,20 +69,20 @@ func ExampleIfElseIf() {
 
 func ExampleBlockishIfElseIfElse() {
 	stmt := &If{
-		Condition: &Literal{&token.BooleanValue{true}},
+		Condition: &Literal{Value: &token.BooleanValue{true}},
 		ThenBranch: &Block{
-			&token.Token{Type_: token.LeftBrace},
-			[]Stmt{&Expression{&Literal{&token.NumberValue{1.0}}}},
+			Token:      &token.Token{Type_: token.LeftBrace},
+			Statements: []Stmt{&Expression{Expression: &Literal{Value: &token.NumberValue{1.0}}}},
 		},
 		ElseBranch: &If{
-			Condition: &Literal{&token.BooleanValue{false}},
+			Condition: &Literal{Value: &token.BooleanValue{false}},
 			ThenBranch: &Block{
-				&token.Token{Type_: token.LeftBrace},
-				[]Stmt{&Expression{&Literal{&token.NumberValue{2.0}}}},
+				Token:      &token.Token{Type_: token.LeftBrace},
+				Statements: []Stmt{&Expression{Expression: &Literal{Value: &token.NumberValue{2.0}}}},
 			},
 			ElseBranch: &Block{
-				&token.Token{Type_: token.LeftBrace},
-				[]Stmt{&Expression{&Literal{&token.NumberValue{3.0}}}},
+				Token:      &token.Token{Type_: token.LeftBrace},
+				Statements: []Stmt{&Expression{Expression: &Literal{Value: &token.NumberValue{3.0}}}},
 			},
 		},
 	}
@@ -100,8 +100,8 @@ func ExampleBlockishIfElseIfElse() {
 
 func ExampleWhile() {
 	stmt := &While{
-		Condition: &Literal{&token.BooleanValue{false}},
-		Body:      &Expression{&Literal{&token.NumberValue{1.0}}},
+		Condition: &Literal{Value: &token.BooleanValue{false}},
+		Body:      &Expression{Expression: &Literal{Value: &token.NumberValue{1.0}}},
 	}
 
 	fmt.Println(ToString(stmt))
@@ -112,10 +112,10 @@ func ExampleWhile() {
 
 func ExampleBlockishWhile() {
 	stmt := &While{
-		Condition: &Literal{&token.BooleanValue{false}},
+		Condition: &Literal{Value: &token.BooleanValue{false}},
 		Body: &Block{
-			&token.Token{Type_: token.LeftBrace},
-			[]Stmt{&Expression{&Literal{&token.NumberValue{1.0}}}},
+			Token:      &token.Token{Type_: token.LeftBrace},
+			Statements: []Stmt{&Expression{Expression: &Literal{Value: &token.NumberValue{1.0}}}},
 		},
 	}
 
@@ -0,0 +1,798 @@
+// Code generated by generate-ast from ast.schema; DO NOT EDIT.
+
+package ast
+
+import (
+	"fmt"
+	"github.com/perlmonger42/go-lox/token"
+)
+
+// Clone returns a deep copy of a syntax tree: every node in it is copied,
+// though the tokens and literal values they hold are shared. The copy
+// must be resolved before it is run, since the interpreter finds variables
+// by node. Clone(nil) is nil.
+func Clone(node Node) Node {
+	if isNil(node) {
+		return node
+	}
+	switch n := node.(type) {
+	case *Grouping:
+		c := *n
+		if n.Expression != nil {
+			c.Expression = toExpr(Clone(n.Expression))
+		}
+		return &c
+	case *This:
+		c := *n
+		return &c
+	case *Super:
+		c := *n
+		return &c
+	case *Variable:
+		c := *n
+		return &c
+	case *Literal:
+		c := *n
+		return &c
+	case *Call:
+		c := *n
+		if n.Callee != nil {
+			c.Callee = toExpr(Clone(n.Callee))
+		}
+		if n.Arguments != nil {
+			c.Arguments = make([]Expr, len(n.Arguments))
+			for i, x := range n.Arguments {
+				c.Arguments[i] = toExpr(Clone(x))
+			}
+		}
+		return &c
+	case *Get:
+		c := *n
+		if n.Object != nil {
+			c.Object = toExpr(Clone(n.Object))
+		}
+		return &c
+	case *Unary:
+		c := *n
+		if n.Right != nil {
+			c.Right = toExpr(Clone(n.Right))
+		}
+		return &c
+	case *Binary:
+		c := *n
+		if n.Left != nil {
+			c.Left = toExpr(Clone(n.Left))
+		}
+		if n.Right != nil {
+			c.Right = toExpr(Clone(n.Right))
+		}
+		return &c
+	case *Logical:
+		c := *n
+		if n.Left != nil {
+			c.Left = toExpr(Clone(n.Left))
+		}
+		if n.Right != nil {
+			c.Right = toExpr(Clone(n.Right))
+		}
+		return &c
+	case *Set:
+		c := *n
+		if n.Object != nil {
+			c.Object = toExpr(Clone(n.Object))
+		}
+		if n.Value != nil {
+			c.Value = toExpr(Clone(n.Value))
+		}
+		return &c
+	case *Assign:
+		c := *n
+		if n.Value != nil {
+			c.Value = toExpr(Clone(n.Value))
+		}
+		return &c
+	case *Index:
+		c := *n
+		if n.Object != nil {
+			c.Object = toExpr(Clone(n.Object))
+		}
+		if n.Index != nil {
+			c.Index = toExpr(Clone(n.Index))
+		}
+		return &c
+	case *SetIndex:
+		c := *n
+		if n.Object != nil {
+			c.Object = toExpr(Clone(n.Object))
+		}
+		if n.Index != nil {
+			c.Index = toExpr(Clone(n.Index))
+		}
+		if n.Value != nil {
+			c.Value = toExpr(Clone(n.Value))
+		}
+		return &c
+	case *BadExpr:
+		c := *n
+		return &c
+	case *Noop:
+		c := *n
+		return &c
+	case *Expression:
+		c := *n
+		if n.Expression != nil {
+			c.Expression = toExpr(Clone(n.Expression))
+		}
+		return &c
+	case *Print:
+		c := *n
+		if n.Expression != nil {
+			c.Expression = toExpr(Clone(n.Expression))
+		}
+		return &c
+	case *Return:
+		c := *n
+		if n.Value != nil {
+			c.Value = toExpr(Clone(n.Value))
+		}
+		return &c
+	case *BadStmt:
+		c := *n
+		return &c
+	case *VarInitialized:
+		c := *n
+		if n.Initializer != nil {
+			c.Initializer = toExpr(Clone(n.Initializer))
+		}
+		return &c
+	case *VarUninitialized:
+		c := *n
+		return &c
+	case *Function:
+		c := *n
+		if n.Params != nil {
+			c.Params = append([]token.T{}, n.Params...)
+		}
+		if n.Body != nil {
+			c.Body = make([]Stmt, len(n.Body))
+			for i, x := range n.Body {
+				c.Body[i] = toStmt(Clone(x))
+			}
+		}
+		if n.ParamTypes != nil {
+			c.ParamTypes = append([]token.T{}, n.ParamTypes...)
+		}
+		return &c
+	case *If:
+		c := *n
+		if n.Condition != nil {
+			c.Condition = toExpr(Clone(n.Condition))
+		}
+		if n.ThenBranch != nil {
+			c.ThenBranch = toStmt(Clone(n.ThenBranch))
+		}
+		if n.ElseBranch != nil {
+			c.ElseBranch = toStmt(Clone(n.ElseBranch))
+		}
+		return &c
+	case *Block:
+		c := *n
+		if n.Statements != nil {
+			c.Statements = make([]Stmt, len(n.Statements))
+			for i, x := range n.Statements {
+				c.Statements[i] = toStmt(Clone(x))
+			}
+		}
+		return &c
+	case *While:
+		c := *n
+		if n.Condition != nil {
+			c.Condition = toExpr(Clone(n.Condition))
+		}
+		if n.Body != nil {
+			c.Body = toStmt(Clone(n.Body))
+		}
+		return &c
+	case *Class:
+		c := *n
+		if n.Superclass != nil {
+			c.Superclass = toVariable(Clone(n.Superclass))
+		}
+		if n.Traits != nil {
+			c.Traits = make([]*Variable, len(n.Traits))
+			for i, x := range n.Traits {
+				c.Traits[i] = toVariable(Clone(x))
+			}
+		}
+		if n.Methods != nil {
+			c.Methods = make([]*Function, len(n.Methods))
+			for i, x := range n.Methods {
+				c.Methods[i] = toFunction(Clone(x))
+			}
+		}
+		if n.StaticMethods != nil {
+			c.StaticMethods = make([]*Function, len(n.StaticMethods))
+			for i, x := range n.StaticMethods {
+				c.StaticMethods[i] = toFunction(Clone(x))
+			}
+		}
+		if n.StaticFields != nil {
+			c.StaticFields = make([]*VarInitialized, len(n.StaticFields))
+			for i, x := range n.StaticFields {
+				c.StaticFields[i] = toVarInitialized(Clone(x))
+			}
+		}
+		return &c
+	case *Trait:
+		c := *n
+		if n.Traits != nil {
+			c.Traits = make([]*Variable, len(n.Traits))
+			for i, x := range n.Traits {
+				c.Traits[i] = toVariable(Clone(x))
+			}
+		}
+		if n.Methods != nil {
+			c.Methods = make([]*Function, len(n.Methods))
+			for i, x := range n.Methods {
+				c.Methods[i] = toFunction(Clone(x))
+			}
+		}
+		return &c
+	}
+	panic(fmt.Sprintf("ast.Clone: unexpected node type %T", node))
+}
+
+// Equal reports whether two syntax trees have the same structure: the same
+// types of node, holding equal tokens (by type and lexeme, wherever they
+// are in the source) and equal values.
+func Equal(a, b Node) bool {
+	if isNil(a) || isNil(b) {
+		return isNil(a) && isNil(b)
+	}
+	switch a := a.(type) {
+	case *Grouping:
+		b, ok := b.(*Grouping)
+		if !ok {
+			return false
+		}
+		if !Equal(a.Expression, b.Expression) {
+			return false
+		}
+		if !equalTokens(a.Paren, b.Paren) {
+			return false
+		}
+		if !equalTokens(a.End, b.End) {
+			return false
+		}
+		return true
+	case *This:
+		b, ok := b.(*This)
+		if !ok {
+			return false
+		}
+		if !equalTokens(a.Keyword, b.Keyword) {
+			return false
+		}
+		return true
+	case *Super:
+		b, ok := b.(*Super)
+		if !ok {
+			return false
+		}
+		if !equalTokens(a.Keyword, b.Keyword) {
+			return false
+		}
+		if !equalTokens(a.Method, b.Method) {
+			return false
+		}
+		return true
+	case *Variable:
+		b, ok := b.(*Variable)
+		if !ok {
+			return false
+		}
+		if !equalTokens(a.Name, b.Name) {
+			return false
+		}
+		return true
+	case *Literal:
+		b, ok := b.(*Literal)
+		if !ok {
+			return false
+		}
+		if !equalValues(a.Value, b.Value) {
+			return false
+		}
+		if !equalTokens(a.Token, b.Token) {
+			return false
+		}
+		return true
+	case *Call:
+		b, ok := b.(*Call)
+		if !ok {
+			return false
+		}
+		if !Equal(a.Callee, b.Callee) {
+			return false
+		}
+		if !equalTokens(a.Paren, b.Paren) {
+			return false
+		}
+		if len(a.Arguments) != len(b.Arguments) {
+			return false
+		}
+		for i := range a.Arguments {
+			if !Equal(a.Arguments[i], b.Arguments[i]) {
+				return false
+			}
+		}
+		return true
+	case *Get:
+		b, ok := b.(*Get)
+		if !ok {
+			return false
+		}
+		if !Equal(a.Object, b.Object) {
+			return false
+		}
+		if !equalTokens(a.Name, b.Name) {
+			return false
+		}
+		return true
+	case *Unary:
+		b, ok := b.(*Unary)
+		if !ok {
+			return false
+		}
+		if !equalTokens(a.Operator, b.Operator) {
+			return false
+		}
+		if !Equal(a.Right, b.Right) {
+			return false
+		}
+		return true
+	case *Binary:
+		b, ok := b.(*Binary)
+		if !ok {
+			return false
+		}
+		if !equalTokens(a.Operator, b.Operator) {
+			return false
+		}
+		if !Equal(a.Left, b.Left) {
+			return false
+		}
+		if !Equal(a.Right, b.Right) {
+			return false
+		}
+		return true
+	case *Logical:
+		b, ok := b.(*Logical)
+		if !ok {
+			return false
+		}
+		if !equalTokens(a.Operator, b.Operator) {
+			return false
+		}
+		if !Equal(a.Left, b.Left) {
+			return false
+		}
+		if !Equal(a.Right, b.Right) {
+			return false
+		}
+		return true
+	case *Set:
+		b, ok := b.(*Set)
+		if !ok {
+			return false
+		}
+		if !Equal(a.Object, b.Object) {
+			return false
+		}
+		if !equalTokens(a.Name, b.Name) {
+			return false
+		}
+		if !Equal(a.Value, b.Value) {
+			return false
+		}
+		return true
+	case *Assign:
+		b, ok := b.(*Assign)
+		if !ok {
+			return false
+		}
+		if !equalTokens(a.Name, b.Name) {
+			return false
+		}
+		if !Equal(a.Value, b.Value) {
+			return false
+		}
+		return true
+	case *Index:
+		b, ok := b.(*Index)
+		if !ok {
+			return false
+		}
+		if !Equal(a.Object, b.Object) {
+			return false
+		}
+		if !equalTokens(a.Bracket, b.Bracket) {
+			return false
+		}
+		if !Equal(a.Index, b.Index) {
+			return false
+		}
+		return true
+	case *SetIndex:
+		b, ok := b.(*SetIndex)
+		if !ok {
+			return false
+		}
+		if !Equal(a.Object, b.Object) {
+			return false
+		}
+		if !equalTokens(a.Bracket, b.Bracket) {
+			return false
+		}
+		if !Equal(a.Index, b.Index) {
+			return false
+		}
+		if !Equal(a.Value, b.Value) {
+			return false
+		}
+		return true
+	case *BadExpr:
+		b, ok := b.(*BadExpr)
+		if !ok {
+			return false
+		}
+		if !equalTokens(a.From, b.From) {
+			return false
+		}
+		if !equalTokens(a.To, b.To) {
+			return false
+		}
+		return true
+	case *Noop:
+		_, ok := b.(*Noop)
+		return ok
+	case *Expression:
+		b, ok := b.(*Expression)
+		if !ok {
+			return false
+		}
+		if !Equal(a.Expression, b.Expression) {
+			return false
+		}
+		if !equalTokens(a.End, b.End) {
+			return false
+		}
+		return true
+	case *Print:
+		b, ok := b.(*Print)
+		if !ok {
+			return false
+		}
+		if !equalTokens(a.Keyword, b.Keyword) {
+			return false
+		}
+		if !Equal(a.Expression, b.Expression) {
+			return false
+		}
+		if !equalTokens(a.End, b.End) {
+			return false
+		}
+		return true
+	case *Return:
+		b, ok := b.(*Return)
+		if !ok {
+			return false
+		}
+		if !equalTokens(a.Keyword, b.Keyword) {
+			return false
+		}
+		if !Equal(a.Value, b.Value) {
+			return false
+		}
+		if !equalTokens(a.End, b.End) {
+			return false
+		}
+		return true
+	case *BadStmt:
+		b, ok := b.(*BadStmt)
+		if !ok {
+			return false
+		}
+		if !equalTokens(a.From, b.From) {
+			return false
+		}
+		if !equalTokens(a.To, b.To) {
+			return false
+		}
+		return true
+	case *VarInitialized:
+		b, ok := b.(*VarInitialized)
+		if !ok {
+			return false
+		}
+		if !equalTokens(a.Name, b.Name) {
+			return false
+		}
+		if !Equal(a.Initializer, b.Initializer) {
+			return false
+		}
+		if !equalTokens(a.Type, b.Type) {
+			return false
+		}
+		if !equalTokens(a.Keyword, b.Keyword) {
+			return false
+		}
+		if !equalTokens(a.End, b.End) {
+			return false
+		}
+		return true
+	case *VarUninitialized:
+		b, ok := b.(*VarUninitialized)
+		if !ok {
+			return false
+		}
+		if !equalTokens(a.Name, b.Name) {
+			return false
+		}
+		if !equalTokens(a.Type, b.Type) {
+			return false
+		}
+		if !equalTokens(a.Keyword, b.Keyword) {
+			return false
+		}
+		if !equalTokens(a.End, b.End) {
+			return false
+		}
+		return true
+	case *Function:
+		b, ok := b.(*Function)
+		if !ok {
+			return false
+		}
+		if !equalTokens(a.Name, b.Name) {
+			return false
+		}
+		if len(a.Params) != len(b.Params) {
+			return false
+		}
+		for i := range a.Params {
+			if !equalTokens(a.Params[i], b.Params[i]) {
+				return false
+			}
+		}
+		if len(a.Body) != len(b.Body) {
+			return false
+		}
+		for i := range a.Body {
+			if !Equal(a.Body[i], b.Body[i]) {
+				return false
+			}
+		}
+		if !(a.Kind == b.Kind) {
+			return false
+		}
+		if len(a.ParamTypes) != len(b.ParamTypes) {
+			return false
+		}
+		for i := range a.ParamTypes {
+			if !equalTokens(a.ParamTypes[i], b.ParamTypes[i]) {
+				return false
+			}
+		}
+		if !equalTokens(a.ReturnType, b.ReturnType) {
+			return false
+		}
+		if !equalTokens(a.Keyword, b.Keyword) {
+			return false
+		}
+		if !equalTokens(a.End, b.End) {
+			return false
+		}
+		return true
+	case *If:
+		b, ok := b.(*If)
+		if !ok {
+			return false
+		}
+		if !equalTokens(a.Keyword, b.Keyword) {
+			return false
+		}
+		if !Equal(a.Condition, b.Condition) {
+			return false
+		}
+		if !Equal(a.ThenBranch, b.ThenBranch) {
+			return false
+		}
+		if !Equal(a.ElseBranch, b.ElseBranch) {
+			return false
+		}
+		return true
+	case *Block:
+		b, ok := b.(*Block)
+		if !ok {
+			return false
+		}
+		if !equalTokens(a.Token, b.Token) {
+			return false
+		}
+		if len(a.Statements) != len(b.Statements) {
+			return false
+		}
+		for i := range a.Statements {
+			if !Equal(a.Statements[i], b.Statements[i]) {
+				return false
+			}
+		}
+		if !equalTokens(a.End, b.End) {
+			return false
+		}
+		return true
+	case *While:
+		b, ok := b.(*While)
+		if !ok {
+			return false
+		}
+		if !equalTokens(a.Keyword, b.Keyword) {
+			return false
+		}
+		if !Equal(a.Condition, b.Condition) {
+			return false
+		}
+		if !Equal(a.Body, b.Body) {
+			return false
+		}
+		return true
+	case *Class:
+		b, ok := b.(*Class)
+		if !ok {
+			return false
+		}
+		if !equalTokens(a.Name, b.Name) {
+			return false
+		}
+		if !Equal(a.Superclass, b.Superclass) {
+			return false
+		}
+		if len(a.Traits) != len(b.Traits) {
+			return false
+		}
+		for i := range a.Traits {
+			if !Equal(a.Traits[i], b.Traits[i]) {
+				return false
+			}
+		}
+		if len(a.Methods) != len(b.Methods) {
+			return false
+		}
+		for i := range a.Methods {
+			if !Equal(a.Methods[i], b.Methods[i]) {
+				return false
+			}
+		}
+		if len(a.StaticMethods) != len(b.StaticMethods) {
+			return false
+		}
+		for i := range a.StaticMethods {
+			if !Equal(a.StaticMethods[i], b.StaticMethods[i]) {
+				return false
+			}
+		}
+		if len(a.StaticFields) != len(b.StaticFields) {
+			return false
+		}
+		for i := range a.StaticFields {
+			if !Equal(a.StaticFields[i], b.StaticFields[i]) {
+				return false
+			}
+		}
+		if !equalTokens(a.Keyword, b.Keyword) {
+			return false
+		}
+		if !equalTokens(a.End, b.End) {
+			return false
+		}
+		return true
+	case *Trait:
+		b, ok := b.(*Trait)
+		if !ok {
+			return false
+		}
+		if !equalTokens(a.Name, b.Name) {
+			return false
+		}
+		if len(a.Traits) != len(b.Traits) {
+			return false
+		}
+		for i := range a.Traits {
+			if !Equal(a.Traits[i], b.Traits[i]) {
+				return false
+			}
+		}
+		if len(a.Methods) != len(b.Methods) {
+			return false
+		}
+		for i := range a.Methods {
+			if !Equal(a.Methods[i], b.Methods[i]) {
+				return false
+			}
+		}
+		if !equalTokens(a.Keyword, b.Keyword) {
+			return false
+		}
+		if !equalTokens(a.End, b.End) {
+			return false
+		}
+		return true
+	}
+	panic(fmt.Sprintf("ast.Equal: unexpected node type %T", a))
+}
+
+// isNil reports whether a node is nil, or a nil pointer.
+func isNil(n Node) bool {
+	switch n := n.(type) {
+	case nil:
+		return true
+	case *Grouping:
+		return n == nil
+	case *This:
+		return n == nil
+	case *Super:
+		return n == nil
+	case *Variable:
+		return n == nil
+	case *Literal:
+		return n == nil
+	case *Call:
+		return n == nil
+	case *Get:
+		return n == nil
+	case *Unary:
+		return n == nil
+	case *Binary:
+		return n == nil
+	case *Logical:
+		return n == nil
+	case *Set:
+		return n == nil
+	case *Assign:
+		return n == nil
+	case *Index:
+		return n == nil
+	case *SetIndex:
+		return n == nil
+	case *BadExpr:
+		return n == nil
+	case *Noop:
+		return n == nil
+	case *Expression:
+		return n == nil
+	case *Print:
+		return n == nil
+	case *Return:
+		return n == nil
+	case *BadStmt:
+		return n == nil
+	case *VarInitialized:
+		return n == nil
+	case *VarUninitialized:
+		return n == nil
+	case *Function:
+		return n == nil
+	case *If:
+		return n == nil
+	case *Block:
+		return n == nil
+	case *While:
+		return n == nil
+	case *Class:
+		return n == nil
+	case *Trait:
+		return n == nil
+	}
+	return false
+}
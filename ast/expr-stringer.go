@@ -53,6 +53,15 @@ func (x *toStringVisitor) Visit_SetExpr_String(expr *Set) string {
 		" = " + ExprToString(expr.Value)
 }
 
+func (x *toStringVisitor) Visit_IndexExpr_String(expr *Index) string {
+	return ExprToString(expr.Object) + "[" + ExprToString(expr.Index) + "]"
+}
+
+func (x *toStringVisitor) Visit_SetIndexExpr_String(expr *SetIndex) string {
+	return ExprToString(expr.Object) + "[" + ExprToString(expr.Index) + "]" +
+		" = " + ExprToString(expr.Value)
+}
+
 func (x *toStringVisitor) Visit_UnaryExpr_String(expr *Unary) string {
 	return x.parenthesize(expr.Operator.Lexeme(), expr.Right)
 }
@@ -73,6 +82,10 @@ func (x *toStringVisitor) Visit_AssignExpr_String(expr *Assign) string {
 	return expr.Name.Lexeme() + " = " + ExprToString(expr.Value) + ";"
 }
 
+func (x *toStringVisitor) Visit_BadExprExpr_String(expr *BadExpr) string {
+	return "(bad)"
+}
+
 func (x *toStringVisitor) parenthesize(name string, exprs ...Expr) string {
 	var str strings.Builder
 
@@ -0,0 +1,98 @@
+package ast
+
+import (
+	"github.com/perlmonger42/go-lox/token"
+)
+
+// StmtLine returns the line a statement starts on, or 0 if no token of it
+// says (as for a statement made of literals only).
+func StmtLine(stmt Stmt) int {
+	return tokenLine(StmtToken(stmt))
+}
+
+// ExprLine returns the line of the first token of expr that has one.
+func ExprLine(expr Expr) int {
+	return tokenLine(ExprToken(expr))
+}
+
+// StmtToken returns the token a statement starts at, or if that is unknown,
+// the first of its tokens that knows its line; or nil if none does.
+func StmtToken(stmt Stmt) token.T {
+	switch stmt := stmt.(type) {
+	case *Expression:
+		return ExprToken(stmt.Expression)
+	case *Print:
+		return stmt.Keyword
+	case *Return:
+		return stmt.Keyword
+	case *BadStmt:
+		return stmt.From
+	case *VarInitialized:
+		return stmt.Name
+	case *VarUninitialized:
+		return stmt.Name
+	case *Function:
+		return stmt.Name
+	case *Class:
+		return stmt.Name
+	case *Trait:
+		return stmt.Name
+	case *Block:
+		return stmt.Token
+	case *If:
+		return firstToken(stmt.Keyword, ExprToken(stmt.Condition))
+	case *While:
+		return firstToken(stmt.Keyword, ExprToken(stmt.Condition))
+	}
+	return nil
+}
+
+// ExprToken returns the first token of expr that knows its line, or nil.
+func ExprToken(expr Expr) token.T {
+	switch expr := expr.(type) {
+	case *Grouping:
+		return ExprToken(expr.Expression)
+	case *This:
+		return expr.Keyword
+	case *Super:
+		return expr.Keyword
+	case *Variable:
+		return expr.Name
+	case *Call:
+		return firstToken(ExprToken(expr.Callee), expr.Paren)
+	case *Get:
+		return firstToken(ExprToken(expr.Object), expr.Name)
+	case *Unary:
+		return expr.Operator
+	case *Binary:
+		return firstToken(ExprToken(expr.Left), expr.Operator)
+	case *Logical:
+		return firstToken(ExprToken(expr.Left), expr.Operator)
+	case *Set:
+		return firstToken(ExprToken(expr.Object), expr.Name)
+	case *Assign:
+		return expr.Name
+	case *Index:
+		return firstToken(ExprToken(expr.Object), expr.Bracket)
+	case *SetIndex:
+		return firstToken(ExprToken(expr.Object), expr.Bracket)
+	case *BadExpr:
+		return expr.From
+	}
+	return nil
+}
+
+func tokenLine(tok token.T) int {
+	if tok == nil {
+		return 0
+	}
+	return tok.Whence().Line()
+}
+
+// firstToken returns tok, or if its line is unknown, fallback.
+func firstToken(tok, fallback token.T) token.T {
+	if tokenLine(tok) == 0 {
+		return fallback
+	}
+	return tok
+}
@@ -32,14 +32,28 @@ func (x *stmtToStringVisitor) statementToString(stmt Stmt) string {
 	return stmt.Accept_Stmt_String(x)
 }
 
-func (x *stmtToStringVisitor) idsToString(ids []token.T) string {
+// paramsToString returns a function's parameters, with their annotations.
+func (x *stmtToStringVisitor) paramsToString(function *Function) string {
 	params := []string{}
-	for _, id := range ids {
-		params = append(params, id.Lexeme())
+	for i, id := range function.Params {
+		var typ token.T
+		if i < len(function.ParamTypes) {
+			typ = function.ParamTypes[i]
+		}
+		params = append(params, id.Lexeme()+annotationToString(typ))
 	}
 	return strings.Join(params, ", ")
 }
 
+// annotationToString returns a type annotation, ": type", or "" if typ is
+// nil.
+func annotationToString(typ token.T) string {
+	if typ == nil {
+		return ""
+	}
+	return ": " + typ.Lexeme()
+}
+
 // blockToString returns a string that begins with "{\n" and ends with "}".
 // All lines but the first have their proper indentation.
 func (x *stmtToStringVisitor) blockToString(stmts []Stmt) string {
@@ -101,25 +115,31 @@ func (x *stmtToStringVisitor) Visit_PrintStmt_String(stmt *Print) string {
 }
 
 func (x *stmtToStringVisitor) Visit_ReturnStmt_String(stmt *Return) string {
+	if stmt.Value == nil {
+		return x.indentation() + "return;\n"
+	}
 	return x.indentation() + "return " + ExprToString(stmt.Value) + ";\n"
 }
 
-func (x *stmtToStringVisitor) Visit_PanicStmt_String(stmt *Panic) string {
-	return x.indentation() + "panic " + ExprToString(stmt.Expression) + ";\n"
+func (x *stmtToStringVisitor) Visit_BadStmtStmt_String(stmt *BadStmt) string {
+	return x.indentation() + "(bad);\n"
 }
 
 func (x *stmtToStringVisitor) Visit_VarInitializedStmt_String(stmt *VarInitialized) string {
-	return x.indentation() + "var " + stmt.Name.Lexeme() + " = " +
+	return x.indentation() + "var " + stmt.Name.Lexeme() +
+		annotationToString(stmt.Type) + " = " +
 		ExprToString(stmt.Initializer) + ";\n"
 }
 
 func (x *stmtToStringVisitor) Visit_VarUninitializedStmt_String(stmt *VarUninitialized) string {
-	return x.indentation() + "var " + stmt.Name.Lexeme() + ";\n"
+	return x.indentation() + "var " + stmt.Name.Lexeme() +
+		annotationToString(stmt.Type) + ";\n"
 }
 
 func (x *stmtToStringVisitor) Visit_FunctionStmt_String(stmt *Function) string {
-	params := x.idsToString(stmt.Params)
-	return x.indentation() + "fun (" + params + ") " +
+	params := x.paramsToString(stmt)
+	return x.indentation() + "fun (" + params + ")" +
+		annotationToString(stmt.ReturnType) + " " +
 		x.blockToString(stmt.Body) + "\n"
 }
 
@@ -142,18 +162,69 @@ func (x *stmtToStringVisitor) Visit_WhileStmt_String(stmt *While) string {
 	return s
 }
 
+func (x *stmtToStringVisitor) methodToString(prefix string, method *Function) string {
+	switch method.Kind {
+	case GetterFunction:
+		return x.indentation() + prefix + method.Name.Lexeme() +
+			annotationToString(method.ReturnType) + " " +
+			x.blockToString(method.Body) + "\n"
+	case SetterFunction:
+		prefix += "set "
+	}
+	return x.indentation() + prefix + method.Name.Lexeme() +
+		"(" + x.paramsToString(method) + ")" +
+		annotationToString(method.ReturnType) + " " +
+		x.blockToString(method.Body) + "\n"
+}
+
 func (x *stmtToStringVisitor) Visit_ClassStmt_String(stmt *Class) string {
-	methods := []string{}
+	members := []string{}
 	x.indent()
+	for _, field := range stmt.StaticFields {
+		text := x.indentation() + "class " + field.Name.Lexeme() +
+			annotationToString(field.Type) + " = " +
+			ExprToString(field.Initializer) + ";\n"
+		members = append(members, text)
+	}
+	for _, method := range stmt.StaticMethods {
+		members = append(members, x.methodToString("class ", method))
+	}
 	for _, method := range stmt.Methods {
-		text := x.indentation() + method.Name.Lexeme() + " " +
-			"(" + x.idsToString(method.Params) + ")" +
-			x.blockToString(method.Body)
-		methods = append(methods, text)
+		members = append(members, x.methodToString("", method))
 	}
 	x.undent()
 
-	return x.indentation() + "class " + "{\n" +
-		strings.Join(methods, "") +
+	heading := "class " + stmt.Name.Lexeme()
+	if stmt.Superclass != nil {
+		heading += " < " + stmt.Superclass.Name.Lexeme()
+	}
+	heading += withToString(stmt.Traits)
+	return x.indentation() + heading + " {\n" +
+		strings.Join(members, "") +
 		x.indentation() + "}\n"
 }
+
+func (x *stmtToStringVisitor) Visit_TraitStmt_String(stmt *Trait) string {
+	members := []string{}
+	x.indent()
+	for _, method := range stmt.Methods {
+		members = append(members, x.methodToString("", method))
+	}
+	x.undent()
+
+	heading := "trait " + stmt.Name.Lexeme() + withToString(stmt.Traits)
+	return x.indentation() + heading + " {\n" +
+		strings.Join(members, "") +
+		x.indentation() + "}\n"
+}
+
+func withToString(traits []*Variable) string {
+	if len(traits) == 0 {
+		return ""
+	}
+	names := []string{}
+	for _, trait := range traits {
+		names = append(names, trait.Name.Lexeme())
+	}
+	return " with " + strings.Join(names, ", ")
+}
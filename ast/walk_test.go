@@ -0,0 +1,72 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/perlmonger42/go-lox/token"
+)
+
+func name(s string) token.T {
+	return token.New(token.Identifier, s, nil, token.NewPos(1))
+}
+
+// program is the tree of
+//
+//	fun f(a) { if (a) print a + b; else return; }
+func program() Stmt {
+	return &Function{
+		Name:   name("f"),
+		Params: []token.T{name("a")},
+		Body: []Stmt{&If{
+			Condition: &Variable{name("a")},
+			ThenBranch: &Print{Expression: &Binary{
+				Operator: token.New(token.Plus, "+", nil, token.NewPos(1)),
+				Left:     &Variable{name("a")},
+				Right:    &Variable{name("b")},
+			}},
+			ElseBranch: &Return{Keyword: name("return")},
+		}},
+	}
+}
+
+func ExampleInspect() {
+	var names []string
+	Inspect(program(), func(n Node) bool {
+		if v, ok := n.(*Variable); ok {
+			names = append(names, v.Name.Lexeme())
+		}
+		return true
+	})
+	fmt.Println(strings.Join(names, " "))
+	// Output:
+	// a a b
+}
+
+// indenter prints each node it visits, indented by its depth.
+type indenter struct {
+	depth *int
+}
+
+func (v indenter) Visit(n Node) Visitor {
+	if n == nil {
+		*v.depth--
+		return nil
+	}
+	fmt.Printf("%s%T\n", strings.Repeat("  ", *v.depth), n)
+	*v.depth++
+	return v
+}
+
+func ExampleWalk() {
+	Walk(indenter{new(int)}, program())
+	// Output:
+	// *ast.Function
+	//   *ast.If
+	//     *ast.Variable
+	//     *ast.Print
+	//       *ast.Binary
+	//         *ast.Variable
+	//         *ast.Variable
+	//     *ast.Return
+}
@@ -0,0 +1,126 @@
+// Package astio writes syntax trees in forms other programs can read, for
+// `go-lox -dump-ast`: JSON (which ReadJSON reads back), Lisp-style
+// S-expressions, and Graphviz DOT graphs.
+//
+// The writers work on any node, by reflection, so they follow the schema
+// in ast/ast.schema without change: a node's fields are written in the
+// order declared there, under the names declared there.
+package astio
+
+import (
+	"reflect"
+
+	"github.com/perlmonger42/go-lox/ast"
+	"github.com/perlmonger42/go-lox/token"
+)
+
+var (
+	nodeType  = reflect.TypeOf((*ast.Node)(nil)).Elem()
+	tokenType = reflect.TypeOf((*token.T)(nil)).Elem()
+	valueType = reflect.TypeOf((*token.Value)(nil)).Elem()
+)
+
+// A field is a field of a node.
+type field struct {
+	name  string
+	value reflect.Value
+}
+
+// kind returns the kind of a node: the name of its type.
+func kind(n ast.Node) string {
+	return reflect.TypeOf(n).Elem().Name()
+}
+
+// fields returns the fields of a node, in the order declared.
+func fields(n ast.Node) []field {
+	v := reflect.ValueOf(n).Elem()
+	fs := make([]field, v.NumField())
+	for i := range fs {
+		fs[i] = field{v.Type().Field(i).Name, v.Field(i)}
+	}
+	return fs
+}
+
+// isNode reports whether a value of type t holds nodes (when not nil).
+func isNode(t reflect.Type) bool {
+	return t.Implements(nodeType)
+}
+
+// node returns the node a value holds, or nil.
+func node(v reflect.Value) ast.Node {
+	if v.IsNil() {
+		return nil
+	}
+	return v.Interface().(ast.Node)
+}
+
+// A Pos is a position in source.
+type Pos struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+func (p Pos) before(q Pos) bool {
+	return p.Line < q.Line || p.Line == q.Line && p.Column < q.Column
+}
+
+// A Span is the extent of a node in source: from the start of its first
+// token to just after its last.
+type Span struct {
+	Start Pos `json:"start"`
+	End   Pos `json:"end"`
+}
+
+// span returns the span of a node, from the tokens it and its descendants
+// hold, and whether any of them knows where it is.
+func span(n ast.Node) (Span, bool) {
+	var s Span
+	found := false
+	var visit func(v reflect.Value)
+	visit = func(v reflect.Value) {
+		switch {
+		case v.Type() == tokenType:
+			if v.IsNil() {
+				return
+			}
+			tok := v.Interface().(token.T)
+			start := Pos{tok.Whence().Line(), tok.Whence().Column()}
+			if start.Line == 0 || start.Column == 0 {
+				return
+			}
+			end := after(start, tok.Lexeme())
+			if !found || start.before(s.Start) {
+				s.Start = start
+			}
+			if !found || s.End.before(end) {
+				s.End = end
+			}
+			found = true
+		case v.Kind() == reflect.Slice:
+			for i := 0; i < v.Len(); i++ {
+				visit(v.Index(i))
+			}
+		case isNode(v.Type()):
+			if n := node(v); n != nil {
+				for _, f := range fields(n) {
+					visit(f.value)
+				}
+			}
+		}
+	}
+	visit(reflect.ValueOf(&n).Elem())
+	return s, found
+}
+
+// after returns the position just after text, which starts at pos.
+func after(pos Pos, text string) Pos {
+	for _, r := range text {
+		if r == '\n' {
+			pos.Line++
+			pos.Column = 1
+		} else {
+			pos.Column++
+		}
+	}
+	return pos
+}
@@ -0,0 +1,208 @@
+package astio
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/perlmonger42/go-lox/ast"
+	"github.com/perlmonger42/go-lox/config"
+	"github.com/perlmonger42/go-lox/lox"
+	"github.com/perlmonger42/go-lox/parse"
+	"github.com/perlmonger42/go-lox/scan"
+)
+
+const program = `fun greet(name) {
+  print "hi " + name;
+  if (name == "bob") return;
+}
+greet("bob");
+`
+
+func parseProgram(text string) []ast.Stmt {
+	lox := lox.New(config.New())
+	return parse.New(lox, scan.New(lox, text).ScanTokens()).ParseProg()
+}
+
+func ExampleWriteSExpr() {
+	WriteSExpr(os.Stdout, parseProgram(program))
+	// Output:
+	// (Function greet [name]
+	//   [(Print print (Binary + (Literal "hi " "\"hi \"") (Variable name)) ;)
+	//    (If if (Binary == (Variable name) (Literal "bob" "\"bob\"")) (Return return nil ;) nil)]
+	//   0
+	//   [nil]
+	//   nil
+	//   fun
+	//   })
+	// (Expression
+	//   (Call (Variable greet) ")"
+	//     [(Literal "bob" "\"bob\"")])
+	//   ;)
+}
+
+func ExampleWriteJSON() {
+	WriteJSON(os.Stdout, parseProgram(`print x;`))
+	// Output:
+	// [
+	//   {
+	//     "kind": "Print",
+	//     "span": {
+	//       "start": {
+	//         "line": 1,
+	//         "column": 1
+	//       },
+	//       "end": {
+	//         "line": 1,
+	//         "column": 9
+	//       }
+	//     },
+	//     "Keyword": {
+	//       "type": "Print",
+	//       "lexeme": "print",
+	//       "line": 1,
+	//       "column": 1
+	//     },
+	//     "Expression": {
+	//       "kind": "Variable",
+	//       "span": {
+	//         "start": {
+	//           "line": 1,
+	//           "column": 7
+	//         },
+	//         "end": {
+	//           "line": 1,
+	//           "column": 8
+	//         }
+	//       },
+	//       "Name": {
+	//         "type": "Identifier",
+	//         "lexeme": "x",
+	//         "line": 1,
+	//         "column": 7
+	//       }
+	//     },
+	//     "End": {
+	//       "type": "Semicolon",
+	//       "lexeme": ";",
+	//       "line": 1,
+	//       "column": 8
+	//     }
+	//   }
+	// ]
+}
+
+func ExampleWriteJSON_spans() {
+	var b bytes.Buffer
+	WriteJSON(&b, parseProgram(`var x = 1 + 2;
+while (x > 0) { print (x); x = x - 1; }
+`))
+	// Print each node's kind and span, indented by its depth.
+	var show func(v interface{}, depth int)
+	show = func(v interface{}, depth int) {
+		switch v := v.(type) {
+		case []interface{}:
+			for _, e := range v {
+				show(e, depth)
+			}
+		case map[string]interface{}:
+			kind, ok := v["kind"].(string)
+			if !ok {
+				return // a token
+			}
+			s := v["span"].(map[string]interface{})
+			start := s["start"].(map[string]interface{})
+			end := s["end"].(map[string]interface{})
+			fmt.Printf("%*s%s %v:%v-%v:%v\n", 2*depth, "", kind,
+				start["line"], start["column"], end["line"], end["column"])
+			var names []string
+			for name := range v {
+				names = append(names, name)
+			}
+			sort.Strings(names) // for a stable order
+			for _, name := range names {
+				if name != "span" {
+					show(v[name], depth+1)
+				}
+			}
+		}
+	}
+	var program interface{}
+	json.Unmarshal(b.Bytes(), &program)
+	show(program, 0)
+	// Output:
+	// VarInitialized 1:1-1:15
+	//   Binary 1:9-1:14
+	//     Literal 1:9-1:10
+	//     Literal 1:13-1:14
+	// While 2:1-2:40
+	//   Block 2:15-2:40
+	//     Print 2:17-2:27
+	//       Grouping 2:23-2:26
+	//         Variable 2:24-2:25
+	//     Expression 2:28-2:38
+	//       Assign 2:28-2:37
+	//         Binary 2:32-2:37
+	//           Variable 2:32-2:33
+	//           Literal 2:36-2:37
+	//   Binary 2:8-2:13
+	//     Variable 2:8-2:9
+	//     Literal 2:12-2:13
+}
+
+func ExampleWriteDot() {
+	WriteDot(os.Stdout, parseProgram(`print a + 1;`))
+	// Output:
+	// digraph ast {
+	//   node [shape=box, fontname=monospace];
+	//   n0 [label="program", shape=ellipse];
+	//   n1 [label="Print\nprint\n;"];
+	//   n2 [label="Binary\n+"];
+	//   n3 [label="Variable\na"];
+	//   n2 -> n3 [label="Left"];
+	//   n4 [label="Literal\n1\n1"];
+	//   n2 -> n4 [label="Right"];
+	//   n1 -> n2 [label="Expression"];
+	//   n0 -> n1 [label="0"];
+	// }
+}
+
+func ExampleReadJSON() {
+	stmts := parseProgram(program)
+	var b bytes.Buffer
+	WriteJSON(&b, stmts)
+	read, err := ReadJSON(&b)
+	fmt.Println(err)
+	for i := range stmts {
+		fmt.Println(ast.Equal(stmts[i], read[i]))
+	}
+	fmt.Print(ast.ToString(read[1]))
+
+	_, err = ReadJSON(bytes.NewBufferString(`[{"kind": "Print", "Expression": {"kind": "Block"}}]`))
+	fmt.Println(err)
+	// Output:
+	// <nil>
+	// true
+	// true
+	// greet("bob");
+	// ast json: [0].Expression: a Block cannot be a ast.Expr
+}
+
+func ExampleReadJSON_missingChildren() {
+	for _, text := range []string{
+		`[{"kind": "Print"}]`,
+		`[{"kind": "Expression", "Expression": null}]`,
+		`[{"kind": "Block", "Statements": [null]}]`,
+		`[{"kind": "Return", "Value": null}]`,
+	} {
+		_, err := ReadJSON(bytes.NewBufferString(text))
+		fmt.Println(err)
+	}
+	// Output:
+	// ast json: [0]: Print has no Expression
+	// ast json: [0].Expression: want a node, got null
+	// ast json: [0].Statements[0]: want a node, got null
+	// <nil>
+}
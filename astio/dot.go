@@ -0,0 +1,112 @@
+package astio
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/perlmonger42/go-lox/ast"
+	"github.com/perlmonger42/go-lox/token"
+)
+
+// WriteDot writes a program as a Graphviz graph (for `dot -Tsvg`), rooted
+// at a node for the program itself. Each syntax tree node is a box
+// labeled with its kind and the tokens and values it holds; each edge is
+// labeled with the field, and index, of the child it leads to.
+func WriteDot(w io.Writer, stmts []ast.Stmt) error {
+	d := &dotWriter{}
+	d.printf("digraph ast {\n")
+	d.printf("  node [shape=box, fontname=monospace];\n")
+	d.printf("  n0 [label=\"program\", shape=ellipse];\n")
+	for i, stmt := range stmts {
+		if stmt != nil {
+			d.edge(0, d.node(stmt), fmt.Sprintf("%d", i))
+		}
+	}
+	d.printf("}\n")
+	_, err := io.WriteString(w, d.b.String())
+	return err
+}
+
+type dotWriter struct {
+	b     strings.Builder
+	nodes int // the number of nodes written, besides the program
+}
+
+func (d *dotWriter) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&d.b, format, args...)
+}
+
+func (d *dotWriter) edge(from, to int, label string) {
+	d.printf("  n%d -> n%d [label=%s];\n", from, to, quote(label))
+}
+
+// node writes a node, and the subtree below it, returning its number.
+func (d *dotWriter) node(n ast.Node) int {
+	d.nodes++
+	id := d.nodes
+	label := []string{kind(n)}
+	type child struct {
+		label string
+		node  ast.Node
+	}
+	var children []child
+	for _, f := range fields(n) {
+		v := f.value
+		switch {
+		case isNode(v.Type()):
+			if c := node(v); c != nil {
+				children = append(children, child{f.name, c})
+			}
+		case v.Kind() == reflect.Slice && isNode(v.Type().Elem()):
+			for i := 0; i < v.Len(); i++ {
+				if c := node(v.Index(i)); c != nil {
+					children = append(children, child{fmt.Sprintf("%s[%d]", f.name, i), c})
+				}
+			}
+		default:
+			if text := dotAtom(v); text != "" {
+				label = append(label, text)
+			}
+		}
+	}
+	d.printf("  n%d [label=%s];\n", id, quote(strings.Join(label, "\n")))
+	for _, c := range children {
+		d.edge(id, d.node(c.node), c.label)
+	}
+	return id
+}
+
+// dotAtom returns the text of a field that is not a node, or "" if it has
+// none worth showing.
+func dotAtom(v reflect.Value) string {
+	switch {
+	case v.Type() == tokenType:
+		if !v.IsNil() {
+			return v.Interface().(token.T).Lexeme()
+		}
+	case v.Type() == valueType:
+		if !v.IsNil() {
+			return v.Interface().(token.Value).Show()
+		}
+	case v.Kind() == reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = dotAtom(v.Index(i))
+		}
+		return "(" + strings.Join(items, ", ") + ")"
+	case v.Kind() == reflect.Int:
+		if v.Int() != 0 {
+			return fmt.Sprintf("%v", v.Interface())
+		}
+	}
+	return ""
+}
+
+// quote returns text as a DOT string.
+func quote(text string) string {
+	text = strings.ReplaceAll(text, `\`, `\\`)
+	text = strings.ReplaceAll(text, `"`, `\"`)
+	return `"` + strings.ReplaceAll(text, "\n", `\n`) + `"`
+}
@@ -0,0 +1,261 @@
+package astio
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/perlmonger42/go-lox/ast"
+	"github.com/perlmonger42/go-lox/token"
+)
+
+// WriteJSON writes a program as a JSON array of its statements. A node is
+// an object with its "kind" (the name of its type), its "span" in the
+// source (from the start of its first token to just after its last, if its
+// tokens know where they are), and its fields, by name. A token is an
+// object with its "type", "lexeme", "line", "column" and any "literal"; a
+// literal value is a JSON number, string, boolean or null.
+func WriteJSON(w io.Writer, stmts []ast.Stmt) error {
+	list := make([]interface{}, len(stmts))
+	for i, stmt := range stmts {
+		list[i] = jsonValue(reflect.ValueOf(&stmt).Elem())
+	}
+	b, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// An object is a JSON object whose members keep their order.
+type object struct {
+	keys   []string
+	values []interface{}
+}
+
+func (o *object) add(key string, value interface{}) {
+	o.keys = append(o.keys, key)
+	o.values = append(o.values, value)
+}
+
+func (o *object) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		v, err := json.Marshal(o.values[i])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// jsonValue returns what WriteJSON writes for the value of a field.
+func jsonValue(v reflect.Value) interface{} {
+	switch {
+	case v.Type() == tokenType:
+		if v.IsNil() {
+			return nil
+		}
+		return jsonToken(v.Interface().(token.T))
+	case v.Type() == valueType:
+		if v.IsNil() {
+			return nil
+		}
+		return jsonLiteral(v.Interface().(token.Value))
+	case isNode(v.Type()):
+		n := node(v)
+		if n == nil {
+			return nil
+		}
+		o := &object{}
+		o.add("kind", kind(n))
+		if s, ok := span(n); ok {
+			o.add("span", s)
+		}
+		for _, f := range fields(n) {
+			o.add(f.name, jsonValue(f.value))
+		}
+		return o
+	case v.Kind() == reflect.Slice:
+		list := make([]interface{}, v.Len())
+		for i := range list {
+			list[i] = jsonValue(v.Index(i))
+		}
+		return list
+	}
+	return v.Interface()
+}
+
+func jsonToken(tok token.T) *object {
+	o := &object{}
+	o.add("type", tok.Type().String())
+	o.add("lexeme", tok.Lexeme())
+	o.add("line", tok.Whence().Line())
+	o.add("column", tok.Whence().Column())
+	if tok.Literal() != nil {
+		o.add("literal", jsonLiteral(tok.Literal()))
+	}
+	return o
+}
+
+func jsonLiteral(v token.Value) interface{} {
+	switch v := v.(type) {
+	case token.NumberValue:
+		return v.V
+	case token.StringValue:
+		return v.V
+	case token.BooleanValue:
+		return v.V
+	case token.NilValue:
+		return nil
+	}
+	return v.Show()
+}
+
+// ReadJSON reads a program written by WriteJSON, or by another program in
+// the same form. A node missing a child it requires is an error; other
+// missing fields, and the positions of tokens if absent, are left zero.
+// Spans are ignored, since the tokens give them.
+func ReadJSON(r io.Reader) ([]ast.Stmt, error) {
+	var list []interface{}
+	if err := json.NewDecoder(r).Decode(&list); err != nil {
+		return nil, fmt.Errorf("ast json: %s", err)
+	}
+	stmts := make([]ast.Stmt, len(list))
+	for i, x := range list {
+		v := reflect.ValueOf(&stmts[i]).Elem()
+		if err := readValue(v, x, fmt.Sprintf("[%d]", i)); err != nil {
+			return nil, err
+		}
+	}
+	return stmts, nil
+}
+
+// readValue sets v from x, the decoded JSON for it, found at path.
+func readValue(v reflect.Value, x interface{}, path string) error {
+	fail := func(format string, args ...interface{}) error {
+		return fmt.Errorf("ast json: %s: %s", path, fmt.Sprintf(format, args...))
+	}
+	if x == nil && isNode(v.Type()) {
+		return fail("want a node, got null")
+	}
+	if x == nil && v.Type() != valueType {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	switch {
+	case v.Type() == tokenType:
+		o, ok := x.(map[string]interface{})
+		if !ok {
+			return fail("want a token, got %T", x)
+		}
+		tok, err := readToken(o)
+		if err != nil {
+			return fail("%s", err)
+		}
+		v.Set(reflect.ValueOf(&tok).Elem())
+	case v.Type() == valueType:
+		value, err := readLiteral(x)
+		if err != nil {
+			return fail("%s", err)
+		}
+		v.Set(reflect.ValueOf(&value).Elem())
+	case isNode(v.Type()):
+		o, ok := x.(map[string]interface{})
+		if !ok {
+			return fail("want a node, got %T", x)
+		}
+		k, _ := o["kind"].(string)
+		n := ast.NewNode(k)
+		if n == nil {
+			return fail("unknown kind %q", k)
+		}
+		nv := reflect.ValueOf(n)
+		if !nv.Type().AssignableTo(v.Type()) {
+			return fail("a %s cannot be a %s", k, v.Type())
+		}
+		for _, f := range fields(n) {
+			child, ok := o[f.name]
+			if child == nil && ast.Optional(k, f.name) {
+				continue
+			}
+			if !ok && isNode(f.value.Type()) {
+				return fail("%s has no %s", k, f.name)
+			}
+			if err := readValue(f.value, child, path+"."+f.name); err != nil {
+				return err
+			}
+		}
+		v.Set(nv)
+	case v.Kind() == reflect.Slice:
+		list, ok := x.([]interface{})
+		if !ok {
+			return fail("want an array, got %T", x)
+		}
+		s := reflect.MakeSlice(v.Type(), len(list), len(list))
+		for i, item := range list {
+			if err := readValue(s.Index(i), item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+	case v.Kind() == reflect.Int:
+		f, ok := x.(float64)
+		if !ok {
+			return fail("want a number, got %T", x)
+		}
+		v.SetInt(int64(f))
+	default:
+		xv := reflect.ValueOf(x)
+		if !xv.Type().AssignableTo(v.Type()) {
+			return fail("want %s, got %T", v.Type(), x)
+		}
+		v.Set(xv)
+	}
+	return nil
+}
+
+func readToken(o map[string]interface{}) (token.T, error) {
+	name, _ := o["type"].(string)
+	typ, ok := token.ParseType(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown token type %q", name)
+	}
+	lexeme, _ := o["lexeme"].(string)
+	line, _ := o["line"].(float64)
+	column, _ := o["column"].(float64)
+	var literal token.Value
+	if x, ok := o["literal"]; ok {
+		var err error
+		if literal, err = readLiteral(x); err != nil {
+			return nil, err
+		}
+	}
+	return token.New(typ, lexeme, literal, token.NewLineColumn(int(line), int(column))), nil
+}
+
+func readLiteral(x interface{}) (token.Value, error) {
+	switch x := x.(type) {
+	case float64:
+		return token.NumberValue{x}, nil
+	case string:
+		return token.StringValue{x}, nil
+	case bool:
+		return token.BooleanValue{x}, nil
+	case nil:
+		return token.NilValue{}, nil
+	}
+	return nil, fmt.Errorf("want a literal value, got %T", x)
+}
@@ -0,0 +1,117 @@
+package astio
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/perlmonger42/go-lox/ast"
+	"github.com/perlmonger42/go-lox/token"
+)
+
+// WriteSExpr writes a program as S-expressions, one per statement. A node
+// is a list of its kind and its fields, in order; a slice is a vector in
+// brackets; a token is its lexeme; a literal value is as `print` would
+// show it, strings quoted; and a nil field is nil. A node whose fields are
+// all atoms is written on one line; in other nodes, each field after the
+// leading atoms starts a line of its own, as does each node in a vector.
+func WriteSExpr(w io.Writer, stmts []ast.Stmt) error {
+	var b strings.Builder
+	for _, stmt := range stmts {
+		writeSExpr(&b, reflect.ValueOf(&stmt).Elem(), 0)
+		b.WriteByte('\n')
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeSExpr writes a value starting at column indent.
+func writeSExpr(b *strings.Builder, v reflect.Value, indent int) {
+	switch {
+	case v.Type() == tokenType:
+		if v.IsNil() {
+			b.WriteString("nil")
+		} else {
+			b.WriteString(atom(v.Interface().(token.T).Lexeme()))
+		}
+	case v.Type() == valueType:
+		if v.IsNil() {
+			b.WriteString("nil")
+		} else {
+			b.WriteString(v.Interface().(token.Value).Show())
+		}
+	case isNode(v.Type()):
+		n := node(v)
+		if n == nil {
+			b.WriteString("nil")
+			return
+		}
+		b.WriteString("(" + kind(n))
+		broken := false
+		for _, f := range fields(n) {
+			if !broken && !isAtom(f.value) {
+				broken = true
+			}
+			if broken {
+				b.WriteString("\n" + strings.Repeat(" ", indent+2))
+			} else {
+				b.WriteByte(' ')
+			}
+			writeSExpr(b, f.value, indent+2)
+		}
+		b.WriteByte(')')
+	case v.Kind() == reflect.Slice:
+		b.WriteByte('[')
+		if isAtom(v) {
+			for i := 0; i < v.Len(); i++ {
+				if i > 0 {
+					b.WriteByte(' ')
+				}
+				writeSExpr(b, v.Index(i), indent)
+			}
+		} else {
+			for i := 0; i < v.Len(); i++ {
+				if i > 0 {
+					b.WriteString("\n" + strings.Repeat(" ", indent+1))
+				}
+				writeSExpr(b, v.Index(i), indent+1)
+			}
+		}
+		b.WriteByte(']')
+	default:
+		fmt.Fprintf(b, "%v", v.Interface())
+	}
+}
+
+// isAtom reports whether a value is written on one line: it is not a node,
+// or it is a node, or slice of them, holding only atoms.
+func isAtom(v reflect.Value) bool {
+	switch {
+	case v.Type() == tokenType || v.Type() == valueType:
+		return true
+	case isNode(v.Type()):
+		if n := node(v); n != nil {
+			for _, f := range fields(n) {
+				if !isAtom(f.value) {
+					return false
+				}
+			}
+		}
+		return true
+	case v.Kind() == reflect.Slice:
+		if isNode(v.Type().Elem()) {
+			return v.Len() == 0
+		}
+		return true
+	}
+	return true
+}
+
+// atom returns text as an S-expression atom, quoted if it must be.
+func atom(text string) string {
+	if text == "" || strings.ContainsAny(text, " \t\n()[]\"") {
+		return fmt.Sprintf("%q", text)
+	}
+	return text
+}
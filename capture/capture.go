@@ -0,0 +1,112 @@
+// Package capture runs Lox programs in fresh interpreters, capturing what
+// they print and the errors reported, for the runners of `go-lox -test` and
+// `go-lox test`.
+package capture
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/perlmonger42/go-lox/ast"
+	"github.com/perlmonger42/go-lox/config"
+	"github.com/perlmonger42/go-lox/interpret"
+	"github.com/perlmonger42/go-lox/lox"
+	"github.com/perlmonger42/go-lox/parse"
+	"github.com/perlmonger42/go-lox/resolve"
+	"github.com/perlmonger42/go-lox/scan"
+	"github.com/perlmonger42/go-lox/token"
+	"github.com/perlmonger42/go-lox/typecheck"
+)
+
+// An Error is an error reported while checking or running a program.
+type Error struct {
+	Pos     token.Pos
+	Where   string
+	Message string
+	Runtime bool // whether it was reported while the program ran
+}
+
+// A Result is what running a program captured.
+type Result struct {
+	Stmts  []ast.Stmt // the statements of the first source, as parsed
+	Output string     // what the program printed
+	Errors []Error
+}
+
+// Run runs sources, one after another, as one program in a fresh
+// interpreter with the given capabilities; the program reads an empty
+// standard input. It is scanned, parsed, resolved and type-checked first,
+// and goes no further than the first of these steps to report an error.
+func Run(capabilities config.Capability, sources ...string) Result {
+	var out bytes.Buffer
+	rec := &recorder{out: &out, end: -1}
+	config := config.New()
+	config.Capabilities = capabilities
+	config.Reporter = rec
+	config.Stdout = &out
+	config.Stdin = strings.NewReader("")
+	lox := lox.New(config)
+
+	interpreter := interpret.New(lox)
+	stmts, program, ok := check(lox, interpreter, sources)
+	if ok {
+		rec.running = true
+		interpreter.InterpretStmts(program)
+	}
+	if rec.end >= 0 {
+		// Drop the interpreter's own announcement of a runtime error,
+		// which follows the report of it.
+		out.Truncate(rec.end)
+	}
+	return Result{Stmts: stmts, Output: out.String(), Errors: rec.errors}
+}
+
+// check scans, parses, resolves and type-checks sources, returning the
+// statements of the first and those of them all, and whether no error was
+// reported.
+func check(
+	lox *lox.T, interpreter interpret.T, sources []string,
+) (first, program []ast.Stmt, ok bool) {
+	var lists [][]ast.Stmt
+	for _, source := range sources {
+		tokens := scan.New(lox, source).ScanTokens()
+		if lox.HadError {
+			return first, nil, false
+		}
+		stmts := parse.New(lox, tokens).ParseProg()
+		if lox.HadError {
+			return first, nil, false
+		}
+		if len(lists) == 0 {
+			first = stmts
+		}
+		lists = append(lists, stmts)
+	}
+
+	resolver := resolve.New(lox, interpreter)
+	resolver.Bindings = resolve.NewBindings()
+	for _, stmts := range lists {
+		resolver.ResolveStmtList(stmts)
+		program = append(program, stmts...)
+	}
+	if lox.HadError {
+		return first, nil, false
+	}
+	typecheck.Check(lox, program, resolver.Bindings)
+	return first, program, !lox.HadError
+}
+
+// A recorder is a report.T that collects errors.
+type recorder struct {
+	out     *bytes.Buffer
+	errors  []Error
+	running bool // whether errors are runtime errors
+	end     int  // the length of out when the last runtime error was reported
+}
+
+func (r *recorder) Report(pos token.Pos, where string, message string) {
+	r.errors = append(r.errors, Error{pos, where, message, r.running})
+	if r.running {
+		r.end = r.out.Len()
+	}
+}
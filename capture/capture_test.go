@@ -0,0 +1,19 @@
+package capture
+
+import "fmt"
+
+func ExampleRun() {
+	show := func(result Result) {
+		fmt.Printf("%d statement(s), output %q\n", len(result.Stmts), result.Output)
+		for _, err := range result.Errors {
+			fmt.Printf("[%s] %s (runtime %t)\n", err.Pos, err.Message, err.Runtime)
+		}
+	}
+	show(Run(0, `fun half(n) { return n / 2; }`, `print half(3); print half(nil);`))
+	show(Run(0, `var = 1;`, `print "never";`))
+	// Output:
+	// 1 statement(s), output "1.5\n"
+	// [line 1] cannot apply Slash: `/` to types nil and number (values nil and 2) (token.NilValue and token.NumberValue) (runtime true)
+	// 0 statement(s), output ""
+	// [line 1] Expect variable name, found `=`. (runtime false)
+}
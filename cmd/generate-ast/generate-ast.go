@@ -1,14 +1,25 @@
+// Command generate-ast writes the Go declarations of the Lox syntax tree
+// from the schema in ast/ast.schema. The ast package runs it with `go
+// generate`.
 package main
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
+	"go/format"
 	"os"
+	"path"
+	"path/filepath"
+	"sort"
 	"strings"
 	"text/template"
 )
 
-var outputDir = flag.String("d", ".", "output directory")
+var (
+	outputDir  = flag.String("d", ".", "output directory")
+	schemaFile = flag.String("schema", "", "schema `file` (default: ast.schema in the output directory)")
+)
 
 func main() {
 	flag.Usage = usage
@@ -17,8 +28,65 @@ func main() {
 	if flag.NArg() != 0 {
 		usage()
 	}
-	defineAst(outputDir, exprDescription)
-	defineAst(outputDir, stmtDescription)
+	if *schemaFile == "" {
+		*schemaFile = filepath.Join(*outputDir, "ast.schema")
+	}
+	f, err := os.Open(*schemaFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "generate-ast: %s\n", err)
+		os.Exit(66) // see "sysexits.h"
+	}
+	schema, errs := ReadSchema(*schemaFile, f)
+	f.Close()
+	if len(errs) > 0 {
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+		}
+		os.Exit(65) // see "sysexits.h"
+	}
+	name := filepath.Base(*schemaFile)
+	for _, classes := range schema.Bases {
+		generate(*outputDir, strings.ToLower(classes.BaseName), astSourceTemplate, struct {
+			*Classes
+			Schema string
+		}{classes, name})
+	}
+	nodes, types := nodeInfos(schema)
+	data := struct {
+		Nodes   []NodeInfo
+		Types   []ChildType
+		Imports []string
+		Schema  string
+	}{nodes, types, cloneImports(schema, nodes), name}
+	generate(*outputDir, "walk", walkSourceTemplate, data)
+	generate(*outputDir, "apply", applySourceTemplate, data)
+	generate(*outputDir, "clone", cloneSourceTemplate, data)
+	generate(*outputDir, "kinds", kindsSourceTemplate, data)
+}
+
+// cloneImports returns the imports that Clone needs: those of the types of
+// the slices it copies.
+func cloneImports(schema *Schema, nodes []NodeInfo) []string {
+	var imports []string
+	seen := map[string]bool{}
+	for _, node := range nodes {
+		for _, f := range node.Fields {
+			dot := strings.Index(f.Elem, ".")
+			if !f.List || f.Child || dot < 0 {
+				continue
+			}
+			for _, b := range schema.Bases {
+				for _, imp := range b.Imports {
+					if path.Base(imp) == f.Elem[:dot] && !seen[imp] {
+						seen[imp] = true
+						imports = append(imports, imp)
+					}
+				}
+			}
+		}
+	}
+	sort.Strings(imports)
+	return imports
 }
 
 func usage() {
@@ -31,6 +99,7 @@ func usage() {
 type FieldDescription struct {
 	FieldName string
 	FieldType string
+	Optional  bool // whether the node it holds may be nil
 }
 
 type Subclass struct {
@@ -45,120 +114,12 @@ type Classes struct {
 	Subclasses   []Subclass
 }
 
-var exprDescription = &Classes{
-	BaseName:     "Expr",
-	VisitorTypes: []string{"token.Value", "string", "", "(token.Value, error)"},
-	Imports:      []string{"github.com/perlmonger42/go-lox/token"},
-	Subclasses: []Subclass{
-		{"Grouping", []FieldDescription{
-			{"Expression", "Expr"},
-		}},
-		{"This", []FieldDescription{
-			{"Keyword", "token.T"},
-		}},
-		{"Super", []FieldDescription{
-			{"Keyword", "token.T"},
-			{"Method", "token.T"},
-		}},
-		{"Variable", []FieldDescription{
-			{"Name", "token.T"},
-		}},
-		{"Literal", []FieldDescription{
-			{"Value", "token.Value"},
-		}},
-		{"Call", []FieldDescription{
-			{"Callee", "Expr"},
-			{"Paren", "token.T"},
-			{"Arguments", "[]Expr"},
-		}},
-		{"Get", []FieldDescription{
-			{"Object", "Expr"},
-			{"Name", "token.T"},
-		}},
-		{"Unary", []FieldDescription{
-			{"Operator", "token.T"},
-			{"Right", "Expr"},
-		}},
-		{"Binary", []FieldDescription{
-			{"Operator", "token.T"},
-			{"Left", "Expr"},
-			{"Right", "Expr"},
-		}},
-		{"Logical", []FieldDescription{
-			{"Operator", "token.T"},
-			{"Left", "Expr"},
-			{"Right", "Expr"},
-		}},
-		{"Set", []FieldDescription{
-			{"Object", "Expr"},
-			{"Name", "token.T"},
-			{"Value", "Expr"},
-		}},
-		{"Assign", []FieldDescription{
-			{"Name", "token.T"},
-			{"Value", "Expr"},
-		}},
-	},
-}
-
-var stmtDescription = &Classes{
-	BaseName:     "Stmt",
-	VisitorTypes: []string{"", "string", "error"},
-	Imports:      []string{"github.com/perlmonger42/go-lox/token"},
-	Subclasses: []Subclass{
-		{"Noop", []FieldDescription{}},
-		{"Expression", []FieldDescription{
-			{"Expression", "Expr"},
-		}},
-		{"Print", []FieldDescription{
-			{"Keyword", "token.T"},
-			{"Expression", "Expr"},
-		}},
-		{"Return", []FieldDescription{
-			{"Keyword", "token.T"},
-			{"Value", "Expr"},
-		}},
-		{"Panic", []FieldDescription{
-			{"Keyword", "token.T"},
-			{"Expression", "Expr"},
-		}},
-		{"VarInitialized", []FieldDescription{
-			{"Name", "token.T"},
-			{"Initializer", "Expr"},
-		}},
-		{"VarUninitialized", []FieldDescription{
-			{"Name", "token.T"},
-		}},
-		{"Function", []FieldDescription{
-			{"Name", "token.T"},
-			{"Params", "[]token.T"},
-			{"Body", "[]Stmt"},
-		}},
-		{"If", []FieldDescription{
-			{"Condition", "Expr"},
-			{"ThenBranch", "Stmt"},
-			{"ElseBranch", "Stmt"},
-		}},
-		{"Block", []FieldDescription{
-			{"Token", "token.T"},
-			{"Statements", "[]Stmt"},
-		}},
-		{"While", []FieldDescription{
-			{"Condition", "Expr"},
-			{"Body", "Stmt"},
-		}},
-		{"Class", []FieldDescription{
-			{"Name", "token.T"},
-			{"Superclass", "*Variable"},
-			{"Methods", "[]*Function"},
-		}},
-	},
-}
-
 var astSourceTemplate string = `
 {{- $base := .BaseName}}
 {{- $subclasses := .Subclasses}}
 {{- $visitorReturnTypes := .VisitorTypes -}}
+// Code generated by generate-ast from {{.Schema}}; DO NOT EDIT.
+
 package ast
 
 import ({{range .Imports -}}
@@ -207,16 +168,9 @@ type {{$base}} interface {
 {{end}}
 `
 
-func defineAst(outputDir *string, classes *Classes) {
-	lcName := strings.ToLower(classes.BaseName)
-	path := fmt.Sprintf("%s/%s.go", *outputDir, lcName)
-	f, err := os.Create(path)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "cannot open %q for writing\n", path)
-		os.Exit(66) // see "sysexits.h"
-	}
-	defer f.Close()
-
+// generate writes the Go file name.go to the output directory from a
+// template, formatted as by gofmt.
+func generate(outputDir, name, source string, data interface{}) {
 	funcMap := template.FuncMap{
 		// The name "lc" is what the function will be called in template text.
 		"lc": strings.ToLower,
@@ -227,15 +181,39 @@ func defineAst(outputDir *string, classes *Classes) {
 			s = strings.ReplaceAll(s, ".", "_")
 			return strings.Title(s)
 		},
+		"convert":        convert,
+		"optionalFields": optionalFields,
+		// equal returns the Go expression comparing a and b, values of a
+		// field's (element) type.
+		"equal": func(f FieldInfo, a, b string) string {
+			switch {
+			case f.Child:
+				return fmt.Sprintf("Equal(%s, %s)", a, b)
+			case f.Equal != "":
+				return fmt.Sprintf("%s(%s, %s)", f.Equal, a, b)
+			}
+			return fmt.Sprintf("(%s == %s)", a, b)
+		},
 	}
 	t := template.Must(template.
-		New("source").
+		New(name).
 		Funcs(funcMap).
-		Parse(astSourceTemplate))
+		Parse(source))
 
-	err = t.Execute(f, classes)
-	if err != nil {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
 		fmt.Fprintf(os.Stderr, "error evaluating template: %s\n", err)
-		os.Exit(66) // see "sysexits.h"
+		os.Exit(70) // see "sysexits.h"
+	}
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error formatting generated code: %s\n", err)
+		os.Exit(70) // see "sysexits.h"
+	}
+
+	path := filepath.Join(outputDir, name+".go")
+	if err := os.WriteFile(path, formatted, 0666); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot write %q: %s\n", path, err)
+		os.Exit(73) // see "sysexits.h"
 	}
 }
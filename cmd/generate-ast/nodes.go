@@ -0,0 +1,202 @@
+package main
+
+import (
+	"strings"
+)
+
+// A NodeInfo is a node as the traversals see it.
+type NodeInfo struct {
+	Name   string
+	Fields []FieldInfo
+}
+
+// A FieldInfo describes a field of a node.
+type FieldInfo struct {
+	Name     string
+	Type     string
+	Elem     string // Type, or its element type if it is a slice
+	List     bool   // whether Type is a slice
+	Child    bool   // whether Elem is a base or a pointer to a node
+	Equal    string // if not a child, the function comparing Elems; "" for ==
+	Optional bool   // whether the child may be nil, as others may not
+}
+
+// Children returns the fields of a node holding other nodes, in the order
+// declared, which is the order of the source.
+func (n NodeInfo) Children() []FieldInfo {
+	var children []FieldInfo
+	for _, f := range n.Fields {
+		if f.Child {
+			children = append(children, f)
+		}
+	}
+	return children
+}
+
+// A ChildType is a type of child, with the name of the function that
+// converts a Node to it.
+type ChildType struct {
+	Type    string
+	Convert string
+}
+
+// equalFuncs are the hand-written functions, in package ast, that compare
+// the field types that == cannot.
+var equalFuncs = map[string]string{
+	"token.T":     "equalTokens",
+	"token.Value": "equalValues",
+}
+
+// convert returns the name of the function converting a Node to a child
+// type.
+func convert(typ string) string {
+	return "to" + strings.TrimPrefix(typ, "*")
+}
+
+// nodeInfos returns the nodes of a schema as the traversals see them, and
+// the types their children have, in order of first use. A child is a field
+// whose type is a base or a pointer to a node, or a slice of either.
+func nodeInfos(schema *Schema) ([]NodeInfo, []ChildType) {
+	bases := map[string]bool{}
+	for _, b := range schema.Bases {
+		bases[b.BaseName] = true
+	}
+	var nodes []NodeInfo
+	var types []ChildType
+	seen := map[string]bool{}
+	for _, b := range schema.Bases {
+		for _, sub := range b.Subclasses {
+			node := NodeInfo{Name: sub.SubName}
+			for _, f := range sub.Fields {
+				elem := strings.TrimPrefix(f.FieldType, "[]")
+				info := FieldInfo{
+					Name:  f.FieldName,
+					Type:  f.FieldType,
+					Elem:  elem,
+					List:  elem != f.FieldType,
+					Child: bases[elem] || strings.HasPrefix(elem, "*"),
+				}
+				info.Optional = f.Optional
+				if info.Child && !seen[elem] {
+					seen[elem] = true
+					types = append(types, ChildType{elem, convert(elem)})
+				}
+				if !info.Child {
+					info.Equal = equalFuncs[elem]
+				}
+				node.Fields = append(node.Fields, info)
+			}
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, types
+}
+
+var walkSourceTemplate string = `// Code generated by generate-ast from {{.Schema}}; DO NOT EDIT.
+
+package ast
+
+import "fmt"
+
+// A Visitor's Visit method is invoked for each node encountered by Walk.
+// If the result visitor w is not nil, Walk visits each of the children of
+// node with the visitor w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses a syntax tree in depth-first order: it starts by calling
+// v.Visit(node); node must not be nil. If the visitor w returned by
+// v.Visit(node) is not nil, Walk is invoked recursively with visitor w for
+// each of the non-nil children of node, in the order of node's fields,
+// followed by a call of w.Visit(nil).
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+{{- range .Nodes}}
+	case *{{.Name}}:
+	{{- range .Children}}
+	{{- if .List}}
+		for _, x := range n.{{.Name}} {
+			if x != nil {
+				Walk(v, x)
+			}
+		}
+	{{- else}}
+		if n.{{.Name}} != nil {
+			Walk(v, n.{{.Name}})
+		}
+	{{- end}}
+	{{- end}}
+{{- end}}
+	default:
+		panic(fmt.Sprintf("ast.Walk: unexpected node type %T", n))
+	}
+
+	v.Visit(nil)
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses a syntax tree in depth-first order: it starts by
+// calling f(node); node must not be nil. If f returns true, Inspect invokes
+// f recursively for each of the non-nil children of node, followed by a
+// call of f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
+`
+
+var kindsSourceTemplate string = `// Code generated by generate-ast from {{.Schema}}; DO NOT EDIT.
+
+package ast
+
+// NewNode returns a new node of the given kind, which is the name of its
+// type, with every field zero; or nil, if there is no such kind.
+func NewNode(kind string) Node {
+	switch kind {
+{{- range .Nodes}}
+	case "{{.Name}}":
+		return &{{.Name}}{}
+{{- end}}
+	}
+	return nil
+}
+
+// Optional reports whether the named field of a node of the given kind
+// holds a child that may be nil, such as the Value of a Return that
+// returns nothing. The other children of a well-formed node are never nil.
+func Optional(kind, field string) bool {
+	switch kind + "." + field {
+{{- with optionalFields .Nodes}}
+	case {{range $i, $n := .}}{{if $i}}, {{end}}"{{$n}}"{{end}}:
+		return true
+{{- end}}
+	}
+	return false
+}
+`
+
+// optionalFields returns the optional children of nodes, each named
+// "Node.Field".
+func optionalFields(nodes []NodeInfo) []string {
+	var names []string
+	for _, n := range nodes {
+		for _, f := range n.Fields {
+			if f.Optional {
+				names = append(names, n.Name+"."+f.Name)
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// A Schema is the content of a schema file: the bases, each with its
+// nodes, in the order declared.
+type Schema struct {
+	Bases []*Classes
+	Types []string // declared by hand in package ast
+}
+
+// A SchemaError is a problem found in a schema file, at a line.
+type SchemaError struct {
+	Filename string
+	Line     int
+	Message  string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.Filename, e.Line, e.Message)
+}
+
+// predeclared are the Go types that fields may have without declaration.
+var predeclared = map[string]bool{
+	"bool": true, "int": true, "float64": true, "string": true, "error": true,
+}
+
+// ReadSchema reads and validates a schema file (see ast/ast.schema for its
+// format). It returns every error found, not just the first.
+func ReadSchema(filename string, r io.Reader) (*Schema, []error) {
+	s := &Schema{}
+	var errs []error
+	fail := func(line int, format string, args ...interface{}) {
+		errs = append(errs, &SchemaError{filename, line, fmt.Sprintf(format, args...)})
+	}
+
+	// Where each name was declared, for validation.
+	declared := map[string]int{}
+	var fields []fieldUse
+
+	var base *Classes
+	var node *Subclass
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		text := scanner.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		words := strings.Fields(text)
+		indented := text[0] == ' ' || text[0] == '\t'
+
+		if indented {
+			if node == nil {
+				fail(n, "field outside a node")
+				continue
+			}
+			optional := len(words) == 3 && words[2] == "optional"
+			if len(words) != 2 && !optional {
+				fail(n, "field must be a name and a type, and perhaps \"optional\"")
+				continue
+			}
+			for _, f := range node.Fields {
+				if f.FieldName == words[0] {
+					fail(n, "duplicate field %s in node %s", words[0], node.SubName)
+				}
+			}
+			node.Fields = append(node.Fields, FieldDescription{words[0], words[1], optional})
+			fields = append(fields, fieldUse{n, base, words[1], optional})
+			continue
+		}
+
+		node = nil
+		keyword, args := words[0], strings.Join(words[1:], " ")
+		switch keyword {
+		case "base", "node", "type":
+			if len(words) != 2 {
+				fail(n, "%s must have one name", keyword)
+				continue
+			}
+			name := words[1]
+			if first, ok := declared[name]; ok {
+				fail(n, "duplicate name %s (first declared at line %d)", name, first)
+			}
+			declared[name] = n
+			switch keyword {
+			case "base":
+				base = &Classes{BaseName: name}
+				s.Bases = append(s.Bases, base)
+			case "type":
+				s.Types = append(s.Types, name)
+			case "node":
+				if base == nil {
+					fail(n, "node %s before any base", name)
+					continue
+				}
+				base.Subclasses = append(base.Subclasses, Subclass{SubName: name})
+				node = &base.Subclasses[len(base.Subclasses)-1]
+			}
+		case "import", "visitor":
+			if base == nil {
+				fail(n, "%s before any base", keyword)
+				continue
+			}
+			if args == "" {
+				fail(n, "%s must have an argument", keyword)
+				continue
+			}
+			if keyword == "import" {
+				base.Imports = append(base.Imports, args)
+				continue
+			}
+			visitor := args
+			if visitor == "none" {
+				visitor = ""
+			}
+			for _, v := range base.VisitorTypes {
+				if v == visitor {
+					fail(n, "duplicate visitor %s for base %s", args, base.BaseName)
+				}
+			}
+			base.VisitorTypes = append(base.VisitorTypes, visitor)
+		default:
+			fail(n, "unknown keyword %q", keyword)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, append(errs, err)
+	}
+
+	nodes := map[string]bool{}
+	for _, b := range s.Bases {
+		for _, sub := range b.Subclasses {
+			nodes[sub.SubName] = true
+		}
+	}
+	for _, f := range fields {
+		if msg := checkType(f.typ, f.base, declared, nodes); msg != "" {
+			fail(f.line, "%s", msg)
+		} else if f.optional && !isBase(s, f.typ) && !strings.HasPrefix(f.typ, "*") {
+			fail(f.line, "bad optional type %s: only a field holding one node may be optional", f.typ)
+		}
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].(*SchemaError).Line < errs[j].(*SchemaError).Line
+	})
+	return s, errs
+}
+
+// A fieldUse is the type of a field, and where it was given, for checking
+// once every name is declared.
+type fieldUse struct {
+	line     int
+	base     *Classes
+	typ      string
+	optional bool
+}
+
+// isBase reports whether typ is the name of a base of s.
+func isBase(s *Schema, typ string) bool {
+	for _, b := range s.Bases {
+		if b.BaseName == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// checkType checks that a field of a node of base has a known type,
+// returning a complaint if it doesn't.
+func checkType(typ string, base *Classes, declared map[string]int, nodes map[string]bool) string {
+	name := strings.TrimPrefix(typ, "[]")
+	pointer := strings.HasPrefix(name, "*")
+	name = strings.TrimPrefix(name, "*")
+	if pkg := strings.Index(name, "."); pkg >= 0 {
+		for _, imp := range base.Imports {
+			if path.Base(imp) == name[:pkg] {
+				return ""
+			}
+		}
+		return fmt.Sprintf("unknown type %s: package %s is not imported by base %s",
+			typ, name[:pkg], base.BaseName)
+	}
+	if pointer && !nodes[name] {
+		return fmt.Sprintf("bad type %s: only nodes may be pointed to", typ)
+	}
+	if _, ok := declared[name]; !ok && !predeclared[name] {
+		return fmt.Sprintf("unknown type %s", typ)
+	}
+	return ""
+}
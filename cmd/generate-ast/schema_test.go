@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+func ExampleReadSchema() {
+	schema, errs := ReadSchema("good.schema", strings.NewReader(`
+type Kind
+base Expr
+import github.com/perlmonger42/go-lox/token
+visitor none
+visitor (token.Value, error)
+node Literal  # a constant
+	Value token.Value
+node Group
+	Inner Expr optional
+	Kind Kind
+	Parts []*Literal
+`))
+	for _, base := range schema.Bases {
+		fmt.Println(base.BaseName, len(base.Subclasses), base.VisitorTypes)
+		for _, sub := range base.Subclasses {
+			fmt.Println(" ", sub.SubName, sub.Fields)
+		}
+	}
+	fmt.Println(errs)
+	// Output:
+	// Expr 2 [ (token.Value, error)]
+	//   Literal [{Value token.Value false}]
+	//   Group [{Inner Expr true} {Kind Kind false} {Parts []*Literal false}]
+	// []
+}
+
+func ExampleReadSchema_errors() {
+	_, errs := ReadSchema("bad.schema", strings.NewReader(`
+base Expr
+visitor string
+visitor string
+node Unary
+	Operator token.T
+	Right Expr
+	Right Expr
+node Unary
+	Left Exprr
+	Ptr *Expr
+nod Binary
+	Orphan Expr
+node Binary
+	Parts []Expr optional
+	Left Expr maybe
+`))
+	for _, err := range errs {
+		fmt.Println(err)
+	}
+	// Output:
+	// bad.schema:4: duplicate visitor string for base Expr
+	// bad.schema:6: unknown type token.T: package token is not imported by base Expr
+	// bad.schema:8: duplicate field Right in node Unary
+	// bad.schema:9: duplicate name Unary (first declared at line 5)
+	// bad.schema:10: unknown type Exprr
+	// bad.schema:11: bad type *Expr: only nodes may be pointed to
+	// bad.schema:12: unknown keyword "nod"
+	// bad.schema:13: field outside a node
+	// bad.schema:15: bad optional type []Expr: only a field holding one node may be optional
+	// bad.schema:16: field must be a name and a type, and perhaps "optional"
+}
@@ -0,0 +1,254 @@
+package main
+
+var applySourceTemplate string = `// Code generated by generate-ast from {{.Schema}}; DO NOT EDIT.
+
+package ast
+
+import "fmt"
+
+// An ApplyFunc is invoked by Apply for each non-nil node n, before and/or
+// after the node's children, using a Cursor describing the current node and
+// providing operations on it.
+//
+// The return value of ApplyFunc controls the syntax tree traversal. See
+// Apply for details.
+type ApplyFunc func(*Cursor) bool
+
+// Apply traverses a syntax tree recursively, starting with root, and
+// calling pre and post for each node as described below. Apply returns
+// the syntax tree, possibly modified.
+//
+// If pre is not nil, it is called for each node before the node's children
+// are traversed (pre-order). If pre returns false, no children are
+// traversed, and post is not called for that node.
+//
+// If post is not nil, and a prior call of pre didn't return false, post is
+// called for each node after its children are traversed (post-order). If
+// post returns false, traversal is terminated and Apply returns
+// immediately.
+//
+// Only fields that refer to nodes are traversed; nil fields are skipped.
+// Children are traversed in the order of their node's fields. If a node is
+// replaced by pre, the children of the replacement are traversed instead.
+// Nodes in a rewritten tree must be resolved again before they are run,
+// since the interpreter finds variables by node.
+func Apply(root Node, pre, post ApplyFunc) (result Node) {
+	defer func() {
+		if r := recover(); r != nil && r != abort {
+			panic(r)
+		}
+	}()
+	a := &application{pre: pre, post: post}
+	result = root
+	a.apply(nil, "", -1, root, func(n Node) { result = n }, nil)
+	return result
+}
+
+var abort = new(int) // singleton, to signal termination of Apply
+
+// A Cursor describes a node encountered during Apply. Information about
+// the node and its parent is available from the Node, Parent, Name, and
+// Index methods.
+type Cursor struct {
+	parent  Node
+	name    string
+	index   int
+	node    Node
+	set     func(Node)
+	delete  func()
+	deleted bool
+}
+
+// Node returns the current Node.
+func (c *Cursor) Node() Node { return c.node }
+
+// Parent returns the parent of the current Node, or nil for the root.
+func (c *Cursor) Parent() Node { return c.parent }
+
+// Name returns the name of the parent Node field that contains the current
+// Node, or "" for the root.
+func (c *Cursor) Name() string { return c.name }
+
+// Index reports the index >= 0 of the current Node in the slice of Nodes
+// that contains it, or a value < 0 if the current Node is not part of a
+// slice.
+func (c *Cursor) Index() int { return c.index }
+
+// Replace replaces the current Node with n. The replacement node is not
+// walked by Apply, though its children are if pre replaced it. Replace
+// panics if n cannot be stored in the parent's field.
+func (c *Cursor) Replace(n Node) {
+	c.set(n)
+	c.node = n
+}
+
+// Delete deletes the current Node from its containing slice. If the
+// current Node is not part of a slice, Delete panics.
+func (c *Cursor) Delete() {
+	if c.delete == nil {
+		panic(fmt.Sprintf("ast.Cursor.Delete: %s is not in a slice", c.name))
+	}
+	c.delete()
+	c.deleted = true
+	c.node = nil
+}
+
+type application struct {
+	pre, post ApplyFunc
+}
+
+// apply applies pre and post to node, and between them to its children,
+// and reports whether node was deleted.
+func (a *application) apply(parent Node, name string, index int, node Node, set func(Node), delete func()) bool {
+	c := &Cursor{parent: parent, name: name, index: index, node: node, set: set, delete: delete}
+	if a.pre != nil && !a.pre(c) || c.deleted {
+		return c.deleted
+	}
+	if c.node != nil {
+		a.children(c.node)
+	}
+	if a.post != nil && !a.post(c) {
+		panic(abort)
+	}
+	return c.deleted
+}
+
+func (a *application) children(node Node) {
+	switch n := node.(type) {
+{{- range .Nodes}}
+	case *{{.Name}}:
+	{{- range .Children}}
+	{{- if .List}}
+		for i := 0; i < len(n.{{.Name}}); i++ {
+			if n.{{.Name}}[i] == nil {
+				continue
+			}
+			if a.apply(n, "{{.Name}}", i, n.{{.Name}}[i],
+				func(x Node) { n.{{.Name}}[i] = {{convert .Elem}}(x) },
+				func() { n.{{.Name}} = append(n.{{.Name}}[:i], n.{{.Name}}[i+1:]...) }) {
+				i--
+			}
+		}
+	{{- else}}
+		if n.{{.Name}} != nil {
+			a.apply(n, "{{.Name}}", -1, n.{{.Name}}, func(x Node) { n.{{.Name}} = {{convert .Elem}}(x) }, nil)
+		}
+	{{- end}}
+	{{- end}}
+{{- end}}
+	default:
+		panic(fmt.Sprintf("ast.Apply: unexpected node type %T", n))
+	}
+}
+{{range .Types}}
+// {{.Convert}} converts a node to a {{.Type}}, which it must be, or nil.
+func {{.Convert}}(n Node) {{.Type}} {
+	if n == nil {
+		return nil
+	}
+	return n.({{.Type}})
+}
+{{end}}`
+
+var cloneSourceTemplate string = `// Code generated by generate-ast from {{.Schema}}; DO NOT EDIT.
+
+package ast
+
+import (
+	"fmt"
+
+{{- range .Imports}}
+	"{{.}}"
+{{- end}}
+)
+
+// Clone returns a deep copy of a syntax tree: every node in it is copied,
+// though the tokens and literal values they hold are shared. The copy
+// must be resolved before it is run, since the interpreter finds variables
+// by node. Clone(nil) is nil.
+func Clone(node Node) Node {
+	if isNil(node) {
+		return node
+	}
+	switch n := node.(type) {
+{{- range .Nodes}}
+	case *{{.Name}}:
+		c := *n
+	{{- range .Fields}}
+	{{- if and .List .Child}}
+		if n.{{.Name}} != nil {
+			c.{{.Name}} = make({{.Type}}, len(n.{{.Name}}))
+			for i, x := range n.{{.Name}} {
+				c.{{.Name}}[i] = {{convert .Elem}}(Clone(x))
+			}
+		}
+	{{- else if .List}}
+		if n.{{.Name}} != nil {
+			c.{{.Name}} = append({{.Type}}{}, n.{{.Name}}...)
+		}
+	{{- else if .Child}}
+		if n.{{.Name}} != nil {
+			c.{{.Name}} = {{convert .Elem}}(Clone(n.{{.Name}}))
+		}
+	{{- end}}
+	{{- end}}
+		return &c
+{{- end}}
+	}
+	panic(fmt.Sprintf("ast.Clone: unexpected node type %T", node))
+}
+
+// Equal reports whether two syntax trees have the same structure: the same
+// types of node, holding equal tokens (by type and lexeme, wherever they
+// are in the source) and equal values.
+func Equal(a, b Node) bool {
+	if isNil(a) || isNil(b) {
+		return isNil(a) && isNil(b)
+	}
+	switch a := a.(type) {
+{{- range .Nodes}}
+	case *{{.Name}}:
+	{{- if not .Fields}}
+		_, ok := b.(*{{.Name}})
+		return ok
+	{{- else}}
+		b, ok := b.(*{{.Name}})
+		if !ok {
+			return false
+		}
+	{{- range .Fields}}
+	{{- if .List}}
+		if len(a.{{.Name}}) != len(b.{{.Name}}) {
+			return false
+		}
+		for i := range a.{{.Name}} {
+			if !{{equal . (printf "a.%s[i]" .Name) (printf "b.%s[i]" .Name)}} {
+				return false
+			}
+		}
+	{{- else}}
+		if !{{equal . (printf "a.%s" .Name) (printf "b.%s" .Name)}} {
+			return false
+		}
+	{{- end}}
+	{{- end}}
+		return true
+	{{- end}}
+{{- end}}
+	}
+	panic(fmt.Sprintf("ast.Equal: unexpected node type %T", a))
+}
+
+// isNil reports whether a node is nil, or a nil pointer.
+func isNil(n Node) bool {
+	switch n := n.(type) {
+	case nil:
+		return true
+{{- range .Nodes}}
+	case *{{.Name}}:
+		return n == nil
+{{- end}}
+	}
+	return false
+}
+`
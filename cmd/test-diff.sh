@@ -29,5 +29,5 @@ while ($line = <>) {
 }
 close $want;
 
-system "opendiff", ".test-got.txt", ".test-want.txt";
+system "diff", "-u", ".test-want.txt", ".test-got.txt";
 # vim: filetype=perl shiftwidth=8 tabstop=8 noexpandtab
@@ -1,6 +1,10 @@
 package config
 
 import (
+	"io"
+	"os"
+	"strings"
+
 	"github.com/perlmonger42/go-lox/report"
 )
 
@@ -12,11 +16,64 @@ type T struct {
 	TraceNodes       bool // print AST nodes as they are built
 	TraceParsed      bool // dump AST rendered as Lox
 	TraceEval        bool // print intermediate values as executed
+	Warnings         bool // report warnings, such as unused variables
+
+	Capabilities Capability // privileged operations scripts may perform
+	Args         []string   // command-line arguments following the script name
+	Stdin        io.Reader  // source of input for `readLine()`
+	Stdout       io.Writer  // destination of `print` and runtime errors
 }
 
 func New() *T {
 	return &T{
 		Prompt:   "> ",
 		Reporter: report.NewStdoutReporter(),
+		Stdin:    os.Stdin,
+		Stdout:   os.Stdout,
+	}
+}
+
+// Capability is a set of privileged operations that natives may perform on
+// behalf of a script. A sandboxed run grants none of them.
+type Capability uint
+
+const (
+	CapStdin     Capability = 1 << iota // read lines from standard input
+	CapReadFile                         // read files and list directories
+	CapWriteFile                        // create and overwrite files
+	CapEnv                              // read environment variables
+	CapArgs                             // read command-line arguments
+
+	NoCapabilities  Capability = 0
+	AllCapabilities Capability = CapStdin | CapReadFile | CapWriteFile |
+		CapEnv | CapArgs
+)
+
+var capabilityNames = []struct {
+	cap  Capability
+	name string
+}{
+	{CapStdin, "stdin"},
+	{CapReadFile, "read-file"},
+	{CapWriteFile, "write-file"},
+	{CapEnv, "env"},
+	{CapArgs, "args"},
+}
+
+// Has reports whether every capability in want is present in c.
+func (c Capability) Has(want Capability) bool {
+	return c&want == want
+}
+
+func (c Capability) String() string {
+	names := []string{}
+	for _, cn := range capabilityNames {
+		if c.Has(cn.cap) {
+			names = append(names, cn.name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
 	}
+	return strings.Join(names, ",")
 }
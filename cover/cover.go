@@ -0,0 +1,270 @@
+// Package cover records the code coverage of a Lox program, as run by
+// `go-lox -coverprofile`. A Profile is built from the program's syntax
+// tree, listing every statement and branch in it, then installed as the
+// interpreter's hook to count which of them execute. It is written out in
+// LCOV format, for coverage tools, and as HTML source annotated with the
+// counts.
+//
+// The branches are the arms of `if` statements (an `if` without an else
+// clause still has an arm for when its condition is false), whether
+// `while` and `for` loop bodies run, and whether the right operands of
+// `and` and `or` are evaluated or short-circuited.
+package cover
+
+import (
+	"fmt"
+
+	"github.com/perlmonger42/go-lox/ast"
+	"github.com/perlmonger42/go-lox/interpret"
+)
+
+// A Profile holds the statements, branches and functions of a program,
+// and how many times each was executed.
+type Profile struct {
+	Statements []*Statement // in source order
+	Branches   []*Branch    // in source order
+	Functions  []*Function  // in source order
+
+	statements map[ast.Stmt]*Statement
+	branches   map[ast.Node]*Branch
+	functions  map[*ast.Function]*Function
+}
+
+type Statement struct {
+	Line  int
+	Count int
+}
+
+// A Branch has two arms: see the arms of interpret.BranchHook.
+type Branch struct {
+	Kind  string // "if", "while", "and" or "or"
+	Line  int
+	Taken [2]int // the number of times each arm was taken
+}
+
+// Arms returns the names of the arms of a branch of the given kind.
+func Arms(kind string) [2]string {
+	switch kind {
+	case "if":
+		return [2]string{"then", "else"}
+	case "while":
+		return [2]string{"body", "exit"}
+	}
+	return [2]string{"short-circuit", "right operand"}
+}
+
+type Function struct {
+	Name  string // qualified by its class or trait, if it is a method
+	Line  int
+	Calls int
+}
+
+var _ interpret.BranchHook = &Profile{}
+
+// New returns an empty Profile for the program.
+func New(stmts []ast.Stmt) *Profile {
+	p := &Profile{
+		statements: make(map[ast.Stmt]*Statement),
+		branches:   make(map[ast.Node]*Branch),
+		functions:  make(map[*ast.Function]*Function),
+	}
+	for _, stmt := range stmts {
+		p.stmt(stmt)
+	}
+	return p
+}
+
+// stmt adds a statement, and the statements, branches and functions inside
+// it, to the profile. Blocks aren't counted, since their statements are.
+func (p *Profile) stmt(stmt ast.Stmt) {
+	if _, ok := stmt.(*ast.Block); !ok {
+		if line := ast.StmtLine(stmt); line > 0 {
+			s := &Statement{Line: line}
+			p.Statements = append(p.Statements, s)
+			p.statements[stmt] = s
+		}
+	}
+
+	switch stmt := stmt.(type) {
+	case *ast.Block:
+		for _, s := range stmt.Statements {
+			p.stmt(s)
+		}
+	case *ast.Expression:
+		p.expr(stmt.Expression)
+	case *ast.Print:
+		p.expr(stmt.Expression)
+	case *ast.Return:
+		if stmt.Value != nil {
+			p.expr(stmt.Value)
+		}
+	case *ast.VarInitialized:
+		p.expr(stmt.Initializer)
+	case *ast.Function:
+		p.function(stmt, "")
+	case *ast.Class:
+		for _, field := range stmt.StaticFields {
+			p.expr(field.Initializer)
+		}
+		for _, method := range stmt.StaticMethods {
+			p.function(method, stmt.Name.Lexeme()+".")
+		}
+		for _, method := range stmt.Methods {
+			p.function(method, stmt.Name.Lexeme()+".")
+		}
+	case *ast.Trait:
+		for _, method := range stmt.Methods {
+			p.function(method, stmt.Name.Lexeme()+".")
+		}
+	case *ast.If:
+		p.branch(stmt, "if", ast.ExprLine(stmt.Condition))
+		p.expr(stmt.Condition)
+		p.stmt(stmt.ThenBranch)
+		if stmt.ElseBranch != nil {
+			p.stmt(stmt.ElseBranch)
+		}
+	case *ast.While:
+		p.branch(stmt, "while", ast.ExprLine(stmt.Condition))
+		p.expr(stmt.Condition)
+		p.stmt(stmt.Body)
+	}
+}
+
+func (p *Profile) function(f *ast.Function, prefix string) {
+	function := &Function{Name: prefix + f.MemberName(), Line: f.Name.Whence().Line()}
+	p.Functions = append(p.Functions, function)
+	p.functions[f] = function
+	for _, stmt := range f.Body {
+		p.stmt(stmt)
+	}
+}
+
+// expr adds the branches inside an expression to the profile.
+func (p *Profile) expr(expr ast.Expr) {
+	switch expr := expr.(type) {
+	case *ast.Grouping:
+		p.expr(expr.Expression)
+	case *ast.Call:
+		p.expr(expr.Callee)
+		for _, arg := range expr.Arguments {
+			p.expr(arg)
+		}
+	case *ast.Get:
+		p.expr(expr.Object)
+	case *ast.Unary:
+		p.expr(expr.Right)
+	case *ast.Binary:
+		p.expr(expr.Left)
+		p.expr(expr.Right)
+	case *ast.Logical:
+		p.branch(expr, expr.Operator.Lexeme(), expr.Operator.Whence().Line())
+		p.expr(expr.Left)
+		p.expr(expr.Right)
+	case *ast.Set:
+		p.expr(expr.Object)
+		p.expr(expr.Value)
+	case *ast.Assign:
+		p.expr(expr.Value)
+	case *ast.Index:
+		p.expr(expr.Object)
+		p.expr(expr.Index)
+	case *ast.SetIndex:
+		p.expr(expr.Object)
+		p.expr(expr.Index)
+		p.expr(expr.Value)
+	}
+}
+
+func (p *Profile) branch(node ast.Node, kind string, line int) {
+	b := &Branch{Kind: kind, Line: line}
+	p.Branches = append(p.Branches, b)
+	p.branches[node] = b
+}
+
+// Statement counts an execution of stmt.
+func (p *Profile) Statement(i interpret.T, stmt ast.Stmt) {
+	if s, ok := p.statements[stmt]; ok {
+		s.Count++
+	}
+}
+
+// Enter counts a call of f.
+func (p *Profile) Enter(i interpret.T, f *interpret.LoxFunction) {
+	if function, ok := p.functions[f.Declaration]; ok {
+		function.Calls++
+	}
+}
+
+func (p *Profile) Leave(i interpret.T, f *interpret.LoxFunction) {}
+
+// Branch counts a choice of an arm of a branch.
+func (p *Profile) Branch(i interpret.T, node ast.Node, arm int) {
+	if b, ok := p.branches[node]; ok {
+		b.Taken[arm]++
+	}
+}
+
+// Summary returns a line summarizing the coverage.
+func (p *Profile) Summary() string {
+	statements, branches := 0, 0
+	for _, s := range p.Statements {
+		if s.Count > 0 {
+			statements++
+		}
+	}
+	for _, b := range p.Branches {
+		for _, taken := range b.Taken {
+			if taken > 0 {
+				branches++
+			}
+		}
+	}
+	return fmt.Sprintf("coverage: %s of statements, %s of branches",
+		percent(statements, len(p.Statements)),
+		percent(branches, 2*len(p.Branches)))
+}
+
+func percent(n, of int) string {
+	if of == 0 {
+		return "100.0%"
+	}
+	return fmt.Sprintf("%.1f%%", 100*float64(n)/float64(of))
+}
+
+// A line is what the profile knows of one line of source.
+type line struct {
+	statements int // how many statements start on the line
+	count      int // how many times the line executed
+	missed     int // how many of its statements never executed
+	notTaken   []string
+}
+
+// lines summarizes the profile by line.
+func (p *Profile) lines() map[int]*line {
+	lines := map[int]*line{}
+	get := func(n int) *line {
+		if lines[n] == nil {
+			lines[n] = &line{}
+		}
+		return lines[n]
+	}
+	for _, s := range p.Statements {
+		l := get(s.Line)
+		l.statements++
+		if s.Count > l.count {
+			l.count = s.Count
+		}
+		if s.Count == 0 {
+			l.missed++
+		}
+	}
+	for _, b := range p.Branches {
+		for arm, taken := range b.Taken {
+			if taken == 0 {
+				l := get(b.Line)
+				l.notTaken = append(l.notTaken, fmt.Sprintf("%s %s", b.Kind, Arms(b.Kind)[arm]))
+			}
+		}
+	}
+	return lines
+}
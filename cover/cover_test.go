@@ -0,0 +1,130 @@
+package cover
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/perlmonger42/go-lox/config"
+	"github.com/perlmonger42/go-lox/interpret"
+	"github.com/perlmonger42/go-lox/lox"
+	"github.com/perlmonger42/go-lox/parse"
+	"github.com/perlmonger42/go-lox/resolve"
+	"github.com/perlmonger42/go-lox/scan"
+)
+
+const program = `fun classify(n) {
+  if (n < 0) {
+    return "negative";
+  } else if (n == 0 or n == nil) {
+    return "zero";
+  }
+  return "positive";
+}
+class Counter {
+  init() { this.n = 0; }
+  next() { this.n = this.n + 1; return this.n; }
+  reset() { this.n = 0; }
+}
+var c = Counter();
+while (c.next() < 3 and true) {
+  print classify(c.n);
+}
+`
+
+// run runs text, returning its coverage.
+func run(text string) *Profile {
+	lox := lox.New(config.New())
+	stmts := parse.New(lox, scan.New(lox, text).ScanTokens()).ParseProg()
+	interpreter := interpret.New(lox)
+	resolve.New(lox, interpreter).ResolveStmtList(stmts)
+	if lox.HadError {
+		return nil
+	}
+	p := New(stmts)
+	interpreter.SetHook(p)
+	interpreter.InterpretStmts(stmts)
+	return p
+}
+
+func ExampleProfile_WriteLCOV() {
+	p := run(program)
+	fmt.Println(p.Summary())
+	p.WriteLCOV(os.Stdout, "classify.lox")
+	// Output:
+	// positive
+	// positive
+	// coverage: 78.6% of statements, 70.0% of branches
+	// TN:
+	// SF:classify.lox
+	// FN:1,classify
+	// FN:10,Counter.init
+	// FN:11,Counter.next
+	// FN:12,Counter.reset
+	// FNDA:2,classify
+	// FNDA:1,Counter.init
+	// FNDA:3,Counter.next
+	// FNDA:0,Counter.reset
+	// FNF:4
+	// FNH:3
+	// BRDA:2,0,0,0
+	// BRDA:2,0,1,2
+	// BRDA:4,1,0,0
+	// BRDA:4,1,1,2
+	// BRDA:4,2,0,0
+	// BRDA:4,2,1,2
+	// BRDA:15,3,0,2
+	// BRDA:15,3,1,1
+	// BRDA:15,4,0,1
+	// BRDA:15,4,1,2
+	// BRF:10
+	// BRH:7
+	// DA:1,1
+	// DA:2,2
+	// DA:3,0
+	// DA:4,2
+	// DA:5,0
+	// DA:7,2
+	// DA:9,1
+	// DA:10,1
+	// DA:11,3
+	// DA:12,0
+	// DA:14,1
+	// DA:15,1
+	// DA:16,2
+	// LF:13
+	// LH:10
+	// end_of_record
+}
+
+func ExampleProfile_WriteHTML() {
+	var buf bytes.Buffer
+	run(program).WriteHTML(&buf, "classify.lox", program)
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.HasPrefix(line, "<tr") || strings.HasPrefix(line, "<p>") {
+			fmt.Println(line)
+		}
+	}
+	// Output:
+	// positive
+	// positive
+	// <p>coverage: 78.6% of statements, 70.0% of branches</p>
+	// <tr class="covered"><td class="number">1</td><td class="count">1</td><td class="source">fun classify(n) {</td></tr>
+	// <tr class="partial" title="not taken: if then"><td class="number">2</td><td class="count">2</td><td class="source">  if (n &lt; 0) {</td></tr>
+	// <tr class="missed"><td class="number">3</td><td class="count">0</td><td class="source">    return &#34;negative&#34;;</td></tr>
+	// <tr class="partial" title="not taken: if then, or short-circuit"><td class="number">4</td><td class="count">2</td><td class="source">  } else if (n == 0 or n == nil) {</td></tr>
+	// <tr class="missed"><td class="number">5</td><td class="count">0</td><td class="source">    return &#34;zero&#34;;</td></tr>
+	// <tr><td class="number">6</td><td class="count"></td><td class="source">  }</td></tr>
+	// <tr class="covered"><td class="number">7</td><td class="count">2</td><td class="source">  return &#34;positive&#34;;</td></tr>
+	// <tr><td class="number">8</td><td class="count"></td><td class="source">}</td></tr>
+	// <tr class="covered"><td class="number">9</td><td class="count">1</td><td class="source">class Counter {</td></tr>
+	// <tr class="covered"><td class="number">10</td><td class="count">1</td><td class="source">  init() { this.n = 0; }</td></tr>
+	// <tr class="covered"><td class="number">11</td><td class="count">3</td><td class="source">  next() { this.n = this.n &#43; 1; return this.n; }</td></tr>
+	// <tr class="missed"><td class="number">12</td><td class="count">0</td><td class="source">  reset() { this.n = 0; }</td></tr>
+	// <tr><td class="number">13</td><td class="count"></td><td class="source">}</td></tr>
+	// <tr class="covered"><td class="number">14</td><td class="count">1</td><td class="source">var c = Counter();</td></tr>
+	// <tr class="covered"><td class="number">15</td><td class="count">1</td><td class="source">while (c.next() &lt; 3 and true) {</td></tr>
+	// <tr class="covered"><td class="number">16</td><td class="count">2</td><td class="source">  print classify(c.n);</td></tr>
+	// <tr><td class="number">17</td><td class="count"></td><td class="source">}</td></tr>
+}
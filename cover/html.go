@@ -0,0 +1,75 @@
+package cover
+
+import (
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteHTML writes the program's source as an HTML page, with each line
+// that has statements marked covered (green), missed (red), or partly
+// covered (yellow: some of its statements or branch arms never ran), and
+// with the number of times it executed.
+func (p *Profile) WriteHTML(w io.Writer, filename, source string) error {
+	type htmlLine struct {
+		Number int
+		Count  string
+		Class  string
+		Note   string
+		Text   string
+	}
+	page := struct {
+		Title   string
+		Summary string
+		Lines   []htmlLine
+	}{Title: filename, Summary: p.Summary()}
+
+	lines := p.lines()
+	for n, text := range strings.Split(strings.TrimSuffix(source, "\n"), "\n") {
+		hl := htmlLine{Number: n + 1, Text: text}
+		if l, ok := lines[n+1]; ok {
+			switch {
+			case l.statements > 0 && l.count == 0:
+				hl.Class = "missed"
+			case l.missed > 0 || len(l.notTaken) > 0:
+				hl.Class = "partial"
+			default:
+				hl.Class = "covered"
+			}
+			if l.statements > 0 {
+				hl.Count = strconv.Itoa(l.count)
+			}
+			if len(l.notTaken) > 0 {
+				hl.Note = "not taken: " + strings.Join(l.notTaken, ", ")
+			}
+		}
+		page.Lines = append(page.Lines, hl)
+	}
+	return htmlTemplate.Execute(w, page)
+}
+
+var htmlTemplate = template.Must(template.New("cover").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}: coverage</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; font-family: monospace; }
+td { padding: 0 0.5em; white-space: pre; }
+td.number, td.count { text-align: right; color: #888; }
+tr.covered td.source { background: #cfc; }
+tr.missed td.source { background: #fcc; }
+tr.partial td.source { background: #ffc; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{.Summary}}</p>
+<table>
+{{range .Lines}}<tr{{with .Class}} class="{{.}}"{{end}}{{with .Note}} title="{{.}}"{{end}}><td class="number">{{.Number}}</td><td class="count">{{.Count}}</td><td class="source">{{.Text}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
@@ -0,0 +1,63 @@
+package cover
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// WriteLCOV writes the profile in the LCOV tracefile format read by
+// genhtml and by coverage services, as the record of one source file.
+func (p *Profile) WriteLCOV(w io.Writer, filename string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "TN:\nSF:%s\n", filename)
+
+	hit := 0
+	for _, f := range p.Functions {
+		fmt.Fprintf(&b, "FN:%d,%s\n", f.Line, f.Name)
+	}
+	for _, f := range p.Functions {
+		fmt.Fprintf(&b, "FNDA:%d,%s\n", f.Calls, f.Name)
+		if f.Calls > 0 {
+			hit++
+		}
+	}
+	fmt.Fprintf(&b, "FNF:%d\nFNH:%d\n", len(p.Functions), hit)
+
+	hit = 0
+	for n, br := range p.Branches {
+		reached := br.Taken[0]+br.Taken[1] > 0
+		for arm, taken := range br.Taken {
+			count := "-" // the branch was never reached
+			if reached {
+				count = fmt.Sprint(taken)
+			}
+			fmt.Fprintf(&b, "BRDA:%d,%d,%d,%s\n", br.Line, n, arm, count)
+			if taken > 0 {
+				hit++
+			}
+		}
+	}
+	fmt.Fprintf(&b, "BRF:%d\nBRH:%d\n", 2*len(p.Branches), hit)
+
+	lines := p.lines()
+	numbers := []int{}
+	for n, l := range lines {
+		if l.statements > 0 {
+			numbers = append(numbers, n)
+		}
+	}
+	sort.Ints(numbers)
+	hit = 0
+	for _, n := range numbers {
+		fmt.Fprintf(&b, "DA:%d,%d\n", n, lines[n].count)
+		if lines[n].count > 0 {
+			hit++
+		}
+	}
+	fmt.Fprintf(&b, "LF:%d\nLH:%d\nend_of_record\n", len(numbers), hit)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
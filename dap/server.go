@@ -0,0 +1,483 @@
+// Package dap implements a Debug Adapter Protocol server for Lox, as run by
+// `go-lox dap`, so that editors such as VS Code can debug Lox programs. The
+// program runs under a debug.Controller; while it is stopped, the server
+// answers requests for the call stack, scopes, variables and expressions
+// from the Controller's frames and their chains of environments.
+//
+// The server has a single goroutine. Before the program starts, and after
+// it ends, requests are handled by Serve; while the program runs they are
+// not read at all; and while it is stopped they are handled from inside the
+// Controller's Stop function, until one resumes it.
+package dap
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/perlmonger42/go-lox/ast"
+	"github.com/perlmonger42/go-lox/config"
+	"github.com/perlmonger42/go-lox/debug"
+	"github.com/perlmonger42/go-lox/interpret"
+	"github.com/perlmonger42/go-lox/lox"
+	"github.com/perlmonger42/go-lox/token"
+	"github.com/perlmonger42/go-lox/wire"
+)
+
+// threadID identifies the only thread a Lox program has.
+const threadID = 1
+
+// globalsReference is the variablesReference of the global scope. The
+// locals of the frame with ID n have reference n+1.
+const globalsReference = 1
+
+type Server struct {
+	in  *bufio.Reader
+	out io.Writer
+	log io.Writer
+	seq int // of the last message sent
+
+	linesStartAt1 bool
+	breakpoints   map[int]bool // shared with the controller
+	source        *Source
+	lox           *lox.T
+	controller    *debug.Controller
+	stmts         []ast.Stmt
+
+	launched   bool
+	configured bool
+	running    bool      // set once the program starts
+	stopped    bool      // set while the program is stopped
+	errors     *[]string // if not nil, collects errors instead of reporting them
+	done       bool      // set by `disconnect`, or when the client goes away
+	err        error     // why the client went away, if not by closing the connection
+}
+
+// NewServer returns a server that reads messages from in and writes them to
+// out. Problems with the connection itself are logged to log.
+func NewServer(in io.Reader, out io.Writer, log io.Writer) *Server {
+	return &Server{
+		in:            bufio.NewReader(in),
+		out:           out,
+		log:           log,
+		linesStartAt1: true,
+		breakpoints:   make(map[int]bool),
+	}
+}
+
+// Serve handles messages until the client disconnects or closes the
+// connection.
+func (s *Server) Serve() error {
+	for !s.done {
+		if req := s.next(); req != nil {
+			s.handle(req)
+		}
+	}
+	return s.err
+}
+
+// next reads the next request, returning nil (and setting s.done at the end
+// of input) if there isn't one.
+func (s *Server) next() *request {
+	content, err := wire.ReadMessage(s.in)
+	if err != nil {
+		if err != io.EOF {
+			s.err = err
+		}
+		s.done = true
+		return nil
+	}
+	var req request
+	if err := json.Unmarshal(content, &req); err != nil {
+		fmt.Fprintf(s.log, "go-lox dap: bad message: %s\n", err)
+		return nil
+	}
+	if req.Type != "request" {
+		return nil
+	}
+	return &req
+}
+
+// handle answers one request. A request that resumes a stopped program
+// also returns how it should resume.
+func (s *Server) handle(req *request) (action debug.Action, resume bool) {
+	var body interface{}
+	var err error
+	switch req.Command {
+	case "initialize":
+		body, err = s.initialize(req)
+	case "launch":
+		err = s.launch(req)
+	case "setBreakpoints":
+		body, err = s.setBreakpoints(req)
+	case "configurationDone":
+		s.configured = true
+	case "threads":
+		body = &ThreadsResponse{[]Thread{{threadID, "main"}}}
+	case "stackTrace":
+		body, err = s.stackTrace()
+	case "scopes":
+		body, err = s.scopes(req)
+	case "variables":
+		body, err = s.variables(req)
+	case "evaluate":
+		body, err = s.evaluate(req)
+	case "continue", "next", "stepIn", "stepOut":
+		if !s.stopped {
+			err = fmt.Errorf("the program is not stopped")
+			break
+		}
+		resume = true
+		action = map[string]debug.Action{
+			"continue": debug.Continue,
+			"next":     debug.StepOver,
+			"stepIn":   debug.StepInto,
+			"stepOut":  debug.StepOut,
+		}[req.Command]
+		if req.Command == "continue" {
+			body = &ContinueResponse{AllThreadsContinued: true}
+		}
+	case "disconnect", "terminate":
+		s.done = true
+		action, resume = debug.Quit, true
+	default:
+		err = fmt.Errorf("unsupported request %q", req.Command)
+	}
+
+	if err != nil {
+		s.reply(req, false, err.Error(), nil)
+		return debug.Continue, false
+	}
+	s.reply(req, true, "", body)
+	switch req.Command {
+	case "initialize":
+		s.event("initialized", nil)
+	case "launch", "configurationDone":
+		if s.launched && s.configured && !s.running {
+			s.run()
+		}
+	}
+	return action, resume
+}
+
+// decode unmarshals the arguments of a request.
+func decode(req *request, v interface{}) error {
+	if len(req.Arguments) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(req.Arguments, v); err != nil {
+		return fmt.Errorf("bad arguments to %s: %s", req.Command, err)
+	}
+	return nil
+}
+
+func (s *Server) initialize(req *request) (*Capabilities, error) {
+	var args InitializeArguments
+	if err := decode(req, &args); err != nil {
+		return nil, err
+	}
+	if args.LinesStartAt1 != nil {
+		s.linesStartAt1 = *args.LinesStartAt1
+	}
+	return &Capabilities{
+		SupportsConfigurationDoneRequest: true,
+		SupportsEvaluateForHovers:        true,
+	}, nil
+}
+
+// launch loads the program, which runs once configuration is done.
+func (s *Server) launch(req *request) error {
+	var args LaunchArguments
+	if err := decode(req, &args); err != nil {
+		return err
+	}
+	if s.launched {
+		return fmt.Errorf("a program has already been launched")
+	}
+	content, err := ioutil.ReadFile(args.Program)
+	if err != nil {
+		return err
+	}
+
+	capabilities := config.AllCapabilities &^ config.CapStdin // stdin carries the protocol
+	config := config.New()
+	config.Stdin = nil
+	config.Capabilities = capabilities
+	config.Args = args.Args
+	config.Stdout = &outputWriter{s, "stdout"}
+	config.Reporter = &outputReporter{s}
+	s.lox = lox.New(config)
+	s.controller = debug.NewController(s.lox, s.stop)
+	s.controller.Breakpoints = s.breakpoints
+	if s.stmts, err = s.controller.Load(string(content)); err != nil {
+		return err
+	}
+	if args.NoDebug {
+		s.controller.Stop = func(string) debug.Action { return debug.Continue }
+	}
+	if args.NoDebug || !args.StopOnEntry {
+		s.controller.Resume(debug.Continue)
+	}
+	s.source = &Source{Name: filepath.Base(args.Program), Path: args.Program}
+	s.launched = true
+	return nil
+}
+
+// run runs the program to its end, then tells the client it has ended.
+func (s *Server) run() {
+	s.running = true
+	if !s.controller.Run(s.stmts) {
+		return // abandoned by `disconnect`
+	}
+	exitCode := 0
+	if s.lox.HadError {
+		exitCode = 70 // see "sysexits.h"
+	}
+	s.event("exited", &ExitedEvent{exitCode})
+	s.event("terminated", nil)
+}
+
+// stop tells the client the program has stopped, then handles requests
+// until one resumes it.
+func (s *Server) stop(reason string) debug.Action {
+	s.event("stopped", &StoppedEvent{
+		Reason:            reason,
+		ThreadID:          threadID,
+		AllThreadsStopped: true,
+	})
+	s.stopped = true
+	defer func() { s.stopped = false }()
+	for !s.done {
+		if req := s.next(); req != nil {
+			if action, resume := s.handle(req); resume {
+				return action
+			}
+		}
+	}
+	return debug.Quit
+}
+
+func (s *Server) setBreakpoints(req *request) (*SetBreakpointsResponse, error) {
+	var args SetBreakpointsArguments
+	if err := decode(req, &args); err != nil {
+		return nil, err
+	}
+	for line := range s.breakpoints {
+		delete(s.breakpoints, line)
+	}
+	result := &SetBreakpointsResponse{Breakpoints: []Breakpoint{}}
+	for _, bp := range args.Breakpoints {
+		s.breakpoints[s.loxLine(bp.Line)] = true
+		result.Breakpoints = append(result.Breakpoints,
+			Breakpoint{Verified: true, Line: bp.Line})
+	}
+	return result, nil
+}
+
+// frame returns the frame with the given ID. The innermost frame has ID 1.
+func (s *Server) frame(id int) (*debug.Frame, error) {
+	if !s.stopped {
+		return nil, fmt.Errorf("the program is not stopped")
+	}
+	frames := s.controller.Frames
+	if id < 1 || id > len(frames) {
+		return nil, fmt.Errorf("no frame %d", id)
+	}
+	return frames[len(frames)-id], nil
+}
+
+func (s *Server) stackTrace() (*StackTraceResponse, error) {
+	if !s.stopped {
+		return nil, fmt.Errorf("the program is not stopped")
+	}
+	result := &StackTraceResponse{StackFrames: []StackFrame{}}
+	for id := 1; id <= len(s.controller.Frames); id++ {
+		frame, _ := s.frame(id)
+		result.StackFrames = append(result.StackFrames, StackFrame{
+			ID:     id,
+			Name:   frame.Name,
+			Source: s.source,
+			Line:   s.clientLine(frame.Line),
+			Column: s.clientLine(1),
+		})
+	}
+	result.TotalFrames = len(result.StackFrames)
+	return result, nil
+}
+
+func (s *Server) scopes(req *request) (*ScopesResponse, error) {
+	var args ScopesArguments
+	if err := decode(req, &args); err != nil {
+		return nil, err
+	}
+	if _, err := s.frame(args.FrameID); err != nil {
+		return nil, err
+	}
+	return &ScopesResponse{[]Scope{
+		{Name: "Locals", VariablesReference: args.FrameID + 1},
+		{Name: "Globals", VariablesReference: globalsReference},
+	}}, nil
+}
+
+// variables lists the variables of a scope. For a frame's locals, those of
+// every local scope are listed, innermost first, except where shadowed.
+// Values are shown as `print` would show them, and aren't expandable.
+func (s *Server) variables(req *request) (*VariablesResponse, error) {
+	var args VariablesArguments
+	if err := decode(req, &args); err != nil {
+		return nil, err
+	}
+	result := &VariablesResponse{Variables: []Variable{}}
+	add := func(env interpret.Environment, name string) {
+		value, _ := env.GetAt(0, name)
+		result.Variables = append(result.Variables, Variable{
+			Name:  name,
+			Value: s.controller.Stringify(value),
+			Type:  typeName(value),
+		})
+	}
+
+	if args.VariablesReference == globalsReference {
+		if !s.stopped {
+			return nil, fmt.Errorf("the program is not stopped")
+		}
+		globals := s.controller.Globals()
+		for _, name := range globals.Names() {
+			if !s.controller.IsNative(name) {
+				add(globals, name)
+			}
+		}
+		return result, nil
+	}
+
+	frame, err := s.frame(args.VariablesReference - 1)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	for _, env := range s.controller.Scopes(frame) {
+		for _, name := range env.Names() {
+			if !seen[name] {
+				seen[name] = true
+				add(env, name)
+			}
+		}
+	}
+	return result, nil
+}
+
+// evaluate evaluates an expression in the innermost frame, whatever frame
+// the client names.
+func (s *Server) evaluate(req *request) (*EvaluateResponse, error) {
+	var args EvaluateArguments
+	if err := decode(req, &args); err != nil {
+		return nil, err
+	}
+	if !s.stopped {
+		return nil, fmt.Errorf("the program is not stopped")
+	}
+	errors := []string{}
+	s.errors = &errors
+	value, ok := s.controller.Evaluate(args.Expression)
+	s.errors = nil
+	if !ok {
+		if len(errors) == 0 {
+			errors = append(errors, "cannot evaluate "+args.Expression)
+		}
+		return nil, fmt.Errorf("%s", strings.Join(errors, "\n"))
+	}
+	return &EvaluateResponse{
+		Result: s.controller.Stringify(value),
+		Type:   typeName(value),
+	}, nil
+}
+
+// typeName returns the name Lox's `typeOf` gives the type of value.
+func typeName(value token.Value) string {
+	if namer, ok := value.(token.TypeNamer); ok {
+		return namer.TypeName()
+	}
+	return ""
+}
+
+// loxLine converts a line number from the client to one of Lox's, which
+// start at 1.
+func (s *Server) loxLine(line int) int {
+	if s.linesStartAt1 {
+		return line
+	}
+	return line + 1
+}
+
+// clientLine converts a line (or column) number of Lox's to the client's.
+func (s *Server) clientLine(line int) int {
+	if s.linesStartAt1 {
+		return line
+	}
+	return line - 1
+}
+
+func (s *Server) reply(req *request, success bool, message string, body interface{}) {
+	s.seq++
+	s.send(&response{
+		Seq:        s.seq,
+		Type:       "response",
+		RequestSeq: req.Seq,
+		Success:    success,
+		Command:    req.Command,
+		Message:    message,
+		Body:       body,
+	})
+}
+
+func (s *Server) event(name string, body interface{}) {
+	s.seq++
+	s.send(&event{Seq: s.seq, Type: "event", Event: name, Body: body})
+}
+
+func (s *Server) send(v interface{}) {
+	if err := wire.WriteMessage(s.out, v); err != nil {
+		fmt.Fprintf(s.log, "go-lox dap: %s\n", err)
+	}
+}
+
+// An outputWriter sends what the program writes to the client as output
+// events.
+type outputWriter struct {
+	s        *Server
+	category string
+}
+
+func (w *outputWriter) Write(p []byte) (int, error) {
+	w.s.event("output", &OutputEvent{Category: w.category, Output: string(p)})
+	return len(p), nil
+}
+
+// An outputReporter is a report.T that sends errors to the client as output
+// events, or, while an expression is evaluated, collects them.
+type outputReporter struct {
+	s *Server
+}
+
+func (r *outputReporter) Report(pos token.Pos, where string, message string) {
+	if r.s.errors != nil {
+		*r.s.errors = append(*r.s.errors, message)
+		return
+	}
+	pad := ""
+	if where != "" {
+		pad = " "
+	}
+	w := &outputWriter{r.s, "stderr"}
+	fmt.Fprintf(w, "[%s] Error%s%s: %s\n", pos, pad, where, message)
+}
+
+// Run serves the client connected to standard input and output.
+func Run() error {
+	return NewServer(os.Stdin, os.Stdout, os.Stderr).Serve()
+}
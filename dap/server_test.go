@@ -0,0 +1,164 @@
+package dap
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/perlmonger42/go-lox/wire"
+)
+
+const program = `fun add(a, b) {
+  var sum = a + b;
+  return sum;
+}
+var x = 1;
+for (var i = 0; i < 2; i = i + 1) {
+  x = add(x, i);
+}
+print x;
+`
+
+// session writes program to a file, then pipes the requests to a server,
+// as a client would, and prints the messages the server sends back. In
+// the requests and the messages, PROGRAM stands for the file's path.
+func session(program string, requests ...string) {
+	dir, err := ioutil.TempDir("", "dap")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "prog.lox")
+	if err := ioutil.WriteFile(path, []byte(program), 0666); err != nil {
+		panic(err)
+	}
+
+	in, toServer := io.Pipe()
+	go func() {
+		for _, req := range requests {
+			req = strings.Replace(req, "PROGRAM", path, -1)
+			fmt.Fprintf(toServer, "Content-Length: %d\r\n\r\n%s", len(req), req)
+		}
+		toServer.Close()
+	}()
+	var out bytes.Buffer
+	err = NewServer(in, &out, os.Stdout).Serve()
+
+	messages := bufio.NewReader(&out)
+	for {
+		content, err := wire.ReadMessage(messages)
+		if err != nil {
+			break
+		}
+		fmt.Println(strings.Replace(string(content), path, "PROGRAM", -1))
+	}
+	fmt.Printf("serve: %v\n", err)
+}
+
+func ExampleServer_breakpoints() {
+	session(program,
+		`{"seq":1,"type":"request","command":"initialize","arguments":{"clientID":"vscode","adapterID":"lox","linesStartAt1":true}}`,
+		`{"seq":2,"type":"request","command":"launch","arguments":{"program":"PROGRAM"}}`,
+		`{"seq":3,"type":"request","command":"setBreakpoints","arguments":{"source":{"path":"PROGRAM"},"breakpoints":[{"line":3}]}}`,
+		`{"seq":4,"type":"request","command":"configurationDone"}`,
+		`{"seq":5,"type":"request","command":"threads"}`,
+		`{"seq":6,"type":"request","command":"stackTrace","arguments":{"threadId":1}}`,
+		`{"seq":7,"type":"request","command":"scopes","arguments":{"frameId":1}}`,
+		`{"seq":8,"type":"request","command":"variables","arguments":{"variablesReference":2}}`,
+		`{"seq":9,"type":"request","command":"scopes","arguments":{"frameId":2}}`,
+		`{"seq":10,"type":"request","command":"variables","arguments":{"variablesReference":3}}`,
+		`{"seq":11,"type":"request","command":"variables","arguments":{"variablesReference":1}}`,
+		`{"seq":12,"type":"request","command":"evaluate","arguments":{"expression":"sum * 10","frameId":1,"context":"watch"}}`,
+		`{"seq":13,"type":"request","command":"evaluate","arguments":{"expression":"nope","frameId":1,"context":"repl"}}`,
+		`{"seq":14,"type":"request","command":"setBreakpoints","arguments":{"source":{"path":"PROGRAM"},"breakpoints":[]}}`,
+		`{"seq":15,"type":"request","command":"continue","arguments":{"threadId":1}}`,
+		`{"seq":16,"type":"request","command":"disconnect","arguments":{}}`,
+	)
+	// Output:
+	// {"seq":1,"type":"response","request_seq":1,"success":true,"command":"initialize","body":{"supportsConfigurationDoneRequest":true,"supportsEvaluateForHovers":true}}
+	// {"seq":2,"type":"event","event":"initialized"}
+	// {"seq":3,"type":"response","request_seq":2,"success":true,"command":"launch"}
+	// {"seq":4,"type":"response","request_seq":3,"success":true,"command":"setBreakpoints","body":{"breakpoints":[{"verified":true,"line":3}]}}
+	// {"seq":5,"type":"response","request_seq":4,"success":true,"command":"configurationDone"}
+	// {"seq":6,"type":"event","event":"stopped","body":{"reason":"breakpoint","threadId":1,"allThreadsStopped":true}}
+	// {"seq":7,"type":"response","request_seq":5,"success":true,"command":"threads","body":{"threads":[{"id":1,"name":"main"}]}}
+	// {"seq":8,"type":"response","request_seq":6,"success":true,"command":"stackTrace","body":{"stackFrames":[{"id":1,"name":"add","source":{"name":"prog.lox","path":"PROGRAM"},"line":3,"column":1},{"id":2,"name":"<script>","source":{"name":"prog.lox","path":"PROGRAM"},"line":7,"column":1}],"totalFrames":2}}
+	// {"seq":9,"type":"response","request_seq":7,"success":true,"command":"scopes","body":{"scopes":[{"name":"Locals","variablesReference":2,"expensive":false},{"name":"Globals","variablesReference":1,"expensive":false}]}}
+	// {"seq":10,"type":"response","request_seq":8,"success":true,"command":"variables","body":{"variables":[{"name":"a","value":"1","type":"number","variablesReference":0},{"name":"b","value":"0","type":"number","variablesReference":0},{"name":"sum","value":"1","type":"number","variablesReference":0}]}}
+	// {"seq":11,"type":"response","request_seq":9,"success":true,"command":"scopes","body":{"scopes":[{"name":"Locals","variablesReference":3,"expensive":false},{"name":"Globals","variablesReference":1,"expensive":false}]}}
+	// {"seq":12,"type":"response","request_seq":10,"success":true,"command":"variables","body":{"variables":[{"name":"i","value":"0","type":"number","variablesReference":0}]}}
+	// {"seq":13,"type":"response","request_seq":11,"success":true,"command":"variables","body":{"variables":[{"name":"add","value":"fun (a, b) {\n  var sum = (+ a b);\n  return sum;\n}\n","type":"function","variablesReference":0},{"name":"x","value":"1","type":"number","variablesReference":0}]}}
+	// {"seq":14,"type":"response","request_seq":12,"success":true,"command":"evaluate","body":{"result":"10","type":"number","variablesReference":0}}
+	// {"seq":15,"type":"response","request_seq":13,"success":false,"command":"evaluate","message":"Undefined variable 'nope'."}
+	// {"seq":16,"type":"response","request_seq":14,"success":true,"command":"setBreakpoints","body":{"breakpoints":[]}}
+	// {"seq":17,"type":"response","request_seq":15,"success":true,"command":"continue","body":{"allThreadsContinued":true}}
+	// {"seq":18,"type":"event","event":"output","body":{"category":"stdout","output":"2\n"}}
+	// {"seq":19,"type":"event","event":"exited","body":{"exitCode":0}}
+	// {"seq":20,"type":"event","event":"terminated"}
+	// {"seq":21,"type":"response","request_seq":16,"success":true,"command":"disconnect"}
+	// serve: <nil>
+}
+
+func ExampleServer_stepping() {
+	session(program,
+		`{"seq":1,"type":"request","command":"initialize","arguments":{"adapterID":"lox"}}`,
+		`{"seq":2,"type":"request","command":"launch","arguments":{"program":"PROGRAM","stopOnEntry":true}}`,
+		`{"seq":3,"type":"request","command":"configurationDone"}`,
+		`{"seq":4,"type":"request","command":"next","arguments":{"threadId":1}}`,
+		`{"seq":5,"type":"request","command":"next","arguments":{"threadId":1}}`,
+		`{"seq":6,"type":"request","command":"next","arguments":{"threadId":1}}`,
+		`{"seq":7,"type":"request","command":"stepIn","arguments":{"threadId":1}}`,
+		`{"seq":8,"type":"request","command":"stackTrace","arguments":{"threadId":1}}`,
+		`{"seq":9,"type":"request","command":"stepOut","arguments":{"threadId":1}}`,
+		`{"seq":10,"type":"request","command":"stackTrace","arguments":{"threadId":1}}`,
+		`{"seq":11,"type":"request","command":"disconnect","arguments":{}}`,
+	)
+	// Output:
+	// {"seq":1,"type":"response","request_seq":1,"success":true,"command":"initialize","body":{"supportsConfigurationDoneRequest":true,"supportsEvaluateForHovers":true}}
+	// {"seq":2,"type":"event","event":"initialized"}
+	// {"seq":3,"type":"response","request_seq":2,"success":true,"command":"launch"}
+	// {"seq":4,"type":"response","request_seq":3,"success":true,"command":"configurationDone"}
+	// {"seq":5,"type":"event","event":"stopped","body":{"reason":"entry","threadId":1,"allThreadsStopped":true}}
+	// {"seq":6,"type":"response","request_seq":4,"success":true,"command":"next"}
+	// {"seq":7,"type":"event","event":"stopped","body":{"reason":"step","threadId":1,"allThreadsStopped":true}}
+	// {"seq":8,"type":"response","request_seq":5,"success":true,"command":"next"}
+	// {"seq":9,"type":"event","event":"stopped","body":{"reason":"step","threadId":1,"allThreadsStopped":true}}
+	// {"seq":10,"type":"response","request_seq":6,"success":true,"command":"next"}
+	// {"seq":11,"type":"event","event":"stopped","body":{"reason":"step","threadId":1,"allThreadsStopped":true}}
+	// {"seq":12,"type":"response","request_seq":7,"success":true,"command":"stepIn"}
+	// {"seq":13,"type":"event","event":"stopped","body":{"reason":"step","threadId":1,"allThreadsStopped":true}}
+	// {"seq":14,"type":"response","request_seq":8,"success":true,"command":"stackTrace","body":{"stackFrames":[{"id":1,"name":"add","source":{"name":"prog.lox","path":"PROGRAM"},"line":2,"column":1},{"id":2,"name":"<script>","source":{"name":"prog.lox","path":"PROGRAM"},"line":7,"column":1}],"totalFrames":2}}
+	// {"seq":15,"type":"response","request_seq":9,"success":true,"command":"stepOut"}
+	// {"seq":16,"type":"event","event":"stopped","body":{"reason":"step","threadId":1,"allThreadsStopped":true}}
+	// {"seq":17,"type":"response","request_seq":10,"success":true,"command":"stackTrace","body":{"stackFrames":[{"id":1,"name":"<script>","source":{"name":"prog.lox","path":"PROGRAM"},"line":6,"column":1}],"totalFrames":1}}
+	// {"seq":18,"type":"response","request_seq":11,"success":true,"command":"disconnect"}
+	// serve: <nil>
+}
+
+func ExampleServer_errors() {
+	session("print 1;\nprint 1 + nil;\n",
+		`{"seq":1,"type":"request","command":"initialize","arguments":{"adapterID":"lox"}}`,
+		`{"seq":2,"type":"request","command":"next","arguments":{"threadId":1}}`,
+		`{"seq":3,"type":"request","command":"launch","arguments":{"program":"PROGRAM"}}`,
+		`{"seq":4,"type":"request","command":"configurationDone"}`,
+		`{"seq":5,"type":"request","command":"disconnect"}`,
+	)
+	// Output:
+	// {"seq":1,"type":"response","request_seq":1,"success":true,"command":"initialize","body":{"supportsConfigurationDoneRequest":true,"supportsEvaluateForHovers":true}}
+	// {"seq":2,"type":"event","event":"initialized"}
+	// {"seq":3,"type":"response","request_seq":2,"success":false,"command":"next","message":"the program is not stopped"}
+	// {"seq":4,"type":"response","request_seq":3,"success":true,"command":"launch"}
+	// {"seq":5,"type":"response","request_seq":4,"success":true,"command":"configurationDone"}
+	// {"seq":6,"type":"event","event":"output","body":{"category":"stdout","output":"1\n"}}
+	// {"seq":7,"type":"event","event":"output","body":{"category":"stderr","output":"[line 2] Error at '+': cannot apply Plus: `+` to types number and nil (values 1 and nil) (token.NumberValue and token.NilValue)\n"}}
+	// {"seq":8,"type":"event","event":"output","body":{"category":"stdout","output":"runtime error: {Plus: `+` cannot apply Plus: `+` to types number and nil (values 1 and nil) (token.NumberValue and token.NilValue)}\n"}}
+	// {"seq":9,"type":"event","event":"exited","body":{"exitCode":70}}
+	// {"seq":10,"type":"event","event":"terminated"}
+	// {"seq":11,"type":"response","request_seq":5,"success":true,"command":"disconnect"}
+	// serve: <nil>
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/perlmonger42/go-lox/config"
+	"github.com/perlmonger42/go-lox/debug"
+	"github.com/perlmonger42/go-lox/lox"
+)
+
+// debugCommand implements `go-lox debug`, which runs a Lox program under
+// the interactive debugger. It returns the process's exit status.
+func debugCommand(args []string) int {
+	flags := flag.NewFlagSet("debug", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: go-lox debug file [args...]\n")
+	}
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 64 // see "sysexits.h"
+	}
+
+	filename := flags.Arg(0)
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "go-lox debug: %s\n", err)
+		return 66 // see "sysexits.h"
+	}
+	capabilities := config.AllCapabilities
+	config := config.New()
+	config.Capabilities = capabilities
+	config.Args = flags.Args()[1:]
+	d := debug.New(lox.New(config), string(content), os.Stdin, os.Stdout)
+	if err := d.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "go-lox debug: %s: %s\n", filename, err)
+		return 65 // see "sysexits.h"
+	}
+	return 0
+}
@@ -0,0 +1,245 @@
+package debug
+
+import (
+	"errors"
+
+	"github.com/perlmonger42/go-lox/ast"
+	"github.com/perlmonger42/go-lox/interpret"
+	"github.com/perlmonger42/go-lox/lox"
+	"github.com/perlmonger42/go-lox/parse"
+	"github.com/perlmonger42/go-lox/resolve"
+	"github.com/perlmonger42/go-lox/scan"
+	"github.com/perlmonger42/go-lox/token"
+)
+
+// ErrCompile is returned for a program that cannot be run because of
+// syntax or resolution errors. The errors themselves are reported through
+// lox.
+var ErrCompile = errors.New("program has errors")
+
+// An Action says how a stopped program resumes.
+type Action int
+
+const (
+	Continue Action = iota // run until a breakpoint
+	StepInto               // stop at the next line reached
+	StepOver               // stop at the next line reached in this frame or a caller
+	StepOut                // stop at the next statement in a caller
+	Quit                   // abandon the program
+)
+
+// Reasons a program stops, as passed to a Controller's Stop function.
+const (
+	StopEntry      = "entry"      // before its first statement
+	StopStep       = "step"       // after a step
+	StopBreakpoint = "breakpoint" // at a line with a breakpoint
+)
+
+// A Frame is an active call of a Lox function, or of the program itself.
+type Frame struct {
+	Name string
+	Line int                   // the line of the statement executing in this frame
+	Env  interpret.Environment // the environment of that statement
+}
+
+// A Controller runs a program, following it through the interpreter's
+// Hook: it is told of each statement before it executes, and of each
+// function call as it begins and ends, and from those it keeps its own
+// view of the call stack. It stops the program before its first statement
+// (unless told to Continue first), then whenever a step completes or
+// execution reaches a line with a breakpoint.
+type Controller struct {
+	// Stop is called whenever the program stops, with the reason; the
+	// program resumes as the Action returned says. While Stop runs, the
+	// Controller's other methods may be used to examine the program.
+	Stop func(reason string) Action
+
+	Breakpoints map[int]bool // lines to stop at
+	Frames      []*Frame     // the call stack, innermost last
+
+	lox         *lox.T
+	interpreter interpret.T
+	resolver    *resolve.T
+	natives     map[string]bool // globals that were defined before the program ran
+
+	action     Action
+	stepDepth  int  // the depth of the stack when the step began
+	lastLine   int  // the line of the statement executed most recently
+	lastDepth  int  // the depth of the stack at that statement
+	started    bool // set once the first statement is reached
+	evaluating bool // set while Evaluate runs Lox code
+}
+
+var _ interpret.Hook = &Controller{}
+
+// errQuit is panicked to abandon the program.
+var errQuit = errors.New("quit")
+
+// NewController returns a Controller for programs run with the given lox.
+func NewController(lox *lox.T, stop func(reason string) Action) *Controller {
+	return &Controller{
+		Stop:        stop,
+		Breakpoints: make(map[int]bool),
+		lox:         lox,
+		action:      StepInto,
+	}
+}
+
+// Load scans, parses and resolves a program, ready for Run.
+func (c *Controller) Load(source string) ([]ast.Stmt, error) {
+	tokens := scan.New(c.lox, source).ScanTokens()
+	if c.lox.HadError {
+		return nil, ErrCompile
+	}
+	stmts := parse.New(c.lox, tokens).ParseProg()
+	if c.lox.HadError {
+		return nil, ErrCompile
+	}
+	c.interpreter = interpret.New(c.lox)
+	c.natives = make(map[string]bool)
+	for _, name := range c.interpreter.GlobalNames() {
+		c.natives[name] = true
+	}
+	c.resolver = resolve.New(c.lox, c.interpreter)
+	c.resolver.ResolveStmtList(stmts)
+	if c.lox.HadError {
+		return nil, ErrCompile
+	}
+	return stmts, nil
+}
+
+// Resume sets how the program continues: before Run, Continue means not to
+// stop on entry.
+func (c *Controller) Resume(action Action) {
+	c.action = action
+	c.stepDepth = len(c.Frames)
+}
+
+// Run runs a loaded program to completion, or until abandoned. It reports
+// whether the program ran to completion.
+func (c *Controller) Run(stmts []ast.Stmt) (completed bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			if r != errQuit {
+				panic(r)
+			}
+			completed = false
+		}
+	}()
+	c.Frames = []*Frame{{Name: "<script>", Env: c.interpreter.GetGlobalEnvironment()}}
+	c.interpreter.SetHook(c)
+	c.interpreter.InterpretStmts(stmts)
+	return true
+}
+
+// Statement stops the program, if it should stop, before stmt executes.
+func (c *Controller) Statement(i interpret.T, stmt ast.Stmt) {
+	if c.evaluating {
+		return
+	}
+	if _, ok := stmt.(*ast.Block); ok {
+		return // stop at the statements inside instead
+	}
+	top := c.Frames[len(c.Frames)-1]
+	depth := len(c.Frames)
+	line := ast.StmtLine(stmt)
+	if line == 0 {
+		line = top.Line
+	}
+	moved := line != c.lastLine || depth != c.lastDepth
+	top.Line, top.Env = line, i.GetCurrentEnvironment()
+	c.lastLine, c.lastDepth = line, depth
+
+	reason := ""
+	switch {
+	case moved && c.Breakpoints[line]:
+		reason = StopBreakpoint
+	case c.action == StepInto && moved,
+		c.action == StepOver && moved && depth <= c.stepDepth,
+		c.action == StepOut && depth < c.stepDepth:
+		reason = StopStep
+		if !c.started {
+			reason = StopEntry
+		}
+	}
+	c.started = true
+	if reason != "" {
+		if action := c.Stop(reason); action == Quit {
+			panic(errQuit)
+		} else {
+			c.Resume(action)
+		}
+	}
+}
+
+// Enter pushes a frame for a call of f.
+func (c *Controller) Enter(i interpret.T, f *interpret.LoxFunction) {
+	if !c.evaluating {
+		c.Frames = append(c.Frames, &Frame{Name: f.Declaration.Name.Lexeme()})
+	}
+}
+
+// Leave pops the frame of a call of f.
+func (c *Controller) Leave(i interpret.T, f *interpret.LoxFunction) {
+	if !c.evaluating {
+		c.Frames = c.Frames[:len(c.Frames)-1]
+	}
+}
+
+// Scopes returns the local scopes of a frame, innermost first, following
+// the chain of environments up to (but not including) the globals.
+func (c *Controller) Scopes(frame *Frame) []interpret.Environment {
+	scopes := []interpret.Environment{}
+	for env := frame.Env; env != nil && env.Parent() != nil; env = env.Parent() {
+		scopes = append(scopes, env)
+	}
+	return scopes
+}
+
+// Globals returns the global scope.
+func (c *Controller) Globals() interpret.Environment {
+	return c.interpreter.GetGlobalEnvironment()
+}
+
+// IsNative reports whether a global variable was defined by the
+// interpreter rather than the program.
+func (c *Controller) IsNative(name string) bool {
+	return c.natives[name]
+}
+
+// Stringify returns the string a Lox `print` shows for value.
+func (c *Controller) Stringify(value token.Value) string {
+	return c.interpreter.Stringify(nil, value)
+}
+
+// Evaluate evaluates an expression in the innermost frame. Errors in the
+// expression are reported through lox, but don't count against the
+// program; ok is false if there were any.
+func (c *Controller) Evaluate(text string) (value token.Value, ok bool) {
+	hadError := c.lox.HadError
+	c.lox.HadError = false
+	defer func() { c.lox.HadError = hadError }()
+
+	tokens := scan.New(c.lox, text).ScanTokens()
+	if c.lox.HadError {
+		return nil, false
+	}
+	expr := parse.New(c.lox, tokens).ParseExpr()
+	if c.lox.HadError {
+		return nil, false
+	}
+	envs := c.Scopes(c.Frames[len(c.Frames)-1])
+	names := make([][]string, len(envs))
+	for n, env := range envs {
+		names[len(envs)-1-n] = env.Names()
+	}
+	c.resolver.ResolveExprIn(expr, names)
+	if c.lox.HadError {
+		return nil, false
+	}
+
+	c.evaluating = true
+	defer func() { c.evaluating = false }()
+	value = c.interpreter.InterpretExpr(expr)
+	return value, value != nil && !c.lox.HadError
+}
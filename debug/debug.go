@@ -0,0 +1,213 @@
+// Package debug implements `go-lox debug`, an interactive step debugger for
+// Lox programs. The debugger runs the program in an ordinary interpreter,
+// following it through the interpreter's Hook.
+//
+// The program stops before its first statement, and afterwards whenever a
+// step completes or execution reaches a line with a breakpoint. While it is
+// stopped, commands read from the input examine it or resume it.
+//
+// The Controller, which does the following and stopping, is separate from
+// the command-line interface, so that other front ends (such as the Debug
+// Adapter Protocol server) can share it.
+package debug
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/perlmonger42/go-lox/lox"
+)
+
+// A Debugger runs one program under the control of commands read from its
+// input.
+type Debugger struct {
+	// Echo, if set, writes each command read to the output after the
+	// prompt, which makes a transcript of scripted input readable.
+	Echo bool
+
+	*Controller
+	lines       []string // the program's source, by line
+	in          *bufio.Reader
+	out         io.Writer
+	lastCommand string // repeated by an empty command
+}
+
+// New returns a debugger for the program whose source is given, reading
+// commands from in and writing to out. The program itself prints to the
+// lox configuration's Stdout, as usual.
+func New(lox *lox.T, source string, in io.Reader, out io.Writer) *Debugger {
+	d := &Debugger{
+		lines: strings.Split(source, "\n"),
+		in:    bufio.NewReader(in),
+		out:   out,
+	}
+	d.Controller = NewController(lox, d.pause)
+	return d
+}
+
+// Run runs the program to completion, or until the `quit` command.
+func (d *Debugger) Run() error {
+	stmts, err := d.Load(strings.Join(d.lines, "\n"))
+	if err != nil {
+		return err
+	}
+	if d.Controller.Run(stmts) {
+		fmt.Fprintf(d.out, "program finished\n")
+	}
+	return nil
+}
+
+// pause shows where the program has stopped, then reads and runs commands
+// until one resumes it.
+func (d *Debugger) pause(reason string) Action {
+	top := d.Frames[len(d.Frames)-1]
+	fmt.Fprintf(d.out, "stopped (%s) at line %d in %s\n",
+		reason, top.Line, top.Name)
+	d.showLine(top.Line, true)
+	for {
+		fmt.Fprintf(d.out, "(debug) ")
+		line, err := d.in.ReadString('\n')
+		if err != nil && line == "" {
+			fmt.Fprintf(d.out, "\n")
+			return Quit
+		}
+		line = strings.TrimSpace(line)
+		if d.Echo {
+			fmt.Fprintf(d.out, "%s\n", line)
+		}
+		if line == "" {
+			line = d.lastCommand
+		}
+		d.lastCommand = line
+		if action, resume := d.command(line); resume {
+			return action
+		}
+	}
+}
+
+// command runs one debugger command, reporting whether (and how) it
+// resumes the program.
+func (d *Debugger) command(line string) (action Action, resume bool) {
+	name, arg := line, ""
+	if n := strings.IndexAny(line, " \t"); n >= 0 {
+		name, arg = line[:n], strings.TrimSpace(line[n+1:])
+	}
+	switch name {
+	case "":
+	case "step", "s":
+		return StepInto, true
+	case "next", "n":
+		return StepOver, true
+	case "finish", "out":
+		return StepOut, true
+	case "continue", "c":
+		return Continue, true
+	case "quit", "q":
+		return Quit, true
+	case "break", "b":
+		if n, ok := d.lineArg(arg); ok {
+			d.Breakpoints[n] = true
+			fmt.Fprintf(d.out, "breakpoint at line %d\n", n)
+		}
+	case "delete", "d":
+		if n, ok := d.lineArg(arg); ok {
+			if !d.Breakpoints[n] {
+				fmt.Fprintf(d.out, "no breakpoint at line %d\n", n)
+			}
+			delete(d.Breakpoints, n)
+		}
+	case "breakpoints":
+		d.listBreakpoints()
+	case "backtrace", "bt", "stack":
+		for n := len(d.Frames) - 1; n >= 0; n-- {
+			f := d.Frames[n]
+			fmt.Fprintf(d.out, "#%d %s at line %d\n", len(d.Frames)-1-n, f.Name, f.Line)
+		}
+	case "locals":
+		d.locals()
+	case "print", "p":
+		if value, ok := d.Evaluate(arg); ok {
+			fmt.Fprintf(d.out, "%s\n", d.Stringify(value))
+		}
+	case "list", "l":
+		top := d.Frames[len(d.Frames)-1]
+		for n := top.Line - 3; n <= top.Line+3; n++ {
+			d.showLine(n, n == top.Line)
+		}
+	case "help", "h":
+		fmt.Fprint(d.out, help)
+	default:
+		fmt.Fprintf(d.out, "unknown command %q; try `help`\n", name)
+	}
+	return Continue, false
+}
+
+const help = `step (s)           run to the next line, entering calls
+next (n)           run to the next line, stepping over calls
+finish (out)       run until the current function returns
+continue (c)       run until a breakpoint
+break (b) LINE     set a breakpoint
+delete (d) LINE    clear a breakpoint
+breakpoints        list the breakpoints
+backtrace (bt)     show the call stack
+locals             show the local variables
+print (p) EXPR     evaluate an expression in the current frame
+list (l)           show the source around the current line
+quit (q)           stop the program
+`
+
+func (d *Debugger) lineArg(arg string) (int, bool) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 || n > len(d.lines) {
+		fmt.Fprintf(d.out, "expected a line number from 1 to %d\n", len(d.lines))
+		return 0, false
+	}
+	return n, true
+}
+
+func (d *Debugger) listBreakpoints() {
+	if len(d.Breakpoints) == 0 {
+		fmt.Fprintf(d.out, "no breakpoints\n")
+		return
+	}
+	lines := []int{}
+	for n := range d.Breakpoints {
+		lines = append(lines, n)
+	}
+	sort.Ints(lines)
+	for _, n := range lines {
+		d.showLine(n, false)
+	}
+}
+
+// showLine prints a line of the program, marking the current one.
+func (d *Debugger) showLine(n int, current bool) {
+	if n < 1 || n > len(d.lines) {
+		return
+	}
+	marker := " "
+	if current {
+		marker = ">"
+	}
+	fmt.Fprintf(d.out, "%s%4d  %s\n", marker, n, d.lines[n-1])
+}
+
+// locals prints the local variables of the current frame, innermost scope
+// first.
+func (d *Debugger) locals() {
+	found := false
+	for _, env := range d.Scopes(d.Frames[len(d.Frames)-1]) {
+		for _, name := range env.Names() {
+			value, _ := env.GetAt(0, name)
+			fmt.Fprintf(d.out, "%s = %s\n", name, d.Stringify(value))
+			found = true
+		}
+	}
+	if !found {
+		fmt.Fprintf(d.out, "no locals\n")
+	}
+}
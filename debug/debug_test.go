@@ -0,0 +1,124 @@
+package debug
+
+import (
+	"os"
+	"strings"
+
+	"github.com/perlmonger42/go-lox/config"
+	"github.com/perlmonger42/go-lox/lox"
+)
+
+const program = `fun add(a, b) {
+  var sum = a + b;
+  return sum;
+}
+var x = 1;
+for (var i = 0; i < 2; i = i + 1) {
+  x = add(x, i);
+}
+print x;
+`
+
+// debug runs program under a debugger given the commands.
+func debug(commands ...string) {
+	in := strings.NewReader(strings.Join(commands, "\n") + "\n")
+	d := New(lox.New(config.New()), program, in, os.Stdout)
+	d.Echo = true
+	if err := d.Run(); err != nil {
+		panic(err)
+	}
+}
+
+func ExampleDebugger_breakpoints() {
+	debug("break 2", "break 9", "breakpoints", "continue", "bt", "locals",
+		"print a * 10 + b", "delete 2", "c", "c")
+	// Output:
+	// stopped (entry) at line 1 in <script>
+	// >   1  fun add(a, b) {
+	// (debug) break 2
+	// breakpoint at line 2
+	// (debug) break 9
+	// breakpoint at line 9
+	// (debug) breakpoints
+	//     2    var sum = a + b;
+	//     9  print x;
+	// (debug) continue
+	// stopped (breakpoint) at line 2 in add
+	// >   2    var sum = a + b;
+	// (debug) bt
+	// #0 add at line 2
+	// #1 <script> at line 7
+	// (debug) locals
+	// a = 1
+	// b = 0
+	// (debug) print a * 10 + b
+	// 10
+	// (debug) delete 2
+	// (debug) c
+	// stopped (breakpoint) at line 9 in <script>
+	// >   9  print x;
+	// (debug) c
+	// 2
+	// program finished
+}
+
+func ExampleDebugger_stepping() {
+	debug("n", "n", "n", "n", "s", "s", "locals", "finish", "n", "p x = 100",
+		"step")
+	// Output:
+	// stopped (entry) at line 1 in <script>
+	// >   1  fun add(a, b) {
+	// (debug) n
+	// stopped (step) at line 5 in <script>
+	// >   5  var x = 1;
+	// (debug) n
+	// stopped (step) at line 6 in <script>
+	// >   6  for (var i = 0; i < 2; i = i + 1) {
+	// (debug) n
+	// stopped (step) at line 7 in <script>
+	// >   7    x = add(x, i);
+	// (debug) n
+	// stopped (step) at line 6 in <script>
+	// >   6  for (var i = 0; i < 2; i = i + 1) {
+	// (debug) s
+	// stopped (step) at line 7 in <script>
+	// >   7    x = add(x, i);
+	// (debug) s
+	// stopped (step) at line 2 in add
+	// >   2    var sum = a + b;
+	// (debug) locals
+	// a = 1
+	// b = 1
+	// (debug) finish
+	// stopped (step) at line 6 in <script>
+	// >   6  for (var i = 0; i < 2; i = i + 1) {
+	// (debug) n
+	// stopped (step) at line 9 in <script>
+	// >   9  print x;
+	// (debug) p x = 100
+	// 100
+	// (debug) step
+	// 100
+	// program finished
+}
+
+func ExampleDebugger_quit() {
+	debug("help", "q")
+	// Output:
+	// stopped (entry) at line 1 in <script>
+	// >   1  fun add(a, b) {
+	// (debug) help
+	// step (s)           run to the next line, entering calls
+	// next (n)           run to the next line, stepping over calls
+	// finish (out)       run until the current function returns
+	// continue (c)       run until a breakpoint
+	// break (b) LINE     set a breakpoint
+	// delete (d) LINE    clear a breakpoint
+	// breakpoints        list the breakpoints
+	// backtrace (bt)     show the call stack
+	// locals             show the local variables
+	// print (p) EXPR     evaluate an expression in the current frame
+	// list (l)           show the source around the current line
+	// quit (q)           stop the program
+	// (debug) q
+}
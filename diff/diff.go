@@ -0,0 +1,170 @@
+// Package diff compares texts line by line and renders the differences in
+// unified diff format, as `diff -u` does.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// context is the number of unchanged lines shown around each change.
+const context = 3
+
+// An op is one line of an edit script: kept (' '), deleted ('-') or
+// inserted ('+').
+type op struct {
+	kind byte
+	line string
+}
+
+// Unified returns a unified diff that turns text a into text b, with headers
+// naming them aName and bName. It returns "" if the texts are equal.
+func Unified(aName, bName, a, b string) string {
+	if a == b {
+		return ""
+	}
+	ops := script(splitLines(a), splitLines(b))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", aName, bName)
+	for start := 0; start < len(ops); {
+		// Find the next change, and the end of the hunk containing it: the
+		// first run of more than 2*context kept lines after a change.
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+		end, kept := first, 0
+		for end < len(ops) && kept <= 2*context {
+			if ops[end].kind == ' ' {
+				kept++
+			} else {
+				kept = 0
+			}
+			end++
+		}
+		if kept > context {
+			end -= kept - context
+		}
+		lo := first - context
+		if lo < start {
+			lo = start
+		}
+		writeHunk(&out, ops, lo, end)
+		start = end
+	}
+	return out.String()
+}
+
+// writeHunk writes ops[lo:hi] as a hunk with its "@@" header.
+func writeHunk(out *strings.Builder, ops []op, lo, hi int) {
+	aLine, bLine := 1, 1
+	for _, o := range ops[:lo] {
+		if o.kind != '+' {
+			aLine++
+		}
+		if o.kind != '-' {
+			bLine++
+		}
+	}
+	aCount, bCount := 0, 0
+	for _, o := range ops[lo:hi] {
+		if o.kind != '+' {
+			aCount++
+		}
+		if o.kind != '-' {
+			bCount++
+		}
+	}
+	// An empty range is numbered by the line before it.
+	if aCount == 0 {
+		aLine--
+	}
+	if bCount == 0 {
+		bLine--
+	}
+	fmt.Fprintf(out, "@@ -%s +%s @@\n",
+		hunkRange(aLine, aCount), hunkRange(bLine, bCount))
+	for _, o := range ops[lo:hi] {
+		out.WriteByte(o.kind)
+		out.WriteString(o.line)
+		out.WriteByte('\n')
+	}
+}
+
+func hunkRange(line, count int) string {
+	if count == 1 {
+		return fmt.Sprint(line)
+	}
+	return fmt.Sprintf("%d,%d", line, count)
+}
+
+// splitLines splits text into lines, without their newlines.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// script returns an edit script turning a into b, computed from a longest
+// common subsequence of lines. Lines common to the start and end of both
+// are set aside first, which keeps the table small for typical edits.
+func script(a, b []string) []op {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := []op{}
+	for _, line := range a[:prefix] {
+		ops = append(ops, op{' ', line})
+	}
+
+	ma, mb := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	// lcs[i][j] is the length of the longest common subsequence of ma[i:]
+	// and mb[j:].
+	lcs := make([][]int, len(ma)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(mb)+1)
+	}
+	for i := len(ma) - 1; i >= 0; i-- {
+		for j := len(mb) - 1; j >= 0; j-- {
+			if ma[i] == mb[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(ma) || j < len(mb) {
+		switch {
+		case i < len(ma) && j < len(mb) && ma[i] == mb[j]:
+			ops = append(ops, op{' ', ma[i]})
+			i++
+			j++
+		case j == len(mb) || (i < len(ma) && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, op{'-', ma[i]})
+			i++
+		default:
+			ops = append(ops, op{'+', mb[j]})
+			j++
+		}
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, op{' ', line})
+	}
+	return ops
+}
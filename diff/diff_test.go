@@ -0,0 +1,39 @@
+package diff
+
+import "fmt"
+
+func ExampleUnified() {
+	a := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n"
+	b := "one\n2\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\neleven\n"
+	fmt.Print(Unified("a.lox", "b.lox", a, b))
+	// Output:
+	// --- a.lox
+	// +++ b.lox
+	// @@ -1,5 +1,5 @@
+	//  one
+	// -two
+	// +2
+	//  three
+	//  four
+	//  five
+	// @@ -8,3 +8,4 @@
+	//  eight
+	//  nine
+	//  ten
+	// +eleven
+}
+
+func ExampleUnified_equal() {
+	fmt.Printf("%q\n", Unified("a", "b", "same\n", "same\n"))
+	// Output:
+	// ""
+}
+
+func ExampleUnified_empty() {
+	fmt.Print(Unified("a", "b", "", "new\n"))
+	// Output:
+	// --- a
+	// +++ b
+	// @@ -0,0 +1 @@
+	// +new
+}
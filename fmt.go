@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/perlmonger42/go-lox/config"
+	"github.com/perlmonger42/go-lox/diff"
+	"github.com/perlmonger42/go-lox/format"
+	"github.com/perlmonger42/go-lox/lox"
+	"github.com/perlmonger42/go-lox/report"
+)
+
+// fmtCommand implements `go-lox fmt`, which prints Lox files in canonical
+// layout. It returns the process's exit status.
+func fmtCommand(args []string) int {
+	flags := flag.NewFlagSet("fmt", flag.ExitOnError)
+	write := flags.Bool("w", false, "write result to (source) file instead of stdout")
+	showDiff := flags.Bool("d", false, "display diffs instead of rewriting files")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: go-lox fmt [-w] [-d] [files...]\n")
+		fmt.Fprintf(os.Stderr, "Flags:\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		if *write {
+			fmt.Fprintf(os.Stderr, "go-lox fmt: cannot use -w with standard input\n")
+			return 64 // see "sysexits.h"
+		}
+		content, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "go-lox fmt: %s\n", err)
+			return 66
+		}
+		return fmtText("<standard input>", string(content), false, *showDiff)
+	}
+
+	status := 0
+	for _, filename := range flags.Args() {
+		content, err := ioutil.ReadFile(filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "go-lox fmt: %s\n", err)
+			status = 66 // see "sysexits.h"
+			continue
+		}
+		if s := fmtText(filename, string(content), *write, *showDiff); s != 0 {
+			status = s
+		}
+	}
+	return status
+}
+
+// fmtText formats the source of one file, then prints it, writes it back
+// to the file, or prints a diff against the original.
+func fmtText(filename, src string, write, showDiff bool) int {
+	config := config.New()
+	config.Reporter = report.NewStderrReporter()
+	formatted, err := format.Source(lox.New(config), src)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "go-lox fmt: %s: %s\n", filename, err)
+		return 65 // see "sysexits.h"
+	}
+
+	if showDiff {
+		fmt.Print(diff.Unified(filename+".orig", filename, src, formatted))
+	}
+	if write {
+		if formatted != src {
+			if err := ioutil.WriteFile(filename, []byte(formatted), 0666); err != nil {
+				fmt.Fprintf(os.Stderr, "go-lox fmt: %s\n", err)
+				return 73 // see "sysexits.h"
+			}
+		}
+	} else if !showDiff {
+		fmt.Print(formatted)
+	}
+	return 0
+}
@@ -0,0 +1,236 @@
+// Package format prints Lox programs in canonical layout, as `go-lox fmt`
+// does. Formatting works on the token stream rather than the syntax tree, so
+// that comments (kept by the scanner as token trivia) and the programmer's
+// choice of `for` loops, which the parser desugars, survive unchanged. The
+// parser is still run first, and a program with syntax errors is left alone.
+//
+// The canonical layout:
+//
+//   - one statement per line, indented by two spaces per block level;
+//   - `{` at the end of the line that opens the block, `}` on a line of its
+//     own, followed by ` else` when an else clause follows;
+//   - `{}` for an empty body;
+//   - a space around binary operators and after commas and keywords, none
+//     inside parentheses or brackets or after a unary operator;
+//   - at most one blank line between statements, none at the start or end
+//     of a block;
+//   - comments kept where they were, re-indented, with a trailing comment
+//     separated from its code by one space.
+package format
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/perlmonger42/go-lox/lox"
+	"github.com/perlmonger42/go-lox/parse"
+	"github.com/perlmonger42/go-lox/scan"
+	"github.com/perlmonger42/go-lox/token"
+)
+
+// ErrSyntax is returned for a program that cannot be formatted because it
+// doesn't parse. The errors themselves are reported through lox.
+var ErrSyntax = errors.New("syntax errors")
+
+const indentation = "  "
+
+// Source returns the canonical formatting of the Lox program src.
+func Source(lox *lox.T, src string) (string, error) {
+	tokens := scan.New(lox, src).ScanTokens()
+	if lox.HadError {
+		return "", ErrSyntax
+	}
+	parse.New(lox, tokens).Parse()
+	if lox.HadError {
+		return "", ErrSyntax
+	}
+
+	p := &printer{tokens: tokens, lineStart: true}
+	for p.pos = 0; p.pos < len(tokens); p.pos++ {
+		p.token(tokens[p.pos])
+	}
+	return p.out.String(), nil
+}
+
+type printer struct {
+	out    strings.Builder
+	tokens []token.T
+	pos    int // index of the token being printed
+
+	indent       int  // block nesting level
+	lineStart    bool // nothing has been written on the current line
+	midStatement bool // the current statement has begun but not ended
+	lastLine     int  // source line of the last token or comment written
+	afterOpen    bool // the last thing written was `{`
+
+	// parens records, for each open parenthesis, whether it is the header
+	// of a `for` loop, in which `;` doesn't end a line.
+	parens []bool
+}
+
+func (p *printer) prev() token.T {
+	if p.pos > 0 {
+		return p.tokens[p.pos-1]
+	}
+	return nil
+}
+
+func (p *printer) next() token.T {
+	if p.pos+1 < len(p.tokens) {
+		return p.tokens[p.pos+1]
+	}
+	return nil
+}
+
+func (p *printer) inForHeader() bool {
+	return len(p.parens) > 0 && p.parens[len(p.parens)-1]
+}
+
+// newline ends the current line, if anything has been written on it.
+func (p *printer) newline() {
+	if !p.lineStart {
+		p.out.WriteByte('\n')
+		p.lineStart = true
+	}
+}
+
+// startLine prepares to write something from source line `line` at the
+// start of a line, keeping one blank line if the source had any.
+func (p *printer) startLine(line int, closing bool) {
+	p.newline()
+	if p.out.Len() > 0 && !p.afterOpen && !closing && line > p.lastLine+1 {
+		p.out.WriteByte('\n')
+	}
+}
+
+// write writes text, indenting it if it begins a line. A line that begins in
+// the middle of a statement is a continuation, and is indented further.
+func (p *printer) write(text string) {
+	if p.lineStart {
+		depth := p.indent
+		if p.midStatement {
+			depth++
+		}
+		p.out.WriteString(strings.Repeat(indentation, depth))
+		p.lineStart = false
+	}
+	p.out.WriteString(text)
+}
+
+func (p *printer) comment(c token.Comment) {
+	p.write(c.Text)
+	p.lastLine = c.Pos.Line()
+	p.afterOpen = false
+}
+
+func (p *printer) token(tok token.T) {
+	typ := tok.Type()
+	trivia := tok.Trivia()
+
+	if trivia != nil {
+		for _, c := range trivia.Leading {
+			p.startLine(c.Pos.Line(), false)
+			p.comment(c)
+			p.newline()
+		}
+	}
+
+	switch typ {
+	case token.EOF:
+		p.newline()
+		return
+	case token.RightBrace:
+		if !p.afterOpen {
+			p.startLine(tok.Whence().Line(), true)
+		}
+		p.indent--
+	case token.Else:
+		if !p.lineStart && p.prev().Type() == token.RightBrace {
+			p.write(" ")
+		} else {
+			p.startLine(tok.Whence().Line(), false)
+		}
+	default:
+		if !p.midStatement || p.afterOpen {
+			p.startLine(tok.Whence().Line(), false)
+		} else if !p.lineStart && p.spaceBefore(tok) {
+			p.write(" ")
+		}
+	}
+
+	p.write(tok.Lexeme())
+	p.lastLine = tok.Whence().Line()
+	p.afterOpen = false
+	p.midStatement = true
+
+	switch typ {
+	case token.LeftParen:
+		p.parens = append(p.parens,
+			p.prev() != nil && p.prev().Type() == token.For)
+	case token.RightParen:
+		if len(p.parens) > 0 {
+			p.parens = p.parens[:len(p.parens)-1]
+		}
+	case token.LeftBrace:
+		p.indent++
+		p.midStatement = false
+		if next := p.next(); next != nil && next.Type() == token.RightBrace &&
+			next.Trivia() == nil && (trivia == nil || trivia.Trailing == nil) {
+			// An empty body stays on one line: `{}`.
+			p.afterOpen = true
+		} else {
+			p.afterOpen = true
+			defer p.newline()
+		}
+	case token.RightBrace:
+		p.midStatement = false
+	case token.Semicolon:
+		if !p.inForHeader() {
+			p.midStatement = false
+		}
+	}
+
+	if trivia != nil && trivia.Trailing != nil {
+		p.write(" ")
+		p.comment(*trivia.Trailing)
+		p.newline()
+	}
+}
+
+// spaceBefore reports whether tok is separated by a space from the token
+// before it on the same line.
+func (p *printer) spaceBefore(tok token.T) bool {
+	switch tok.Type() {
+	case token.RightParen, token.RightBrack, token.Semicolon, token.Comma,
+		token.Dot, token.Colon:
+		return false
+	}
+
+	prev := p.prev()
+	switch prev.Type() {
+	case token.LeftParen, token.LeftBrack, token.Dot, token.Bang:
+		return false
+	case token.Minus:
+		if p.pos >= 2 && !endsOperand(p.tokens[p.pos-2]) {
+			return false // after unary minus
+		}
+	}
+
+	switch tok.Type() {
+	case token.LeftParen, token.LeftBrack:
+		return !endsOperand(prev) // no space before a call or index
+	}
+	return true
+}
+
+// endsOperand reports whether tok can be the last token of an operand, in
+// which case a following `(` is a call, `[` an index, and `-` binary.
+func endsOperand(tok token.T) bool {
+	switch tok.Type() {
+	case token.Identifier, token.Number, token.String, token.True,
+		token.False, token.Nil, token.This, token.Super,
+		token.RightParen, token.RightBrack:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package format
+
+import (
+	"fmt"
+
+	"github.com/perlmonger42/go-lox/config"
+	"github.com/perlmonger42/go-lox/lox"
+)
+
+// formatText prints the formatting of text, and complains if formatting
+// that again changes it.
+func formatText(text string) {
+	out, err := Source(lox.New(config.New()), text)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Print(out)
+	if again, _ := Source(lox.New(config.New()), out); again != out {
+		fmt.Printf("not idempotent; formatting again gives:\n%s", again)
+	}
+}
+
+func ExampleSpacing() {
+	formatText(`var   x=1+2*-3 ;print -x;print !(x==1) ;print a.b(c)[d]-e;`)
+	// Output:
+	// var x = 1 + 2 * -3;
+	// print -x;
+	// print !(x == 1);
+	// print a.b(c)[d] - e;
+}
+
+func ExampleBlocks() {
+	formatText(`fun f(a,b){return a-b;}
+for(var i=0;i<10;i=i+1){print i;}
+if(x>1){print "a";}else if(!x){print "b";}else print "c";
+if (x) print 1; else print 2;
+while(true) {
+}`)
+	// Output:
+	// fun f(a, b) {
+	//   return a - b;
+	// }
+	// for (var i = 0; i < 10; i = i + 1) {
+	//   print i;
+	// }
+	// if (x > 1) {
+	//   print "a";
+	// } else if (!x) {
+	//   print "b";
+	// } else print "c";
+	// if (x) print 1;
+	// else print 2;
+	// while (true) {}
+}
+
+func ExampleClasses() {
+	formatText(`class A<B with T,U{init(x){this.x=x;}   area{return 1;}
+set size(v){this.s=v;} class k=3;} trait T{m(){}}`)
+	// Output:
+	// class A < B with T, U {
+	//   init(x) {
+	//     this.x = x;
+	//   }
+	//   area {
+	//     return 1;
+	//   }
+	//   set size(v) {
+	//     this.s = v;
+	//   }
+	//   class k = 3;
+	// }
+	// trait T {
+	//   m() {}
+	// }
+}
+
+func ExampleComments() {
+	formatText(`// leading comment
+
+
+var x = 1;   // trailing
+{
+     // inner comment
+
+
+  x = x +  // broken
+        1;
+  // last in block
+
+}
+// end comment
+`)
+	// Output:
+	// // leading comment
+	//
+	// var x = 1; // trailing
+	// {
+	//   // inner comment
+	//
+	//   x = x + // broken
+	//     1;
+	//   // last in block
+	// }
+	// // end comment
+}
+
+func ExampleSyntaxError() {
+	formatText(`print (;`)
+	// Output:
+	// [line 1] Error at ';': Expect expression, found `;`.
+	// syntax errors
+}
@@ -3,6 +3,6 @@ module github.com/perlmonger42/go-lox
 go 1.19
 
 require (
-	github.com/bobappleyard/readline v0.0.0-20150707195538-7e300e02d38e // indirect
-	golang.org/x/text v0.3.7 // indirect
+	github.com/bobappleyard/readline v0.0.0-20150707195538-7e300e02d38e
+	golang.org/x/text v0.3.7
 )
@@ -0,0 +1,159 @@
+// Package golden runs Lox test programs that state their own expected
+// results in comments, in the style of the test suite of Crafting
+// Interpreters (https://github.com/munificent/craftinginterpreters), and is
+// the engine of `go-lox -test`.
+//
+// A test's expectations, in the order they should occur, are:
+//
+//	print 1 + 2; // expect: 3
+//	print x;     // expect runtime error: Undefined variable 'x'.
+//	var 1;       // Error at '1': Expect variable name, found number `1`.
+//	// [line 7] Error at end: Expect '}' after block.
+//
+// "expect:" gives a line of output. "expect runtime error:" gives the
+// message of the runtime error that ends the program, raised on the line of
+// the comment. "Error" gives a compile error reported on the line of the
+// comment, or, prefixed with "[line N]", on line N. Expectations for other
+// implementations ("[c line N]") are ignored.
+package golden
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/perlmonger42/go-lox/capture"
+	"github.com/perlmonger42/go-lox/config"
+	"github.com/perlmonger42/go-lox/diff"
+)
+
+var (
+	expectOutput       = regexp.MustCompile(`// expect: ?(.*)`)
+	expectRuntimeError = regexp.MustCompile(`// expect runtime error: (.+)`)
+	expectError        = regexp.MustCompile(`// (Error.*)`)
+	expectErrorLine    = regexp.MustCompile(`// \[(java )?line (\d+)\] (Error.*)`)
+)
+
+// Expected returns the transcript that the comments of a test program
+// expect it to produce: a line for each line of output and each error.
+func Expected(source string) string {
+	var b strings.Builder
+	for n, line := range strings.Split(source, "\n") {
+		if m := expectOutput.FindStringSubmatch(line); m != nil {
+			fmt.Fprintf(&b, "%s\n", m[1])
+		} else if m := expectRuntimeError.FindStringSubmatch(line); m != nil {
+			fmt.Fprintf(&b, "[line %d] runtime error: %s\n", n+1, m[1])
+		} else if m := expectErrorLine.FindStringSubmatch(line); m != nil {
+			fmt.Fprintf(&b, "[line %s] %s\n", m[2], m[3])
+		} else if m := expectError.FindStringSubmatch(line); m != nil {
+			fmt.Fprintf(&b, "[line %d] %s\n", n+1, m[1])
+		}
+	}
+	return b.String()
+}
+
+// Actual runs a test program in a fresh interpreter, with the given
+// capabilities, and returns the transcript of what it printed and the
+// errors reported.
+func Actual(source string, capabilities config.Capability) string {
+	result := capture.Run(capabilities, source)
+	var b strings.Builder
+	b.WriteString(result.Output)
+	for _, err := range result.Errors {
+		if err.Runtime {
+			fmt.Fprintf(&b, "[%s] runtime error: %s\n", err.Pos, err.Message)
+			continue
+		}
+		pad := ""
+		if err.Where != "" {
+			pad = " "
+		}
+		fmt.Fprintf(&b, "[%s] Error%s%s: %s\n", err.Pos, pad, err.Where, err.Message)
+	}
+	return b.String()
+}
+
+// Check runs a test program, returning a unified diff of the transcript
+// its comments expect against the one it produced, or "" if they agree.
+func Check(filename, source string, capabilities config.Capability) (result string) {
+	expected := Expected(source)
+	defer func() {
+		// A crash of the interpreter fails the test rather than the run.
+		if r := recover(); r != nil {
+			result = fmt.Sprintf("--- %s (expected)\n+++ %s (actual)\npanic: %v\n",
+				filename, filename, r)
+		}
+	}()
+	actual := Actual(source, capabilities)
+	return diff.Unified(filename+" (expected)", filename+" (actual)", expected, actual)
+}
+
+// Files returns the Lox files named by paths, in order: each path is a Lox
+// file, or a directory searched recursively for files ending in ".lox".
+func Files(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		var found []string
+		err = filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && strings.HasSuffix(p, ".lox") {
+				found = append(found, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(found)
+		files = append(files, found...)
+	}
+	return files, nil
+}
+
+// Summary counts the tests run by Run.
+type Summary struct {
+	Passed, Failed int
+}
+
+func (s Summary) String() string {
+	return fmt.Sprintf("%d passed, %d failed", s.Passed, s.Failed)
+}
+
+// Run checks the test programs named by paths (see Files), reporting each
+// as PASS or FAIL, with a diff for each failure, to out.
+func Run(out io.Writer, paths []string, capabilities config.Capability) (Summary, error) {
+	var summary Summary
+	files, err := Files(paths)
+	if err != nil {
+		return summary, err
+	}
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return summary, err
+		}
+		if d := Check(file, string(content), capabilities); d != "" {
+			summary.Failed++
+			fmt.Fprintf(out, "FAIL %s\n%s", file, d)
+		} else {
+			summary.Passed++
+			fmt.Fprintf(out, "PASS %s\n", file)
+		}
+	}
+	return summary, nil
+}
@@ -0,0 +1,51 @@
+package golden
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/perlmonger42/go-lox/config"
+)
+
+func ExampleRun() {
+	summary, err := Run(os.Stdout, []string{"testdata"}, config.NoCapabilities)
+	fmt.Println(summary, err)
+	// Output:
+	// PASS testdata/closure.lox
+	// PASS testdata/compile_error.lox
+	// PASS testdata/runtime_error.lox
+	// PASS testdata/unannotated.lox
+	// 4 passed, 0 failed <nil>
+}
+
+func ExampleExpected() {
+	fmt.Print(Expected(`print 1; // expect: 1
+print "a" + "b"; // expect: ab
+// [line 5] Error at end: Expect ';' after value.
+// [c line 5] Error at end: Expect ';' after value.
+print x; // expect runtime error: Undefined variable 'x'.
+`))
+	// Output:
+	// 1
+	// ab
+	// [line 5] Error at end: Expect ';' after value.
+	// [line 5] runtime error: Undefined variable 'x'.
+}
+
+func ExampleCheck() {
+	fmt.Print(Check("sum.lox", `var a = 1;
+print a;     // expect: 1
+print a + 1; // expect: 3
+print a + 2; // expect: 3
+print b;     // expect runtime error: Undefined variable 'b'.
+`, config.NoCapabilities))
+	// Output:
+	// --- sum.lox (expected)
+	// +++ sum.lox (actual)
+	// @@ -1,4 +1,4 @@
+	//  1
+	// -3
+	// +2
+	//  3
+	//  [line 5] runtime error: Undefined variable 'b'.
+}
@@ -0,0 +1,46 @@
+package interpret
+
+import (
+	"github.com/perlmonger42/go-lox/token"
+)
+
+// defineAssertNatives installs the natives with which Lox code, such as
+// the tests run by `go-lox test`, checks its own results. Each raises a
+// runtime error, located at the call, when its check fails:
+//
+//	assert(condition, message)       unless condition is truthy
+//	assertEqual(actual, expected)    unless actual == expected
+//	assertNotEqual(actual, other)    if actual == other
+//	fail(message)                    always
+func (i *Interpreter) defineAssertNatives() {
+	i.defineNative("assert(condition, message)", 2,
+		func(_ T, arguments []token.Value) token.Value {
+			if !token.IsTruthy(arguments[0]) {
+				panic(nativeError("assertion failed: %s", i.Stringify(nil, arguments[1])))
+			}
+			return token.NilValue{}
+		})
+
+	i.defineNative("assertEqual(actual, expected)", 2,
+		func(_ T, arguments []token.Value) token.Value {
+			if !arguments[0].IsEqualTo(arguments[1]) {
+				panic(nativeError("assertEqual: got %s, want %s",
+					arguments[0].Show(), arguments[1].Show()))
+			}
+			return token.NilValue{}
+		})
+
+	i.defineNative("assertNotEqual(actual, other)", 2,
+		func(_ T, arguments []token.Value) token.Value {
+			if arguments[0].IsEqualTo(arguments[1]) {
+				panic(nativeError("assertNotEqual: got %s, want anything else",
+					arguments[0].Show()))
+			}
+			return token.NilValue{}
+		})
+
+	i.defineNative("fail(message)", 1,
+		func(_ T, arguments []token.Value) token.Value {
+			panic(nativeError("failed: %s", i.Stringify(nil, arguments[0])))
+		})
+}
@@ -0,0 +1,24 @@
+package interpret
+
+func ExampleAssertEqual() {
+	exec(`
+assertEqual(1 + 2, 3);
+assertNotEqual("a", "b");
+assert(true, "not reached");
+print "ok";
+assertEqual("a" + "b", "abc");
+	`)
+	// Output:
+	// ok
+	// [line 6] Error at ')': assertEqual: got "ab", want "abc"
+	// runtime error: {RightParen: `)` assertEqual: got "ab", want "abc"}
+}
+
+func ExampleAssert() {
+	exec(`
+assert(1 > 2, "one is not more than two");
+	`)
+	// Output:
+	// [line 2] Error at ')': assertion failed: one is not more than two
+	// runtime error: {RightParen: `)` assertion failed: one is not more than two}
+}
@@ -1,32 +1,80 @@
 package interpret
 
 import (
+	"fmt"
+
+	"github.com/perlmonger42/go-lox/ast"
 	"github.com/perlmonger42/go-lox/token"
 )
 
+// LoxClass is a class value. Besides the instance methods it hands to its
+// instances, it has static methods and fields of its own, which subclasses
+// inherit. Getters live with the methods; setters are kept apart, since a
+// property may have both.
 type LoxClass struct {
-	Name       token.T
-	Superclass *LoxClass
-	Methods    map[string]*LoxFunction
+	Name          token.T
+	Superclass    *LoxClass
+	Methods       map[string]*LoxFunction
+	Setters       map[string]*LoxFunction
+	StaticMethods map[string]*LoxFunction
+	StaticSetters map[string]*LoxFunction
+	Fields        map[string]token.Value
 }
 
 var _ token.Object = &LoxClass{}
 var _ LoxCallable = &LoxClass{}
 
+// A MethodTable collects the methods, getters and setters of one kind
+// (instance or static) declared in a class body.
+type MethodTable struct {
+	Methods map[string]*LoxFunction // methods and getters
+	Setters map[string]*LoxFunction
+}
+
+func NewMethodTable() MethodTable {
+	return MethodTable{
+		Methods: make(map[string]*LoxFunction),
+		Setters: make(map[string]*LoxFunction),
+	}
+}
+
+func (t MethodTable) Add(f *LoxFunction) {
+	if f.Declaration.Kind == ast.SetterFunction {
+		t.Setters[f.Declaration.Name.Lexeme()] = f
+	} else {
+		t.Methods[f.Declaration.Name.Lexeme()] = f
+	}
+}
+
 func NewLoxClass(
 	name token.T,
 	superclass *LoxClass,
-	methods map[string]*LoxFunction,
+	instanceMembers MethodTable,
+	staticMembers MethodTable,
 ) *LoxClass {
 	return &LoxClass{
-		Name:       name,
-		Superclass: superclass,
-		Methods:    methods,
+		Name:          name,
+		Superclass:    superclass,
+		Methods:       instanceMembers.Methods,
+		Setters:       instanceMembers.Setters,
+		StaticMethods: staticMembers.Methods,
+		StaticSetters: staticMembers.Setters,
+		Fields:        make(map[string]token.Value),
 	}
 }
 
+// findInitializer returns the class's `init` method, ignoring a getter that
+// happens to have that name.
+func (f *LoxClass) findInitializer() (*LoxFunction, bool) {
+	if method, ok := f.FindMethod("init"); ok &&
+		method.Declaration.Kind == ast.OrdinaryFunction {
+		return method, true
+	}
+	return nil, false
+}
+
 func (f *LoxClass) Arity() int {
-	if initializer, ok := f.FindMethod("init"); ok {
+	if initializer, ok := f.findInitializer(); ok {
 		return initializer.Arity()
 	}
 	return 0
@@ -34,7 +82,7 @@ func (f *LoxClass) Arity() int {
 
 func (f *LoxClass) Call(i T, arguments []token.Value) (result token.Value) {
 	var instance *LoxInstance = NewLoxInstance(f)
-	if initializer, ok := f.FindMethod("init"); ok {
+	if initializer, ok := f.findInitializer(); ok {
 		initializer.Bind(instance).Call(i, arguments)
 	}
 	return token.ObjectValue{instance}
@@ -48,6 +96,74 @@ func (c *LoxClass) FindMethod(name string) (method *LoxFunction, ok bool) {
 	return
 }
 
+func (c *LoxClass) FindStaticMethod(name string) (method *LoxFunction, ok bool) {
+	method, ok = c.StaticMethods[name]
+	if !ok && c.Superclass != nil {
+		method, ok = c.Superclass.FindStaticMethod(name)
+	}
+	return
+}
+
+func (c *LoxClass) FindSetter(name string) (method *LoxFunction, ok bool) {
+	method, ok = c.Setters[name]
+	if !ok && c.Superclass != nil {
+		method, ok = c.Superclass.FindSetter(name)
+	}
+	return
+}
+
+func (c *LoxClass) FindStaticSetter(name string) (method *LoxFunction, ok bool) {
+	method, ok = c.StaticSetters[name]
+	if !ok && c.Superclass != nil {
+		method, ok = c.Superclass.FindStaticSetter(name)
+	}
+	return
+}
+
+// FindField returns the value of the named static field of c or of its
+// nearest superclass that has one.
+func (c *LoxClass) FindField(name string) (value token.Value, ok bool) {
+	value, ok = c.Fields[name]
+	if !ok && c.Superclass != nil {
+		value, ok = c.Superclass.FindField(name)
+	}
+	return
+}
+
+// Get returns a static field, the result of a static getter, or a static
+// method bound to c (so that `this` in the method refers to c, even when the
+// method is inherited). A class's own statics, fields and methods alike,
+// come before those of its superclass.
+func (c *LoxClass) Get(i T, name token.T) (token.Value, error) {
+	for class := c; class != nil; class = class.Superclass {
+		if value, ok := class.Fields[name.Lexeme()]; ok {
+			return value, nil
+		}
+		if method, ok := class.StaticMethods[name.Lexeme()]; ok {
+			return method.BindProperty(i, c), nil
+		}
+	}
+
+	return &token.NilValue{},
+		&RuntimeError{name,
+			fmt.Sprintf("Undefined property `%s`.", name.Lexeme())}
+}
+
+// Set calls a static setter, or assigns a static field of c itself; a
+// subclass that assigns an inherited field gets its own copy.
+func (c *LoxClass) Set(i T, name token.T, val token.Value) error {
+	if setter, ok := c.FindStaticSetter(name.Lexeme()); ok {
+		setter.Bind(c).Call(i, []token.Value{val})
+		return nil
+	}
+	if getter, ok := c.FindStaticMethod(name.Lexeme()); ok &&
+		getter.Declaration.Kind == ast.GetterFunction {
+		return readOnlyError(name)
+	}
+	c.Fields[name.Lexeme()] = val
+	return nil
+}
+
 func (c *LoxClass) EqualsObject(o token.Object) bool {
 	if lf, ok := o.(*LoxClass); ok {
 		return c.Name == lf.Name
@@ -55,6 +171,8 @@ func (c *LoxClass) EqualsObject(o token.Object) bool {
 	return false
 }
 
+func (c *LoxClass) TypeName() string { return "class" }
+
 func (c *LoxClass) String() string {
 	return "class " + c.Name.Lexeme()
 }
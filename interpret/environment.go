@@ -2,6 +2,7 @@ package interpret
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/perlmonger42/go-lox/token"
 )
@@ -14,6 +15,8 @@ type Environment interface {
 	AssignAt(distance int, name token.T, value Value) *RuntimeError
 	GetLocal(name token.T) (Value, *RuntimeError)
 	GetAt(distance int, name string) (Value, error)
+	Names() []string     // sorted names defined here, not in enclosing scopes
+	Parent() Environment // the enclosing scope, or nil for the globals
 
 	Dump(msg string)
 }
@@ -67,6 +70,17 @@ func (v *globalEnv) AssignAt(
 		distance))
 }
 
+func (v *globalEnv) Names() []string {
+	names := make([]string, 0, len(v.Values))
+	for name := range v.Values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (v *globalEnv) Parent() Environment { return nil }
+
 func (v *globalEnv) GetLocal(name token.T) (Value, *RuntimeError) {
 	if v, ok := v.Values[name.Lexeme()]; ok {
 		return v, nil
@@ -134,6 +148,8 @@ func (v *nestedEnv) AssignAt(
 	return v.Enclosing.AssignAt(distance-1, name, value)
 }
 
+func (v *nestedEnv) Parent() Environment { return v.Enclosing }
+
 func (v *nestedEnv) GetAt(distance int, name string) (Value, error) {
 	if distance > 0 {
 		return v.Enclosing.GetAt(distance-1, name)
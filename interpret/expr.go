@@ -74,17 +74,27 @@ func (i *Interpreter) Visit_SuperExpr_Token_Value(expr *ast.Super) Value {
 	// "this" is always bound just inside the environment that binds "super"
 	object := i.GetThisAt(distance - 1)
 
+	// In a static method, `this` is a class and `super` finds static methods.
+	find := superclass.FindMethod
+	if _, ok := object.(*LoxClass); ok {
+		find = superclass.FindStaticMethod
+	}
+
 	var method *LoxFunction
 	var ok bool
-	if method, ok = superclass.FindMethod(expr.Method.Lexeme()); !ok {
+	if method, ok = find(expr.Method.Lexeme()); !ok {
 		panic(i.Error(expr.Method,
 			fmt.Sprintf("Undefined property '%s'.",
 				expr.Method.Lexeme())))
 	} else {
-		return token.ObjectValue{method.Bind(object)}
+		return method.BindProperty(i, object)
 	}
 }
 
+func (i *Interpreter) Visit_BadExprExpr_Token_Value(expr *ast.BadExpr) Value {
+	panic(i.Error(expr.From, "Cannot evaluate an expression that failed to parse."))
+}
+
 func (i *Interpreter) Visit_GroupingExpr_Token_Value(expr *ast.Grouping) Value {
 	return i.evaluate(expr.Expression)
 }
@@ -102,14 +112,36 @@ func (i *Interpreter) Visit_CallExpr_Token_Value(expr *ast.Call) Value {
 				function.Arity(),
 				len(expr.Arguments))))
 	}
+	switch function.(type) {
+	case *NativeFunction, *StrNative:
+		return i.callNative(expr.Paren, function, arguments)
+	}
 	return function.Call(i, arguments)
 }
 
+// callNative calls a native function, converting any NativeError it raises
+// into a RuntimeError reported at the call's closing parenthesis.
+func (i *Interpreter) callNative(
+	paren token.T,
+	native Callable,
+	arguments []token.Value,
+) token.Value {
+	defer func() {
+		if r := recover(); r != nil {
+			if nerr, ok := r.(NativeError); ok {
+				panic(i.Error(paren, nerr.Message))
+			}
+			panic(r)
+		}
+	}()
+	return native.Call(i, arguments)
+}
+
 func (i *Interpreter) Visit_GetExpr_Token_Value(expr *ast.Get) Value {
 	var lhs Value = i.evaluate(expr.Object)
 	if obj, ok := lhs.(token.ObjectValue); ok {
-		if instance, ok := obj.V.(*LoxInstance); ok {
-			if value, err := instance.Get(expr.Name); err != nil {
+		if holder, ok := obj.V.(PropertyHolder); ok {
+			if value, err := holder.Get(i, expr.Name); err != nil {
 				panic(i.Error(expr.Name, err.Error()))
 			} else {
 				return value
@@ -122,13 +154,15 @@ func (i *Interpreter) Visit_GetExpr_Token_Value(expr *ast.Get) Value {
 func (i *Interpreter) Visit_SetExpr_Token_Value(expr *ast.Set) Value {
 	var lhs Value = i.evaluate(expr.Object)
 	if obj, ok := lhs.(token.ObjectValue); ok {
-		if instance, ok := obj.V.(*LoxInstance); ok {
+		if holder, ok := obj.V.(FieldHolder); ok {
 			var rhs Value = i.evaluate(expr.Value)
-			instance.Set(expr.Name, rhs)
+			if err := holder.Set(i, expr.Name, rhs); err != nil {
+				panic(i.Error(expr.Name, err.Error()))
+			}
 			return rhs
 		}
 	}
-	panic(i.Error(expr.Name, "Only instances have fields."))
+	panic(i.Error(expr.Name, "Only instances and classes have fields."))
 }
 
 func (i *Interpreter) GetCallable(paren token.T, v token.Value) Callable {
@@ -155,6 +189,9 @@ func (i *Interpreter) Visit_UnaryExpr_Token_Value(expr *ast.Unary) Value {
 		case token.NumberValue:
 			return token.NumberValue{-r.V}
 		}
+		if instance, method, ok := findSpecialMethod(right, "negate"); ok {
+			return i.callSpecialMethod(expr.Operator, instance, method)
+		}
 	case token.Bang:
 		switch r := right.(type) {
 		case token.BooleanValue:
@@ -176,6 +213,9 @@ func (i *Interpreter) Visit_UnaryExpr_Token_Value(expr *ast.Unary) Value {
 func (i *Interpreter) Visit_BinaryExpr_Token_Value(expr *ast.Binary) Value {
 	var left Value = i.evaluate(expr.Left)
 	var right Value = i.evaluate(expr.Right)
+	if result, ok := i.binaryOverload(expr, left, right); ok {
+		return result
+	}
 	switch expr.Operator.Type() {
 	case token.Minus:
 		switch l := left.(type) {
@@ -290,15 +330,18 @@ func (i *Interpreter) Visit_LogicalExpr_Token_Value(expr *ast.Logical) Value {
 	var left Value = i.evaluate(expr.Left)
 
 	if expr.Operator.Type() == token.Or {
-		if isTruthy(left) {
+		if token.IsTruthy(left) {
+			i.branch(expr, ShortCircuitArm)
 			return left
 		}
 	} else {
-		if !isTruthy(left) {
+		if !token.IsTruthy(left) {
+			i.branch(expr, ShortCircuitArm)
 			return left
 		}
 	}
 
+	i.branch(expr, RightArm)
 	return i.evaluate(expr.Right)
 }
 
@@ -62,8 +62,8 @@ func ExampleTypeMismatch() {
 	eval(`7+""`)
 	eval(`nil-4`)
 	// Output:
-	// [line 1] Error at 'Plus': cannot apply Plus: `+` to types number and string (values 7 and "") (token.NumberValue and token.StringValue)
-	// [line 1] Error at 'Minus': cannot apply Minus: `-` to types nil and number (values nil and 4) (token.NilValue and token.NumberValue)
+	// [line 1] Error at '+': cannot apply Plus: `+` to types number and string (values 7 and "") (token.NumberValue and token.StringValue)
+	// [line 1] Error at '-': cannot apply Minus: `-` to types nil and number (values nil and 4) (token.NilValue and token.NumberValue)
 }
 
 func ExampleBinary() {
@@ -75,8 +75,8 @@ func ExampleBinary() {
 	eval("-3 * 6 / (7 - (4+3))")
 	// Output:
 	// 10
-	// [line 1] Error at 'Plus': cannot apply Plus: `+` to types boolean and nil (values true and nil) (token.BooleanValue and token.NilValue)
-	// [line 1] Error at 'Slash': cannot apply Slash: `/` to types number and boolean (values 6 and false) (token.NumberValue and token.BooleanValue)
+	// [line 1] Error at '+': cannot apply Plus: `+` to types boolean and nil (values true and nil) (token.BooleanValue and token.NilValue)
+	// [line 1] Error at '/': cannot apply Slash: `/` to types number and boolean (values 6 and false) (token.NumberValue and token.BooleanValue)
 	// 3.5
 	// +Inf
 	// -Inf
@@ -90,10 +90,10 @@ func ExampleUnaryMinus() {
 	eval(`-"bar"`)
 	// Output:
 	// -8
-	// [line 1] Error at 'Minus': cannot apply Minus: `-` to type nil (nil) (token.NilValue)
-	// [line 1] Error at 'Minus': cannot apply Minus: `-` to type boolean (false) (token.BooleanValue)
-	// [line 1] Error at 'Minus': cannot apply Minus: `-` to type boolean (true) (token.BooleanValue)
-	// [line 1] Error at 'Minus': cannot apply Minus: `-` to type string ("bar") (token.StringValue)
+	// [line 1] Error at '-': cannot apply Minus: `-` to type nil (nil) (token.NilValue)
+	// [line 1] Error at '-': cannot apply Minus: `-` to type boolean (false) (token.BooleanValue)
+	// [line 1] Error at '-': cannot apply Minus: `-` to type boolean (true) (token.BooleanValue)
+	// [line 1] Error at '-': cannot apply Minus: `-` to type string ("bar") (token.StringValue)
 }
 
 func ExampleUnaryBang() {
@@ -106,8 +106,8 @@ func ExampleUnaryBang() {
 	// true
 	// true
 	// false
-	// [line 1] Error at 'Bang': cannot apply Bang: `!` to type string ("hello, world!") (token.StringValue)
-	// [line 1] Error at 'Bang': cannot apply Bang: `!` to type number (432) (token.NumberValue)
+	// [line 1] Error at '!': cannot apply Bang: `!` to type string ("hello, world!") (token.StringValue)
+	// [line 1] Error at '!': cannot apply Bang: `!` to type number (432) (token.NumberValue)
 }
 
 func ExampleUnaryUnary() {
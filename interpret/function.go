@@ -54,6 +54,11 @@ func (f *LoxFunction) Call(i T, arguments []token.Value) (result token.Value) {
 		}
 	}()
 
+	if hook := i.getHook(); hook != nil {
+		hook.Enter(i, f)
+		defer hook.Leave(i, f)
+	}
+
 	environment := NewNestedEnvironment(f.Closure)
 	for i, param := range f.Declaration.Params {
 		environment.Define(param, arguments[i])
@@ -62,7 +67,9 @@ func (f *LoxFunction) Call(i T, arguments []token.Value) (result token.Value) {
 	return &token.NilValue{}
 }
 
-func (f *LoxFunction) Bind(instance *LoxInstance) *LoxFunction {
+// Bind returns a copy of f in which `this` refers to the given instance (or,
+// for a static method, class).
+func (f *LoxFunction) Bind(instance token.Object) *LoxFunction {
 	env := NewNestedEnvironment(f.Closure)
 	this := &token.Token{
 		Type_:   token.This,
@@ -75,6 +82,17 @@ func (f *LoxFunction) Bind(instance *LoxInstance) *LoxFunction {
 	//return token.ObjectValue{bound}
 }
 
+// BindProperty binds f to an instance or class whose property f was found
+// as. A getter is called immediately and its result returned; any other
+// method is returned as a bound method value.
+func (f *LoxFunction) BindProperty(i T, this token.Object) token.Value {
+	bound := f.Bind(this)
+	if f.Declaration.Kind == ast.GetterFunction {
+		return bound.Call(i, []token.Value{})
+	}
+	return token.ObjectValue{bound}
+}
+
 func (f *LoxFunction) EqualsObject(o token.Object) bool {
 	if lf, ok := o.(*LoxFunction); ok {
 		return f.Declaration == lf.Declaration
@@ -82,6 +100,8 @@ func (f *LoxFunction) EqualsObject(o token.Object) bool {
 	return false
 }
 
+func (f *LoxFunction) TypeName() string { return "function" }
+
 func (f *LoxFunction) String() string {
 	return ast.StmtToString(f.Declaration)
 }
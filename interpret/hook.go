@@ -0,0 +1,59 @@
+package interpret
+
+import (
+	"github.com/perlmonger42/go-lox/ast"
+)
+
+// A Hook follows the progress of execution, as a debugger must. While the
+// hook runs, the interpreter's current environment is that of the code
+// being executed.
+type Hook interface {
+	// Statement is called before each statement is executed.
+	Statement(i T, stmt ast.Stmt)
+
+	// Enter is called as a call of f begins, and Leave as it ends (whether
+	// by returning or by a runtime error).
+	Enter(i T, f *LoxFunction)
+	Leave(i T, f *LoxFunction)
+}
+
+// A BranchHook is a Hook that is also told which way each branch goes, as a
+// coverage tool must.
+type BranchHook interface {
+	Hook
+
+	// Branch is called as execution takes one arm of a branch:
+	//
+	//	if:        ThenArm or ElseArm (taken even if there is no else clause)
+	//	while:     BodyArm each time the body runs, then ExitArm
+	//	and, or:   ShortCircuitArm, or RightArm when the right operand is
+	//	           evaluated
+	Branch(i T, node ast.Node, arm int)
+}
+
+// Arms of branches, as passed to BranchHook.Branch.
+const (
+	ThenArm         = 0
+	ElseArm         = 1
+	BodyArm         = 0
+	ExitArm         = 1
+	ShortCircuitArm = 0
+	RightArm        = 1
+)
+
+// SetHook installs the hook to be called during execution; nil removes it.
+// If the hook is a BranchHook, it is told of branches too.
+func (i *Interpreter) SetHook(hook Hook) {
+	i.hook = hook
+	i.branchHook, _ = hook.(BranchHook)
+}
+
+// branch tells the BranchHook, if there is one, which arm of a branch
+// execution takes.
+func (i *Interpreter) branch(node ast.Node, arm int) {
+	if i.branchHook != nil {
+		i.branchHook.Branch(i, node, arm)
+	}
+}
+
+func (i *Interpreter) getHook() Hook { return i.hook }
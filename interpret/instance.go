@@ -2,8 +2,10 @@ package interpret
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
+	"github.com/perlmonger42/go-lox/ast"
 	"github.com/perlmonger42/go-lox/token"
 )
 
@@ -18,14 +20,14 @@ func NewLoxInstance(class *LoxClass) *LoxInstance {
 	return &LoxInstance{class: class, fields: make(map[string]token.Value)}
 }
 
-func (i *LoxInstance) Get(name token.T) (token.Value, error) {
+// Get returns a field, the result of calling a getter, or a bound method.
+func (i *LoxInstance) Get(interp T, name token.T) (token.Value, error) {
 	if value, ok := i.fields[name.Lexeme()]; ok {
 		return value, nil
 	}
 
 	if method, ok := i.class.FindMethod(name.Lexeme()); ok {
-		bound_method := method.Bind(i)
-		return token.ObjectValue{bound_method}, nil
+		return method.BindProperty(interp, i), nil
 	}
 
 	return &token.NilValue{},
@@ -33,8 +35,25 @@ func (i *LoxInstance) Get(name token.T) (token.Value, error) {
 			fmt.Sprintf("Undefined property `%s`.", name.Lexeme())}
 }
 
-func (i *LoxInstance) Set(name token.T, val token.Value) {
+// Set calls a setter if the class has one for the property; otherwise it
+// assigns a field, unless the property is a getter without a setter.
+func (i *LoxInstance) Set(interp T, name token.T, val token.Value) error {
+	if setter, ok := i.class.FindSetter(name.Lexeme()); ok {
+		setter.Bind(i).Call(interp, []token.Value{val})
+		return nil
+	}
+	if getter, ok := i.class.FindMethod(name.Lexeme()); ok &&
+		getter.Declaration.Kind == ast.GetterFunction {
+		return readOnlyError(name)
+	}
 	i.fields[name.Lexeme()] = val
+	return nil
+}
+
+func readOnlyError(name token.T) error {
+	return &RuntimeError{name, fmt.Sprintf(
+		"Cannot assign to property `%s`, which has a getter but no setter.",
+		name.Lexeme())}
 }
 
 func (i *LoxInstance) EqualsObject(o token.Object) bool {
@@ -44,10 +63,17 @@ func (i *LoxInstance) EqualsObject(o token.Object) bool {
 	return false
 }
 
+func (i *LoxInstance) TypeName() string { return "instance" }
+
 func (i *LoxInstance) String() string {
+	keys := []string{}
+	for key := range i.fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
 	values := []string{}
-	for key, val := range i.fields {
-		values = append(values, fmt.Sprintf("%s: %s", key, val.Show()))
+	for _, key := range keys {
+		values = append(values, fmt.Sprintf("%s: %s", key, i.fields[key].Show()))
 	}
 	return i.class.Name.Lexeme() + "{" + strings.Join(values, ", ") + "}"
 }
@@ -1,6 +1,7 @@
 package interpret
 
 import (
+	"bufio"
 	"fmt"
 
 	"github.com/perlmonger42/go-lox/ast"
@@ -13,25 +14,30 @@ type Value = token.Value
 type T interface {
 	InterpretStmts(stmts []ast.Stmt)
 	InterpretExpr(expr ast.Expr) Value
+	Stringify(tok token.T, v Value) string
 
 	//InterpretStmts2(stmts []ast.Stmt) error
 	//InterpretExprMaybe(expr ast.Expr) (Value, error)
 
 	GetGlobalEnvironment() Environment
 	GetCurrentEnvironment() Environment
+	GlobalNames() []string
 	GetAt(distance int, name string) (token.Value, error)
 
 	GetSuper(super *ast.Super) (distance int, superclass *LoxClass)
-	GetThisAt(distance int) *LoxInstance
+	GetThisAt(distance int) token.Object
 
 	Error(tok token.T, message string) RuntimeError
 
 	Resolve(expr ast.Expr, name token.T, depth int)
 
+	SetHook(hook Hook)
+
 	executeBlock(statements []ast.Stmt, newEnv Environment)
 
 	printIndent()
 	getLox() *lox.T
+	getHook() Hook
 	indent() string
 }
 
@@ -47,6 +53,18 @@ func New(lox *lox.T) T {
 	str := token.New(token.Identifier, "str", nil, token.NewPos(0))
 	i.globals.Define(str, token.ObjectValue{&StrNative{}})
 
+	i.defineNative("list()", 0, func(_ T, arguments []token.Value) token.Value {
+		return token.ObjectValue{NewLoxList([]token.Value{})}
+	})
+	i.defineNative("map()", 0, func(_ T, arguments []token.Value) token.Value {
+		return token.ObjectValue{NewLoxMap()}
+	})
+
+	i.defineIONatives()
+	i.defineJSONNatives()
+	i.defineIntrospectionNatives()
+	i.defineAssertNatives()
+
 	// i.globals.Dump("Interpreter Environment")
 	return i
 }
@@ -57,6 +75,9 @@ type Interpreter struct {
 	globals     Environment
 	environment Environment
 	locals      map[ast.Expr]int
+	stdin       *bufio.Reader // created on first call to `readLine()`
+	hook        Hook          // see SetHook
+	branchHook  BranchHook    // hook, if it is a BranchHook
 }
 
 var _ T = &Interpreter{}
@@ -70,6 +91,12 @@ func (rte *RuntimeError) Error() string { return rte.Message }
 
 func (i *Interpreter) GetGlobalEnvironment() Environment  { return i.globals }
 func (i *Interpreter) GetCurrentEnvironment() Environment { return i.environment }
+
+// GlobalNames returns the sorted names of the global variables, which
+// before a program runs are just the natives.
+func (i *Interpreter) GlobalNames() []string {
+	return i.globals.Names()
+}
 func (i *Interpreter) GetAt(distance int, name string) (Value, error) {
 	return i.environment.GetAt(distance, name)
 }
@@ -80,6 +107,18 @@ func (i *Interpreter) Error(tok token.T, message string) RuntimeError {
 	return RuntimeError{tok, message}
 }
 
+// defineNative binds a NativeFunction in the global environment under the
+// name given by the signature.
+func (i *Interpreter) defineNative(
+	signature string,
+	arity int,
+	fn func(i T, arguments []token.Value) token.Value,
+) {
+	native := NewNativeFunction(signature, arity, fn)
+	name := token.New(token.Identifier, native.Name(), nil, token.NewPos(0))
+	i.globals.Define(name, token.ObjectValue{native})
+}
+
 func (i *Interpreter) Define(name token.T, value Value) {
 	if i.getLox().Config.TraceEval {
 		fmt.Printf("%sdefine %s <-- %s\n", i.indent(), name.Lexeme(), value)
@@ -103,6 +142,10 @@ func (i *Interpreter) GetSuper(
 		panic(i.Error(super.Keyword, fmt.Sprintf(
 			"[internal error] `super` value could not be found: %s",
 			err)))
+	} else if _, ok := value.(token.NilValue); ok {
+		// A trait method included by a class that has no superclass.
+		panic(i.Error(super.Keyword,
+			"Can't use 'super' in a trait method of a class with no superclass."))
 	} else if object, ok := value.(token.ObjectValue); !ok {
 		panic(i.Error(super.Keyword,
 			"[internal error] `super` value is not an ObjectValue."))
@@ -114,18 +157,22 @@ func (i *Interpreter) GetSuper(
 	}
 }
 
-func (i *Interpreter) GetThisAt(distance int) *LoxInstance {
+// GetThisAt returns the value of `this`: a LoxInstance in a method, or a
+// LoxClass in a static method.
+func (i *Interpreter) GetThisAt(distance int) token.Object {
 	if value, err := i.GetAt(distance, "this"); err != nil {
 		panic(fmt.Errorf(
 			"[internal error] `this` value could not be found: %s", err))
 	} else if object, ok := value.(token.ObjectValue); !ok {
 		panic(fmt.Errorf(
 			"[internal error] `this` value is not an ObjectValue."))
-	} else if this, ok := object.V.(*LoxInstance); !ok {
-		panic(fmt.Errorf(
-			"[internal error] `this` value is not a LoxInstance."))
 	} else {
-		return this
+		switch this := object.V.(type) {
+		case *LoxInstance, *LoxClass:
+			return this
+		}
+		panic(fmt.Errorf(
+			"[internal error] `this` value is not a LoxInstance or LoxClass."))
 	}
 }
 
@@ -192,14 +239,3 @@ func (i *Interpreter) lookUpVariable(name token.T, expr ast.Expr) Value {
 		return v
 	}
 }
-
-func isTruthy(val Value) bool {
-	switch v := val.(type) {
-	case token.NilValue:
-		return false
-	case token.BooleanValue:
-		return v.V
-	default:
-		return true
-	}
-}
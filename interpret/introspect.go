@@ -0,0 +1,172 @@
+package interpret
+
+import (
+	"sort"
+
+	"github.com/perlmonger42/go-lox/token"
+)
+
+// defineIntrospectionNatives installs the natives that let a script examine
+// values at run time:
+//
+//	type(x)                 "number", "string", "instance", "class", ...
+//	instanceof(x, Class)    whether x is an instance of Class or a subclass
+//	classOf(x)              the class of instance x, or nil
+//	fields(x)               sorted field names of an instance or class
+//	methods(x)              sorted method names of a class (or an instance's)
+//	hasField(x, name)       whether an instance or class has a field
+//	getField(x, name)       x.name, with the name given as a string
+//	setField(x, name, v)    x.name = v, with the name given as a string
+func (i *Interpreter) defineIntrospectionNatives() {
+	i.defineNative("type(x)", 1,
+		func(_ T, arguments []token.Value) token.Value {
+			return token.StringValue{arguments[0].TypeName()}
+		})
+
+	i.defineNative("instanceof(x, class)", 2,
+		func(_ T, arguments []token.Value) token.Value {
+			class := classArg("instanceof", arguments, 1)
+			if instance, ok := asInstance(arguments[0]); ok {
+				for c := instance.class; c != nil; c = c.Superclass {
+					if c == class {
+						return token.BooleanValue{true}
+					}
+				}
+			}
+			return token.BooleanValue{false}
+		})
+
+	i.defineNative("classOf(x)", 1,
+		func(_ T, arguments []token.Value) token.Value {
+			if instance, ok := asInstance(arguments[0]); ok {
+				return token.ObjectValue{instance.class}
+			}
+			return token.NilValue{}
+		})
+
+	i.defineNative("fields(x)", 1,
+		func(_ T, arguments []token.Value) token.Value {
+			names := map[string]bool{}
+			switch x := objectArg("fields", arguments, 0).(type) {
+			case *LoxInstance:
+				for name := range x.fields {
+					names[name] = true
+				}
+			case *LoxClass:
+				for c := x; c != nil; c = c.Superclass {
+					for name := range c.Fields {
+						names[name] = true
+					}
+				}
+			default:
+				panic(nativeError(
+					"fields: argument 1 must be an instance or class (got %s).",
+					arguments[0].TypeName()))
+			}
+			return token.ObjectValue{NewStringList(sortedNames(names))}
+		})
+
+	i.defineNative("methods(x)", 1,
+		func(_ T, arguments []token.Value) token.Value {
+			var class *LoxClass
+			switch x := objectArg("methods", arguments, 0).(type) {
+			case *LoxInstance:
+				class = x.class
+			case *LoxClass:
+				class = x
+			default:
+				panic(nativeError(
+					"methods: argument 1 must be a class or instance (got %s).",
+					arguments[0].TypeName()))
+			}
+			names := map[string]bool{}
+			for c := class; c != nil; c = c.Superclass {
+				for name := range c.Methods {
+					names[name] = true
+				}
+				for name := range c.Setters {
+					names[name] = true
+				}
+			}
+			return token.ObjectValue{NewStringList(sortedNames(names))}
+		})
+
+	i.defineNative("hasField(x, name)", 2,
+		func(_ T, arguments []token.Value) token.Value {
+			name := stringArg("hasField", arguments, 1)
+			var ok bool
+			switch x := objectArg("hasField", arguments, 0).(type) {
+			case *LoxInstance:
+				_, ok = x.fields[name]
+			case *LoxClass:
+				_, ok = x.FindField(name)
+			}
+			return token.BooleanValue{ok}
+		})
+
+	i.defineNative("getField(x, name)", 2,
+		func(i T, arguments []token.Value) token.Value {
+			name := propertyName(stringArg("getField", arguments, 1))
+			holder, ok := objectArg("getField", arguments, 0).(PropertyHolder)
+			if !ok {
+				panic(nativeError("getField: %s has no properties.",
+					arguments[0].TypeName()))
+			}
+			value, err := holder.Get(i, name)
+			if err != nil {
+				panic(nativeError("getField: %s", err))
+			}
+			return value
+		})
+
+	i.defineNative("setField(x, name, value)", 3,
+		func(i T, arguments []token.Value) token.Value {
+			name := propertyName(stringArg("setField", arguments, 1))
+			holder, ok := objectArg("setField", arguments, 0).(FieldHolder)
+			if !ok {
+				panic(nativeError("setField: %s has no fields.",
+					arguments[0].TypeName()))
+			}
+			if err := holder.Set(i, name, arguments[2]); err != nil {
+				panic(nativeError("setField: %s", err))
+			}
+			return arguments[2]
+		})
+}
+
+// objectArg returns the object held by arguments[n], or panics with a
+// NativeError naming the function and the argument.
+func objectArg(fname string, arguments []token.Value, n int) token.Object {
+	if obj, ok := arguments[n].(token.ObjectValue); ok {
+		return obj.V
+	}
+	panic(nativeError("%s: argument %d must be an object (got %s).",
+		fname, n+1, arguments[n].TypeName()))
+}
+
+// classArg returns arguments[n] as a class, or panics with a NativeError
+// naming the function and the argument.
+func classArg(fname string, arguments []token.Value, n int) *LoxClass {
+	if obj, ok := arguments[n].(token.ObjectValue); ok {
+		if class, ok := obj.V.(*LoxClass); ok {
+			return class
+		}
+	}
+	panic(nativeError("%s: argument %d must be a class (got %s).",
+		fname, n+1, arguments[n].TypeName()))
+}
+
+// propertyName makes a token for a property named at run time, for use with
+// PropertyHolder.Get and FieldHolder.Set.
+func propertyName(name string) token.T {
+	return token.New(token.Identifier, name, nil, token.NewPos(0))
+}
+
+func sortedNames(set map[string]bool) []string {
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
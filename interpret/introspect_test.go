@@ -0,0 +1,77 @@
+package interpret
+
+func ExampleType() {
+	exec(`
+class A { m() {} }
+fun f() {}
+print type(1) + " " + type("s") + " " + type(nil) + " " + type(true);
+print type(A()) + " " + type(A) + " " + type(A().m) + " " + type(f);
+print type(clock) + " " + type(list()) + " " + type(map()) + " " + type(json);
+	`)
+	// Output:
+	// number string nil boolean
+	// instance class function function
+	// native list map module
+}
+
+func ExampleInstanceof() {
+	exec(`
+class A {}
+class B < A {}
+var b = B();
+print instanceof(b, A);
+print instanceof(b, B);
+print instanceof(A(), B);
+print instanceof(1, A);
+print classOf(b);
+print classOf(b) == B;
+print classOf(3);
+instanceof(b, b);
+	`)
+	// Output:
+	// true
+	// true
+	// false
+	// false
+	// class B
+	// true
+	// nil
+	// [line 12] Error at ')': instanceof: argument 2 must be a class (got instance).
+	// runtime error: {RightParen: `)` instanceof: argument 2 must be a class (got instance).}
+}
+
+func ExampleFieldsAndMethods() {
+	exec(`
+class A {
+  init() { this.x = 1; }
+  m() {}
+  area { return 2; }
+  set size(v) { this.y = v; }
+  class count = 3;
+}
+class B < A { n() {} }
+var b = B();
+b.z = 2;
+print fields(b);
+print fields(B);
+print methods(B);
+print hasField(b, "x");
+print hasField(b, "m");
+print hasField(B, "count");
+print getField(b, "area");
+setField(b, "size", 9);
+print b.y;
+getField(b, "nope");
+	`)
+	// Output:
+	// ["x", "z"]
+	// ["count"]
+	// ["area", "init", "m", "n", "size"]
+	// true
+	// false
+	// true
+	// 2
+	// 9
+	// [line 21] Error at ')': getField: Undefined property `nope`.
+	// runtime error: {RightParen: `)` getField: Undefined property `nope`.}
+}
@@ -0,0 +1,108 @@
+package interpret
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/perlmonger42/go-lox/config"
+	"github.com/perlmonger42/go-lox/token"
+)
+
+// defineIONatives installs the natives that touch files, the environment
+// and the process. Each one checks the corresponding capability in
+// Config.Capabilities when called, so a sandboxed run sees them defined but
+// gets a runtime error on use.
+func (i *Interpreter) defineIONatives() {
+	i.defineNative("readLine()", 0,
+		func(_ T, arguments []token.Value) token.Value {
+			i.requireCapability("readLine", config.CapStdin)
+			line, err := i.stdinReader().ReadString('\n')
+			if err == io.EOF && line == "" {
+				return token.NilValue{}
+			} else if err != nil && err != io.EOF {
+				panic(nativeError("readLine: %s", err))
+			}
+			line = strings.TrimSuffix(line, "\n")
+			line = strings.TrimSuffix(line, "\r")
+			return token.StringValue{line}
+		})
+
+	i.defineNative("readFile(path)", 1,
+		func(_ T, arguments []token.Value) token.Value {
+			i.requireCapability("readFile", config.CapReadFile)
+			path := stringArg("readFile", arguments, 0)
+			content, err := os.ReadFile(path)
+			if err != nil {
+				panic(nativeError("readFile: %s", err))
+			}
+			return token.StringValue{string(content)}
+		})
+
+	i.defineNative("writeFile(path, text)", 2,
+		func(_ T, arguments []token.Value) token.Value {
+			i.requireCapability("writeFile", config.CapWriteFile)
+			path := stringArg("writeFile", arguments, 0)
+			text := stringArg("writeFile", arguments, 1)
+			if err := os.WriteFile(path, []byte(text), 0666); err != nil {
+				panic(nativeError("writeFile: %s", err))
+			}
+			return token.NilValue{}
+		})
+
+	i.defineNative("listDir(path)", 1,
+		func(_ T, arguments []token.Value) token.Value {
+			i.requireCapability("listDir", config.CapReadFile)
+			path := stringArg("listDir", arguments, 0)
+			entries, err := os.ReadDir(path)
+			if err != nil {
+				panic(nativeError("listDir: %s", err))
+			}
+			names := make([]string, 0, len(entries))
+			for _, entry := range entries {
+				names = append(names, entry.Name())
+			}
+			return token.ObjectValue{NewStringList(names)}
+		})
+
+	i.defineNative("getEnv(name)", 1,
+		func(_ T, arguments []token.Value) token.Value {
+			i.requireCapability("getEnv", config.CapEnv)
+			name := stringArg("getEnv", arguments, 0)
+			if value, ok := os.LookupEnv(name); ok {
+				return token.StringValue{value}
+			}
+			return token.NilValue{}
+		})
+
+	i.defineNative("args()", 0,
+		func(_ T, arguments []token.Value) token.Value {
+			i.requireCapability("args", config.CapArgs)
+			args := append([]string{}, i.lox.Config.Args...)
+			return token.ObjectValue{NewStringList(args)}
+		})
+}
+
+// requireCapability panics with a NativeError unless the configuration
+// grants every capability in want.
+func (i *Interpreter) requireCapability(fname string, want config.Capability) {
+	if !i.lox.Config.Capabilities.Has(want) {
+		panic(nativeError("%s: not permitted (requires capability %s).",
+			fname, want))
+	}
+}
+
+// stdinReader returns the buffered reader used by `readLine()`, creating it
+// on first use so that unread input is not consumed by interpreters that
+// never read.
+func (i *Interpreter) stdinReader() *bufio.Reader {
+	if i.stdin == nil {
+		var in io.Reader = i.lox.Config.Stdin
+		if in == nil {
+			in = os.Stdin
+		}
+		i.stdin = bufio.NewReader(in)
+	}
+	return i.stdin
+}
@@ -0,0 +1,88 @@
+package interpret
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/perlmonger42/go-lox/config"
+)
+
+func ioConfig(stdin string, args ...string) *config.T {
+	cfg := config.New()
+	cfg.Capabilities = config.AllCapabilities
+	cfg.Stdin = strings.NewReader(stdin)
+	cfg.Args = args
+	return cfg
+}
+
+func ExampleReadLine() {
+	execWithConfig(ioConfig("first\r\nsecond\nthird"), `
+var line = readLine();
+while (line != nil) {
+  print "[" + line + "]";
+  line = readLine();
+}
+	`)
+	// Output:
+	// [first]
+	// [second]
+	// [third]
+}
+
+func ExampleArgs() {
+	execWithConfig(ioConfig("", "-v", "input.txt"), `
+var a = args();
+print a;
+print a.length();
+print a.get(1);
+print a.get(2);
+	`)
+	// Output:
+	// ["-v", "input.txt"]
+	// 2
+	// input.txt
+	// [line 6] Error at ')': get: list index 2 out of range [0, 2).
+	// runtime error: {RightParen: `)` get: list index 2 out of range [0, 2).}
+}
+
+func ExampleFiles() {
+	dir, err := os.MkdirTemp("", "go-lox-io")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	os.Setenv("GO_LOX_TEST_DIR", dir)
+	defer os.Unsetenv("GO_LOX_TEST_DIR")
+	os.WriteFile(filepath.Join(dir, "b.txt"), []byte("bee"), 0666)
+
+	execWithConfig(ioConfig(""), `
+var dir = getEnv("GO_LOX_TEST_DIR");
+writeFile(dir + "/a.txt", "one\ntwo\n");
+print readFile(dir + "/a.txt");
+print listDir(dir);
+print getEnv("GO_LOX_TEST_UNSET_VARIABLE");
+writeFile(dir, 42);
+	`)
+	// Output:
+	// one
+	// two
+	//
+	// ["a.txt", "b.txt"]
+	// nil
+	// [line 7] Error at ')': writeFile: argument 2 must be a string (got number).
+	// runtime error: {RightParen: `)` writeFile: argument 2 must be a string (got number).}
+}
+
+func ExampleSandboxed() {
+	cfg := ioConfig("")
+	cfg.Capabilities = config.AllCapabilities &^ config.CapReadFile
+	execWithConfig(cfg, `
+print args();
+print readFile("/etc/passwd");
+	`)
+	// Output:
+	// []
+	// [line 3] Error at ')': readFile: not permitted (requires capability read-file).
+	// runtime error: {RightParen: `)` readFile: not permitted (requires capability read-file).}
+}
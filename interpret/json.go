@@ -0,0 +1,418 @@
+package interpret
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/perlmonger42/go-lox/token"
+)
+
+// defineJSONNatives installs the `json` module, whose members convert
+// between JSON text and Lox values:
+//
+//	JSON object  <-> LoxMap (keys keep their order)
+//	JSON array   <-> LoxList
+//	JSON string  <-> StringValue
+//	JSON number  <-> NumberValue
+//	true/false   <-> BooleanValue
+//	null         <-> NilValue
+//
+// Instances are stringified as objects holding their fields.
+func (i *Interpreter) defineJSONNatives() {
+	parse := NewNativeFunction("json.parse(text)", 1,
+		func(_ T, arguments []token.Value) token.Value {
+			return jsonParse(stringArg("json.parse", arguments, 0))
+		})
+	stringify := NewNativeFunction("json.stringify(value, indent)", 2,
+		func(_ T, arguments []token.Value) token.Value {
+			indent := jsonIndent(arguments[1])
+			s := &jsonStringifier{indent: indent, active: map[token.Object]bool{}}
+			s.value(arguments[0], 0)
+			return token.StringValue{s.buf.String()}
+		})
+
+	name := token.New(token.Identifier, "json", nil, token.NewPos(0))
+	i.globals.Define(name, token.ObjectValue{NewNativeModule("json", parse, stringify)})
+}
+
+// ===== json.parse =====
+
+type jsonParser struct {
+	text string
+	pos  int // byte offset of the next unread character
+}
+
+// jsonSyntaxError is raised (via panic) inside jsonParser and converted to a
+// NativeError by jsonParse.
+type jsonSyntaxError struct {
+	offset  int
+	message string
+}
+
+func jsonParse(text string) (result token.Value) {
+	p := &jsonParser{text: text}
+	defer func() {
+		if r := recover(); r != nil {
+			if serr, ok := r.(jsonSyntaxError); ok {
+				line, column := p.lineColumn(serr.offset)
+				panic(nativeError("json.parse: %s at line %d, column %d.",
+					serr.message, line, column))
+			}
+			panic(r)
+		}
+	}()
+
+	result = p.value()
+	p.skipSpace()
+	if p.pos < len(p.text) {
+		p.fail("unexpected %s after JSON value", p.describeNext())
+	}
+	return result
+}
+
+// lineColumn converts a byte offset in the text into 1-based line and
+// column (counted in characters) numbers.
+func (p *jsonParser) lineColumn(offset int) (line, column int) {
+	before := p.text[:offset]
+	line = strings.Count(before, "\n") + 1
+	lineStart := strings.LastIndexByte(before, '\n') + 1
+	column = utf8.RuneCountInString(before[lineStart:]) + 1
+	return line, column
+}
+
+func (p *jsonParser) fail(format string, args ...interface{}) {
+	panic(jsonSyntaxError{p.pos, fmt.Sprintf(format, args...)})
+}
+
+func (p *jsonParser) describeNext() string {
+	if p.pos >= len(p.text) {
+		return "end of input"
+	}
+	r, _ := utf8.DecodeRuneInString(p.text[p.pos:])
+	return fmt.Sprintf("character %q", r)
+}
+
+func (p *jsonParser) skipSpace() {
+	for p.pos < len(p.text) {
+		switch p.text[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+// expect consumes the literal word (e.g., "true") or fails.
+func (p *jsonParser) expect(word string) {
+	if !strings.HasPrefix(p.text[p.pos:], word) {
+		p.fail("invalid literal (expected %q)", word)
+	}
+	p.pos += len(word)
+}
+
+func (p *jsonParser) value() token.Value {
+	p.skipSpace()
+	if p.pos >= len(p.text) {
+		p.fail("unexpected end of input (expected a JSON value)")
+	}
+	switch c := p.text[p.pos]; {
+	case c == '{':
+		return p.object()
+	case c == '[':
+		return p.array()
+	case c == '"':
+		return token.StringValue{p.str()}
+	case c == 't':
+		p.expect("true")
+		return token.BooleanValue{true}
+	case c == 'f':
+		p.expect("false")
+		return token.BooleanValue{false}
+	case c == 'n':
+		p.expect("null")
+		return token.NilValue{}
+	case c == '-' || (c >= '0' && c <= '9'):
+		return p.number()
+	}
+	p.fail("unexpected %s (expected a JSON value)", p.describeNext())
+	return nil // unreachable
+}
+
+func (p *jsonParser) object() token.Value {
+	m := NewLoxMap()
+	p.pos++ // consume '{'
+	p.skipSpace()
+	if p.pos < len(p.text) && p.text[p.pos] == '}' {
+		p.pos++
+		return token.ObjectValue{m}
+	}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.text) || p.text[p.pos] != '"' {
+			p.fail("unexpected %s (expected a string key)", p.describeNext())
+		}
+		key := p.str()
+		p.skipSpace()
+		if p.pos >= len(p.text) || p.text[p.pos] != ':' {
+			p.fail("unexpected %s (expected ':' after object key)",
+				p.describeNext())
+		}
+		p.pos++
+		m.Store(key, p.value())
+		p.skipSpace()
+		if p.pos < len(p.text) && p.text[p.pos] == ',' {
+			p.pos++
+			continue
+		}
+		if p.pos < len(p.text) && p.text[p.pos] == '}' {
+			p.pos++
+			return token.ObjectValue{m}
+		}
+		p.fail("unexpected %s (expected ',' or '}' in object)", p.describeNext())
+	}
+}
+
+func (p *jsonParser) array() token.Value {
+	elements := []token.Value{}
+	p.pos++ // consume '['
+	p.skipSpace()
+	if p.pos < len(p.text) && p.text[p.pos] == ']' {
+		p.pos++
+		return token.ObjectValue{NewLoxList(elements)}
+	}
+	for {
+		elements = append(elements, p.value())
+		p.skipSpace()
+		if p.pos < len(p.text) && p.text[p.pos] == ',' {
+			p.pos++
+			continue
+		}
+		if p.pos < len(p.text) && p.text[p.pos] == ']' {
+			p.pos++
+			return token.ObjectValue{NewLoxList(elements)}
+		}
+		p.fail("unexpected %s (expected ',' or ']' in array)", p.describeNext())
+	}
+}
+
+// str scans a quoted string, leaving p.pos just past the closing quote.
+// Escape sequences are decoded by encoding/json.
+func (p *jsonParser) str() string {
+	start := p.pos
+	p.pos++ // consume opening '"'
+	for p.pos < len(p.text) {
+		switch c := p.text[p.pos]; {
+		case c == '"':
+			p.pos++
+			var s string
+			if err := json.Unmarshal([]byte(p.text[start:p.pos]), &s); err != nil {
+				p.pos = start
+				p.fail("invalid string literal (%s)",
+					strings.TrimPrefix(err.Error(), "json: "))
+			}
+			return s
+		case c == '\\':
+			p.pos += 2
+		case c < ' ':
+			p.fail("invalid control character in string")
+		default:
+			p.pos++
+		}
+	}
+	p.pos = start
+	p.fail("unterminated string")
+	return "" // unreachable
+}
+
+func (p *jsonParser) number() token.Value {
+	start := p.pos
+	digits := func() int {
+		n := 0
+		for p.pos < len(p.text) && p.text[p.pos] >= '0' && p.text[p.pos] <= '9' {
+			p.pos++
+			n++
+		}
+		return n
+	}
+	if p.text[p.pos] == '-' {
+		p.pos++
+	}
+	intStart := p.pos
+	if digits() == 0 {
+		p.fail("invalid number (expected a digit)")
+	} else if p.text[intStart] == '0' && p.pos-intStart > 1 {
+		p.pos = intStart
+		p.fail("invalid number (leading zero)")
+	}
+	if p.pos < len(p.text) && p.text[p.pos] == '.' {
+		p.pos++
+		if digits() == 0 {
+			p.fail("invalid number (expected a digit after '.')")
+		}
+	}
+	if p.pos < len(p.text) && (p.text[p.pos] == 'e' || p.text[p.pos] == 'E') {
+		p.pos++
+		if p.pos < len(p.text) && (p.text[p.pos] == '+' || p.text[p.pos] == '-') {
+			p.pos++
+		}
+		if digits() == 0 {
+			p.fail("invalid number (expected a digit in exponent)")
+		}
+	}
+	f, err := strconv.ParseFloat(p.text[start:p.pos], 64)
+	if err != nil {
+		p.pos = start
+		p.fail("invalid number (%s)", err)
+	}
+	return token.NumberValue{f}
+}
+
+// ===== json.stringify =====
+
+// jsonIndent interprets stringify's indent argument: nil, 0 and "" select
+// compact output; a number selects that many spaces; a string is used as is.
+func jsonIndent(v token.Value) string {
+	switch indent := v.(type) {
+	case token.NilValue, *token.NilValue:
+		return ""
+	case token.NumberValue:
+		n := int(indent.V)
+		if float64(n) != indent.V || n < 0 || n > 10 {
+			panic(nativeError(
+				"json.stringify: indent must be an integer from 0 to 10 (got %s).",
+				indent.Show()))
+		}
+		return strings.Repeat(" ", n)
+	case token.StringValue:
+		return indent.V
+	}
+	panic(nativeError(
+		"json.stringify: indent must be nil, a number or a string (got %s).",
+		v.TypeName()))
+}
+
+type jsonStringifier struct {
+	buf    bytes.Buffer
+	indent string
+	active map[token.Object]bool // containers being written, to catch cycles
+}
+
+func (s *jsonStringifier) newline(level int) {
+	if s.indent != "" {
+		s.buf.WriteByte('\n')
+		s.buf.WriteString(strings.Repeat(s.indent, level))
+	}
+}
+
+func (s *jsonStringifier) colon() {
+	if s.indent != "" {
+		s.buf.WriteString(": ")
+	} else {
+		s.buf.WriteString(":")
+	}
+}
+
+func (s *jsonStringifier) str(str string) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.Encode(str)
+	s.buf.Write(bytes.TrimSuffix(b.Bytes(), []byte{'\n'}))
+}
+
+func (s *jsonStringifier) value(v token.Value, level int) {
+	switch v := v.(type) {
+	case token.NilValue, *token.NilValue:
+		s.buf.WriteString("null")
+	case token.BooleanValue:
+		s.buf.WriteString(v.String())
+	case token.NumberValue:
+		if math.IsNaN(v.V) || math.IsInf(v.V, 0) {
+			panic(nativeError("json.stringify: cannot represent %s in JSON.",
+				v.Show()))
+		}
+		b, _ := json.Marshal(v.V)
+		s.buf.Write(b)
+	case token.StringValue:
+		s.str(v.V)
+	case token.ObjectValue:
+		s.object(v.V, level)
+	default:
+		panic(nativeError("json.stringify: cannot represent %s in JSON.",
+			v.TypeName()))
+	}
+}
+
+func (s *jsonStringifier) object(obj token.Object, level int) {
+	var keys []string
+	var lookup func(key string) token.Value
+	switch o := obj.(type) {
+	case *LoxList:
+		s.enter(obj)
+		defer delete(s.active, obj)
+		s.list(o, level)
+		return
+	case *LoxMap:
+		keys = o.Keys()
+		lookup = func(key string) token.Value { v, _ := o.Lookup(key); return v }
+	case *LoxInstance:
+		for key := range o.fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		lookup = func(key string) token.Value { return o.fields[key] }
+	default:
+		panic(nativeError("json.stringify: cannot represent %s in JSON.",
+			obj.Show()))
+	}
+
+	s.enter(obj)
+	defer delete(s.active, obj)
+	if len(keys) == 0 {
+		s.buf.WriteString("{}")
+		return
+	}
+	s.buf.WriteByte('{')
+	for n, key := range keys {
+		if n > 0 {
+			s.buf.WriteByte(',')
+		}
+		s.newline(level + 1)
+		s.str(key)
+		s.colon()
+		s.value(lookup(key), level+1)
+	}
+	s.newline(level)
+	s.buf.WriteByte('}')
+}
+
+func (s *jsonStringifier) list(l *LoxList, level int) {
+	if len(l.Elements) == 0 {
+		s.buf.WriteString("[]")
+		return
+	}
+	s.buf.WriteByte('[')
+	for n, element := range l.Elements {
+		if n > 0 {
+			s.buf.WriteByte(',')
+		}
+		s.newline(level + 1)
+		s.value(element, level+1)
+	}
+	s.newline(level)
+	s.buf.WriteByte(']')
+}
+
+func (s *jsonStringifier) enter(obj token.Object) {
+	if s.active[obj] {
+		panic(nativeError("json.stringify: cannot represent a cyclic structure."))
+	}
+	s.active[obj] = true
+}
@@ -0,0 +1,82 @@
+package interpret
+
+func ExampleJSONParse() {
+	exec(`
+var v = json.parse("{\"name\": \"go-lox\", \"tags\": [\"lox\", 1, 2.5e3, true, false, null], \"empty\": {}}");
+print v;
+print v.keys();
+print v.get("tags").get(2) + 1;
+print json.parse("\"caf\\u00e9\"");
+	`)
+	// Output:
+	// {"name": "go-lox", "tags": ["lox", 1, 2500, true, false, nil], "empty": {}}
+	// ["name", "tags", "empty"]
+	// 2501
+	// café
+}
+
+func ExampleJSONParseErrors() {
+	exec(`json.parse("{\"a\": [1,\n  2,, 3]}");`)
+	exec(`json.parse("[1, 2] 3");`)
+	exec(`json.parse("{\"a\" 1}");`)
+	exec(`json.parse("01");`)
+	exec(`json.parse("\"unterminated");`)
+	// Output:
+	// [line 1] Error at ')': json.parse: unexpected character ',' (expected a JSON value) at line 2, column 5.
+	// runtime error: {RightParen: `)` json.parse: unexpected character ',' (expected a JSON value) at line 2, column 5.}
+	// [line 1] Error at ')': json.parse: unexpected character '3' after JSON value at line 1, column 8.
+	// runtime error: {RightParen: `)` json.parse: unexpected character '3' after JSON value at line 1, column 8.}
+	// [line 1] Error at ')': json.parse: unexpected character '1' (expected ':' after object key) at line 1, column 6.
+	// runtime error: {RightParen: `)` json.parse: unexpected character '1' (expected ':' after object key) at line 1, column 6.}
+	// [line 1] Error at ')': json.parse: invalid number (leading zero) at line 1, column 1.
+	// runtime error: {RightParen: `)` json.parse: invalid number (leading zero) at line 1, column 1.}
+	// [line 1] Error at ')': json.parse: unterminated string at line 1, column 1.
+	// runtime error: {RightParen: `)` json.parse: unterminated string at line 1, column 1.}
+}
+
+func ExampleJSONStringify() {
+	exec(`
+class Point { init(x, y) { this.y = y; this.x = x; } }
+var m = map();
+m.set("s", "quote\" <tag>");
+m.set("n", 0.5);
+var l = list();
+l.push(Point(1, 2));
+l.push(nil);
+l.push(list());
+m.set("l", l);
+print json.stringify(m, nil);
+print json.stringify(m, "\t");
+print json.stringify(1/0, 0);
+	`)
+	// Output:
+	// {"s":"quote\" <tag>","n":0.5,"l":[{"x":1,"y":2},null,[]]}
+	// {
+	// 	"s": "quote\" <tag>",
+	// 	"n": 0.5,
+	// 	"l": [
+	// 		{
+	// 			"x": 1,
+	// 			"y": 2
+	// 		},
+	// 		null,
+	// 		[]
+	// 	]
+	// }
+	// [line 13] Error at ')': json.stringify: cannot represent +Inf in JSON.
+	// runtime error: {RightParen: `)` json.stringify: cannot represent +Inf in JSON.}
+}
+
+func ExampleJSONRoundTrip() {
+	exec(`
+var text = "{\"a\":[1,{\"b\":null}],\"c\":\"d\"}";
+print json.stringify(json.parse(text), 0) == text;
+var m = map();
+m.set("self", m);
+json.stringify(m, nil);
+	`)
+	// Output:
+	// true
+	// [line 6] Error at ')': json.stringify: cannot represent a cyclic structure.
+	// runtime error: {RightParen: `)` json.stringify: cannot represent a cyclic structure.}
+}
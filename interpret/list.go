@@ -0,0 +1,142 @@
+package interpret
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/perlmonger42/go-lox/token"
+)
+
+// LoxList is an ordered, growable sequence of values. Its elements are
+// manipulated through methods: length(), get(i), set(i, v), push(v), pop().
+type LoxList struct {
+	Elements []token.Value
+}
+
+var _ token.Object = &LoxList{}
+var _ PropertyHolder = &LoxList{}
+var _ Indexable = &LoxList{}
+
+func NewLoxList(elements []token.Value) *LoxList {
+	return &LoxList{Elements: elements}
+}
+
+// NewStringList returns a LoxList holding a StringValue for each string.
+func NewStringList(strs []string) *LoxList {
+	elements := make([]token.Value, 0, len(strs))
+	for _, s := range strs {
+		elements = append(elements, token.StringValue{s})
+	}
+	return NewLoxList(elements)
+}
+
+func (l *LoxList) Get(_ T, name token.T) (token.Value, error) {
+	var method *NativeFunction
+	switch name.Lexeme() {
+	case "length":
+		method = NewNativeFunction("length()", 0,
+			func(i T, arguments []token.Value) token.Value {
+				return token.NumberValue{float64(len(l.Elements))}
+			})
+	case "get":
+		method = NewNativeFunction("get(index)", 1,
+			func(i T, arguments []token.Value) token.Value {
+				return l.Elements[l.index("get", arguments)]
+			})
+	case "set":
+		method = NewNativeFunction("set(index, value)", 2,
+			func(i T, arguments []token.Value) token.Value {
+				l.Elements[l.index("set", arguments)] = arguments[1]
+				return arguments[1]
+			})
+	case "push":
+		method = NewNativeFunction("push(value)", 1,
+			func(i T, arguments []token.Value) token.Value {
+				l.Elements = append(l.Elements, arguments[0])
+				return arguments[0]
+			})
+	case "pop":
+		method = NewNativeFunction("pop()", 0,
+			func(i T, arguments []token.Value) token.Value {
+				n := len(l.Elements)
+				if n == 0 {
+					panic(nativeError("pop: list is empty."))
+				}
+				last := l.Elements[n-1]
+				l.Elements = l.Elements[:n-1]
+				return last
+			})
+	default:
+		return &token.NilValue{},
+			&RuntimeError{name,
+				fmt.Sprintf("Undefined property `%s`.", name.Lexeme())}
+	}
+	return token.ObjectValue{method}, nil
+}
+
+// index validates arguments[0] as an index into l.
+func (l *LoxList) index(fname string, arguments []token.Value) int {
+	n, err := l.checkIndex(arguments[0])
+	if err != nil {
+		panic(nativeError("%s: %s", fname, err))
+	}
+	return n
+}
+
+// checkIndex returns v as an index into l, or an error explaining why it
+// isn't one.
+func (l *LoxList) checkIndex(v token.Value) (int, error) {
+	num, ok := v.(token.NumberValue)
+	if !ok {
+		return 0, fmt.Errorf("list index must be a number (got %s).",
+			v.TypeName())
+	}
+	n := int(num.V)
+	if float64(n) != num.V {
+		return 0, fmt.Errorf("list index must be an integer (got %s).",
+			num.Show())
+	}
+	if n < 0 || n >= len(l.Elements) {
+		return 0, fmt.Errorf("list index %d out of range [0, %d).",
+			n, len(l.Elements))
+	}
+	return n, nil
+}
+
+func (l *LoxList) GetIndex(index token.Value) (token.Value, error) {
+	n, err := l.checkIndex(index)
+	if err != nil {
+		return &token.NilValue{}, err
+	}
+	return l.Elements[n], nil
+}
+
+func (l *LoxList) SetIndex(index token.Value, value token.Value) error {
+	n, err := l.checkIndex(index)
+	if err != nil {
+		return err
+	}
+	l.Elements[n] = value
+	return nil
+}
+
+func (l *LoxList) EqualsObject(o token.Object) bool {
+	if it, ok := o.(*LoxList); ok {
+		return l == it
+	}
+	return false
+}
+
+func (l *LoxList) TypeName() string { return "list" }
+
+func (l *LoxList) String() string {
+	values := []string{}
+	for _, val := range l.Elements {
+		values = append(values, val.Show())
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
+
+func (l *LoxList) Show() string {
+	return l.String()
+}
@@ -0,0 +1,148 @@
+package interpret
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/perlmonger42/go-lox/token"
+)
+
+// LoxMap associates string keys with values, remembering the order in which
+// keys were first inserted. Its entries are manipulated through methods:
+// length(), get(key), set(key, value), has(key), remove(key), keys().
+type LoxMap struct {
+	keys   []string
+	values map[string]token.Value
+}
+
+var _ token.Object = &LoxMap{}
+var _ PropertyHolder = &LoxMap{}
+var _ Indexable = &LoxMap{}
+
+func NewLoxMap() *LoxMap {
+	return &LoxMap{values: make(map[string]token.Value)}
+}
+
+// Keys returns the map's keys in insertion order.
+func (m *LoxMap) Keys() []string {
+	return append([]string{}, m.keys...)
+}
+
+// Lookup returns the value associated with key, if there is one.
+func (m *LoxMap) Lookup(key string) (value token.Value, ok bool) {
+	value, ok = m.values[key]
+	return
+}
+
+// Store associates value with key, appending key to the key order if it is
+// new.
+func (m *LoxMap) Store(key string, value token.Value) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// Delete removes key from the map, reporting whether it was present.
+func (m *LoxMap) Delete(key string) bool {
+	if _, ok := m.values[key]; !ok {
+		return false
+	}
+	delete(m.values, key)
+	for n, k := range m.keys {
+		if k == key {
+			m.keys = append(m.keys[:n], m.keys[n+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+func (m *LoxMap) GetIndex(index token.Value) (token.Value, error) {
+	key, ok := index.(token.StringValue)
+	if !ok {
+		return &token.NilValue{},
+			fmt.Errorf("map key must be a string (got %s).", index.TypeName())
+	}
+	if value, ok := m.Lookup(key.V); ok {
+		return value, nil
+	}
+	return token.NilValue{}, nil
+}
+
+func (m *LoxMap) SetIndex(index token.Value, value token.Value) error {
+	key, ok := index.(token.StringValue)
+	if !ok {
+		return fmt.Errorf("map key must be a string (got %s).", index.TypeName())
+	}
+	m.Store(key.V, value)
+	return nil
+}
+
+func (m *LoxMap) Get(_ T, name token.T) (token.Value, error) {
+	var method *NativeFunction
+	switch name.Lexeme() {
+	case "length":
+		method = NewNativeFunction("length()", 0,
+			func(i T, arguments []token.Value) token.Value {
+				return token.NumberValue{float64(len(m.keys))}
+			})
+	case "get":
+		method = NewNativeFunction("get(key)", 1,
+			func(i T, arguments []token.Value) token.Value {
+				if value, ok := m.Lookup(stringArg("get", arguments, 0)); ok {
+					return value
+				}
+				return token.NilValue{}
+			})
+	case "set":
+		method = NewNativeFunction("set(key, value)", 2,
+			func(i T, arguments []token.Value) token.Value {
+				m.Store(stringArg("set", arguments, 0), arguments[1])
+				return arguments[1]
+			})
+	case "has":
+		method = NewNativeFunction("has(key)", 1,
+			func(i T, arguments []token.Value) token.Value {
+				_, ok := m.Lookup(stringArg("has", arguments, 0))
+				return token.BooleanValue{ok}
+			})
+	case "remove":
+		method = NewNativeFunction("remove(key)", 1,
+			func(i T, arguments []token.Value) token.Value {
+				return token.BooleanValue{m.Delete(stringArg("remove", arguments, 0))}
+			})
+	case "keys":
+		method = NewNativeFunction("keys()", 0,
+			func(i T, arguments []token.Value) token.Value {
+				return token.ObjectValue{NewStringList(m.Keys())}
+			})
+	default:
+		return &token.NilValue{},
+			&RuntimeError{name,
+				fmt.Sprintf("Undefined property `%s`.", name.Lexeme())}
+	}
+	return token.ObjectValue{method}, nil
+}
+
+func (m *LoxMap) EqualsObject(o token.Object) bool {
+	if it, ok := o.(*LoxMap); ok {
+		return m == it
+	}
+	return false
+}
+
+func (m *LoxMap) TypeName() string { return "map" }
+
+func (m *LoxMap) String() string {
+	values := []string{}
+	for _, key := range m.keys {
+		values = append(values,
+			fmt.Sprintf("%q: %s", key, m.values[key].Show()))
+	}
+	return "{" + strings.Join(values, ", ") + "}"
+}
+
+func (m *LoxMap) Show() string {
+	return m.String()
+}
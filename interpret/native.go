@@ -1,6 +1,8 @@
 package interpret
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/perlmonger42/go-lox/token"
@@ -12,8 +14,9 @@ type ClockNative struct {
 
 var _ token.Object = ClockNative{}
 
-func (f ClockNative) Show() string   { return f.String() }
-func (f ClockNative) String() string { return `[native function "clock()"]` }
+func (f ClockNative) Show() string     { return f.String() }
+func (f ClockNative) String() string   { return `[native function "clock()"]` }
+func (f ClockNative) TypeName() string { return "native" }
 
 var _ Callable = &ClockNative{}
 
@@ -41,18 +44,133 @@ type StrNative struct {
 
 var _ token.Object = &StrNative{}
 
-func (f StrNative) Show() string   { return f.String() }
-func (f StrNative) String() string { return `[native function "str(x)"]` }
+func (f StrNative) Show() string     { return f.String() }
+func (f StrNative) String() string   { return `[native function "str(x)"]` }
+func (f StrNative) TypeName() string { return "native" }
 
 var _ Callable = StrNative{}
 
 func (f StrNative) Arity() int { return 1 }
 
-// Return the number of seconds since 00:00:00 on 1 January 1970 (UTC).
+// Return the argument converted to a string, as `print` would show it.
 func (f StrNative) Call(i T, arguments []token.Value) token.Value {
-	return token.StringValue{arguments[0].String()}
+	return token.StringValue{i.Stringify(nil, arguments[0])}
 }
 
 func (f StrNative) EqualsObject(o token.Object) bool {
 	return false
 }
+
+// NativeFunction implements a builtin function whose behavior is supplied by
+// a Go function. Signature is used only for display, e.g. "readFile(path)".
+type NativeFunction struct {
+	Signature string
+	arity     int
+	fn        func(i T, arguments []token.Value) token.Value
+}
+
+var _ token.Object = &NativeFunction{}
+var _ Callable = &NativeFunction{}
+
+func NewNativeFunction(
+	signature string,
+	arity int,
+	fn func(i T, arguments []token.Value) token.Value,
+) *NativeFunction {
+	return &NativeFunction{Signature: signature, arity: arity, fn: fn}
+}
+
+func (f *NativeFunction) Show() string { return f.String() }
+func (f *NativeFunction) String() string {
+	return fmt.Sprintf("[native function %q]", f.Signature)
+}
+
+func (f *NativeFunction) Arity() int       { return f.arity }
+func (f *NativeFunction) TypeName() string { return "native" }
+
+func (f *NativeFunction) Call(i T, arguments []token.Value) token.Value {
+	return f.fn(i, arguments)
+}
+
+func (f *NativeFunction) EqualsObject(o token.Object) bool {
+	return f == o
+}
+
+// Name returns the function name from the signature (e.g., "readFile").
+func (f *NativeFunction) Name() string {
+	if paren := strings.IndexByte(f.Signature, '('); paren >= 0 {
+		return f.Signature[:paren]
+	}
+	return f.Signature
+}
+
+// A NativeError is raised (via panic) by a native function that cannot
+// complete. The interpreter converts it into a RuntimeError located at the
+// call site.
+type NativeError struct {
+	Message string
+}
+
+func (e NativeError) Error() string { return e.Message }
+
+func nativeError(format string, args ...interface{}) NativeError {
+	return NativeError{fmt.Sprintf(format, args...)}
+}
+
+// stringArg returns arguments[n] as a Go string, or panics with a NativeError
+// naming the function and the argument.
+func stringArg(fname string, arguments []token.Value, n int) string {
+	if s, ok := arguments[n].(token.StringValue); ok {
+		return s.V
+	}
+	panic(nativeError("%s: argument %d must be a string (got %s).",
+		fname, n+1, arguments[n].TypeName()))
+}
+
+// numberArg returns arguments[n] as a Go float64, or panics with a
+// NativeError naming the function and the argument.
+func numberArg(fname string, arguments []token.Value, n int) float64 {
+	if num, ok := arguments[n].(token.NumberValue); ok {
+		return num.V
+	}
+	panic(nativeError("%s: argument %d must be a number (got %s).",
+		fname, n+1, arguments[n].TypeName()))
+}
+
+// NativeModule is a namespace of natives, such as `json`, whose members are
+// read with `.`.
+type NativeModule struct {
+	Name    string
+	Members map[string]token.Value
+}
+
+var _ token.Object = &NativeModule{}
+var _ PropertyHolder = &NativeModule{}
+
+func NewNativeModule(name string, members ...*NativeFunction) *NativeModule {
+	m := &NativeModule{Name: name, Members: make(map[string]token.Value)}
+	for _, member := range members {
+		key := strings.TrimPrefix(member.Name(), name+".")
+		m.Members[key] = token.ObjectValue{member}
+	}
+	return m
+}
+
+func (m *NativeModule) Get(_ T, name token.T) (token.Value, error) {
+	if value, ok := m.Members[name.Lexeme()]; ok {
+		return value, nil
+	}
+	return &token.NilValue{},
+		&RuntimeError{name,
+			fmt.Sprintf("Undefined property `%s`.", name.Lexeme())}
+}
+
+func (m *NativeModule) Show() string     { return m.String() }
+func (m *NativeModule) TypeName() string { return "module" }
+func (m *NativeModule) String() string {
+	return fmt.Sprintf("[native module %q]", m.Name)
+}
+
+func (m *NativeModule) EqualsObject(o token.Object) bool {
+	return m == o
+}
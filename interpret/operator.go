@@ -0,0 +1,204 @@
+package interpret
+
+import (
+	"fmt"
+
+	"github.com/perlmonger42/go-lox/ast"
+	"github.com/perlmonger42/go-lox/token"
+)
+
+// Classes customize how their instances behave with built-in operations by
+// defining special methods. These are ordinary methods, found through
+// LoxClass.FindMethod, so they are inherited and may call `super`:
+//
+//	toString()             print, str()
+//	equals(other)          ==, !=
+//	add(other)             +
+//	subtract(other)        -
+//	multiply(other)        *
+//	divide(other)          /
+//	negate()               unary -
+//	less(other)            <  (and see below)
+//	greater(other)         >
+//	lessEqual(other)       <=
+//	greaterEqual(other)    >=
+//	index(key)             x[key]
+//	setIndex(key, value)   x[key] = value
+//
+// Arithmetic and ordering dispatch on the left operand. `==` also tries the
+// right operand, so `nil == instance` consults instance.equals(nil). When
+// the left operand doesn't define the method for an ordering, less() stands
+// in where it can without knowing equality: `a >= b` is !a.less(b) for a
+// left operand defining less(), and `a > b` and `a <= b` are b.less(a) and
+// !b.less(a) for a right operand defining it, so `3 > instance` is
+// instance.less(3). An instance defining only less() can't be the left
+// operand of `>` or `<=`.
+var binaryMethodNames = map[token.Type]string{
+	token.Plus:         "add",
+	token.Minus:        "subtract",
+	token.Star:         "multiply",
+	token.Slash:        "divide",
+	token.Less:         "less",
+	token.Greater:      "greater",
+	token.LessEqual:    "lessEqual",
+	token.GreaterEqual: "greaterEqual",
+	token.EqualEqual:   "equals",
+	token.BangEqual:    "equals",
+}
+
+// asInstance returns the LoxInstance held by v, if any.
+func asInstance(v Value) (*LoxInstance, bool) {
+	if obj, ok := v.(token.ObjectValue); ok {
+		instance, ok := obj.V.(*LoxInstance)
+		return instance, ok
+	}
+	return nil, false
+}
+
+// findSpecialMethod returns the method named name that v's class defines or
+// inherits, if v is an instance. Getters are not special methods.
+func findSpecialMethod(v Value, name string) (*LoxInstance, *LoxFunction, bool) {
+	if instance, ok := asInstance(v); ok {
+		if method, ok := instance.class.FindMethod(name); ok &&
+			method.Declaration.Kind == ast.OrdinaryFunction {
+			return instance, method, true
+		}
+	}
+	return nil, nil, false
+}
+
+// callSpecialMethod binds method to instance and calls it, reporting an
+// arity mismatch at tok.
+func (i *Interpreter) callSpecialMethod(
+	tok token.T,
+	instance *LoxInstance,
+	method *LoxFunction,
+	arguments ...Value,
+) Value {
+	if method.Arity() != len(arguments) {
+		panic(i.Error(tok, arityMessage(instance, method, len(arguments))))
+	}
+	return method.Bind(instance).Call(i, arguments)
+}
+
+// arityMessage describes a special method that doesn't take n arguments.
+func arityMessage(instance *LoxInstance, method *LoxFunction, n int) string {
+	return fmt.Sprintf(
+		"Special method '%s' of class %s must take %d parameter(s), not %d.",
+		method.Declaration.Name.Lexeme(), instance.class.Name.Lexeme(),
+		n, method.Arity())
+}
+
+// binaryOverload applies a special method for a binary operator if one of
+// the operands is an instance that defines it.
+func (i *Interpreter) binaryOverload(
+	expr *ast.Binary, left, right Value,
+) (Value, bool) {
+	op := expr.Operator
+	name, ok := binaryMethodNames[op.Type()]
+	if !ok {
+		return nil, false
+	}
+
+	if instance, method, ok := findSpecialMethod(left, name); ok {
+		result := i.callSpecialMethod(op, instance, method, right)
+		if op.Type() == token.BangEqual {
+			result = token.BooleanValue{!token.IsTruthy(result)}
+		}
+		return result, true
+	}
+
+	switch op.Type() {
+	case token.EqualEqual, token.BangEqual:
+		if instance, method, ok := findSpecialMethod(right, name); ok {
+			result := token.IsTruthy(i.callSpecialMethod(op, instance, method, left))
+			return token.BooleanValue{result == (op.Type() == token.EqualEqual)}, true
+		}
+	case token.Greater:
+		// a > b  is  b < a
+		if instance, method, ok := findSpecialMethod(right, "less"); ok {
+			return i.callSpecialMethod(op, instance, method, left), true
+		}
+	case token.LessEqual:
+		// a <= b  is  !(b < a)
+		if instance, method, ok := findSpecialMethod(right, "less"); ok {
+			less := i.callSpecialMethod(op, instance, method, left)
+			return token.BooleanValue{!token.IsTruthy(less)}, true
+		}
+	case token.GreaterEqual:
+		// a >= b  is  !(a < b)
+		if instance, method, ok := findSpecialMethod(left, "less"); ok {
+			less := i.callSpecialMethod(op, instance, method, right)
+			return token.BooleanValue{!token.IsTruthy(less)}, true
+		}
+	}
+	return nil, false
+}
+
+// Stringify converts a value to the text that `print` shows, calling the
+// toString() method of instances whose class defines one. A toString()
+// that can't be called, or doesn't return a string, is reported at tok,
+// the `print` or call doing the converting; if tok is nil, it is raised as
+// a NativeError, for a native's caller to report.
+func (i *Interpreter) Stringify(tok token.T, v Value) string {
+	instance, method, ok := findSpecialMethod(v, "toString")
+	if !ok {
+		return v.String()
+	}
+	var message string
+	if method.Arity() != 0 {
+		message = arityMessage(instance, method, 0)
+	} else {
+		result := method.Bind(instance).Call(i, nil)
+		if s, ok := result.(token.StringValue); ok {
+			return s.V
+		}
+		message = fmt.Sprintf("toString() of class %s must return a string, not %s.",
+			instance.class.Name.Lexeme(), result.TypeName())
+	}
+	if tok == nil {
+		panic(nativeError("%s", message))
+	}
+	panic(i.Error(tok, message))
+}
+
+func (i *Interpreter) Visit_IndexExpr_Token_Value(expr *ast.Index) Value {
+	var object Value = i.evaluate(expr.Object)
+	var index Value = i.evaluate(expr.Index)
+	if instance, method, ok := findSpecialMethod(object, "index"); ok {
+		return i.callSpecialMethod(expr.Bracket, instance, method, index)
+	}
+	if obj, ok := object.(token.ObjectValue); ok {
+		if indexable, ok := obj.V.(Indexable); ok {
+			value, err := indexable.GetIndex(index)
+			if err != nil {
+				panic(i.Error(expr.Bracket, err.Error()))
+			}
+			return value
+		}
+	}
+	panic(i.Error(expr.Bracket, fmt.Sprintf(
+		"Only lists, maps and instances with an index() method can be indexed (got %s).",
+		object.TypeName())))
+}
+
+func (i *Interpreter) Visit_SetIndexExpr_Token_Value(expr *ast.SetIndex) Value {
+	var object Value = i.evaluate(expr.Object)
+	var index Value = i.evaluate(expr.Index)
+	var value Value = i.evaluate(expr.Value)
+	if instance, method, ok := findSpecialMethod(object, "setIndex"); ok {
+		i.callSpecialMethod(expr.Bracket, instance, method, index, value)
+		return value
+	}
+	if obj, ok := object.(token.ObjectValue); ok {
+		if indexable, ok := obj.V.(Indexable); ok {
+			if err := indexable.SetIndex(index, value); err != nil {
+				panic(i.Error(expr.Bracket, err.Error()))
+			}
+			return value
+		}
+	}
+	panic(i.Error(expr.Bracket, fmt.Sprintf(
+		"Only lists, maps and instances with a setIndex() method can be assigned by index (got %s).",
+		object.TypeName())))
+}
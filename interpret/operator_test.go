@@ -0,0 +1,164 @@
+package interpret
+
+func ExampleToString() {
+	exec(`
+class Point {
+  init(x, y) { this.x = x; this.y = y; }
+  toString() { return "(" + str(this.x) + ", " + str(this.y) + ")"; }
+}
+class Point3 < Point {
+  init(x, y, z) { super.init(x, y); this.z = z; }
+  toString() { return super.toString() + "@" + str(this.z); }
+}
+class Broken { toString() { return 42; } }
+print Point(1, 2);
+print str(Point3(1, 2, 3)) + "!";
+print Broken();
+	`)
+	// Output:
+	// (1, 2)
+	// (1, 2)@3!
+	// [line 13] Error at 'print': toString() of class Broken must return a string, not number.
+	// runtime error: {Print: `print` toString() of class Broken must return a string, not number.}
+}
+
+func ExampleArithmeticOverloading() {
+	exec(`
+class Vec {
+  init(x, y) { this.x = x; this.y = y; }
+  add(o) { return Vec(this.x + o.x, this.y + o.y); }
+  subtract(o) { return Vec(this.x - o.x, this.y - o.y); }
+  multiply(k) { return Vec(this.x * k, this.y * k); }
+  divide(k) { return Vec(this.x / k, this.y / k); }
+  negate() { return Vec(-this.x, -this.y); }
+  toString() { return "<" + str(this.x) + " " + str(this.y) + ">"; }
+}
+var a = Vec(1, 2);
+var b = Vec(10, 20);
+print a + b;
+print b - a;
+print a * 3;
+print b / 4;
+print -a;
+print 3 * a;
+	`)
+	// Output:
+	// <11 22>
+	// <9 18>
+	// <3 6>
+	// <2.5 5>
+	// <-1 -2>
+	// [line 18] Error at '*': cannot apply Star: `*` to types number and instance (values 3 and Vec{x: 1, y: 2}) (token.NumberValue and token.ObjectValue)
+	// runtime error: {Star: `*` cannot apply Star: `*` to types number and instance (values 3 and Vec{x: 1, y: 2}) (token.NumberValue and token.ObjectValue)}
+}
+
+func ExampleComparisonOverloading() {
+	exec(`
+class Money {
+  init(cents) { this.cents = cents; }
+  less(o) { return this.cents < o.cents; }
+  equals(o) { return o != nil and this.cents == o.cents; }
+}
+var a = Money(100);
+var b = Money(250);
+print a < b;
+print a > b;
+print a <= b;
+print a >= b;
+print a == Money(100);
+print a != Money(100);
+print nil == a;
+print a == b;
+	`)
+	// Output:
+	// true
+	// false
+	// true
+	// false
+	// true
+	// false
+	// false
+	// false
+}
+
+func ExampleIndexing() {
+	exec(`
+var l = list();
+l.push("a");
+l.push("b");
+l[1] = "B";
+print l[0] + l[1];
+var m = map();
+m["k"] = l;
+print m["k"][1];
+print m["missing"];
+class Grid {
+  init() { this.cells = map(); }
+  index(k) { return this.cells[str(k)]; }
+  setIndex(k, v) { this.cells[str(k)] = v; }
+}
+class LabeledGrid < Grid {
+  index(k) { return "cell " + str(super.index(k)); }
+}
+var g = LabeledGrid();
+g[7] = "seven";
+print g[7];
+print l[2];
+	`)
+	// Output:
+	// aB
+	// B
+	// nil
+	// cell seven
+	// [line 22] Error at ']': list index 2 out of range [0, 2).
+	// runtime error: {RightBrack: `]` list index 2 out of range [0, 2).}
+}
+
+func ExampleBadSpecialMethod() {
+	exec(`
+class C { add() { return 1; } }
+print C() + 1;
+	`)
+	// Output:
+	// [line 3] Error at '+': Special method 'add' of class C must take 1 parameter(s), not 0.
+	// runtime error: {Plus: `+` Special method 'add' of class C must take 1 parameter(s), not 0.}
+}
+
+func ExampleComparisonOverloading_numberOnLeft() {
+	exec(`
+class Money {
+  init(cents) { this.cents = cents; }
+  less(o) { return this.cents < o; }
+}
+var a = Money(100);
+print 300 > a;
+print 50 > a;
+print 300 <= a;
+print 50 <= a;
+	`)
+	// Output:
+	// true
+	// false
+	// false
+	// true
+}
+
+func ExampleToString_callSite() {
+	exec(`
+class Broken { toString() { return 42; } }
+var b = Broken();
+print "ok";
+print str(
+  b);
+	`)
+	exec(`
+class Greedy { toString(x) { return "?"; } }
+print Greedy();
+	`)
+	// Output:
+	// ok
+	// [line 6] Error at ')': toString() of class Broken must return a string, not number.
+	// runtime error: {RightParen: `)` toString() of class Broken must return a string, not number.}
+	// [line 3] Error at 'print': Special method 'toString' of class Greedy must take 0 parameter(s), not 1.
+	// runtime error: {Print: `print` Special method 'toString' of class Greedy must take 0 parameter(s), not 1.}
+}
@@ -0,0 +1,28 @@
+package interpret
+
+import (
+	"github.com/perlmonger42/go-lox/token"
+)
+
+// A PropertyHolder is an object whose properties can be read with `.`.
+// Reading a property may run Lox code (a getter), hence the interpreter.
+type PropertyHolder interface {
+	Get(i T, name token.T) (token.Value, error)
+}
+
+// A FieldHolder is a PropertyHolder whose fields can also be assigned with
+// `.`. Assigning a property may run Lox code (a setter).
+type FieldHolder interface {
+	PropertyHolder
+	Set(i T, name token.T, val token.Value) error
+}
+
+var _ FieldHolder = &LoxInstance{}
+var _ FieldHolder = &LoxClass{}
+
+// An Indexable is an object whose elements can be read and written with
+// `[]`.
+type Indexable interface {
+	GetIndex(index token.Value) (token.Value, error)
+	SetIndex(index token.Value, value token.Value) error
+}
@@ -14,7 +14,7 @@ func (i *Interpreter) InterpretStmts(statements []ast.Stmt) {
 	defer func() {
 		if r := recover(); r != nil {
 			if exception, ok := r.(RuntimeError); ok {
-				fmt.Printf("runtime error: %s\n", exception)
+				fmt.Fprintf(i.lox.Config.Stdout, "runtime error: %s\n", exception)
 			} else {
 				panic(r)
 			}
@@ -28,6 +28,9 @@ func (i *Interpreter) InterpretStmts(statements []ast.Stmt) {
 }
 
 func (i *Interpreter) execute(stmt ast.Stmt) {
+	if i.hook != nil {
+		i.hook.Statement(i, stmt)
+	}
 	stmt.Accept_Stmt(i)
 }
 
@@ -50,7 +53,7 @@ func (i *Interpreter) Visit_ExpressionStmt(stmt *ast.Expression) {
 
 func (i *Interpreter) Visit_PrintStmt(stmt *ast.Print) {
 	var value Value = i.evaluate(stmt.Expression)
-	fmt.Printf("%s\n", value.String())
+	fmt.Fprintf(i.lox.Config.Stdout, "%s\n", i.Stringify(stmt.Keyword, value))
 }
 
 type PanicForReturn struct {
@@ -70,9 +73,8 @@ func (i *Interpreter) Visit_ReturnStmt(stmt *ast.Return) {
 	panic(PanicForReturn{Token: stmt.Keyword, Result: value})
 }
 
-func (i *Interpreter) Visit_PanicStmt(stmt *ast.Panic) {
-	var value Value = i.evaluate(stmt.Expression)
-	i.Error(stmt.Keyword, value.String())
+func (i *Interpreter) Visit_BadStmtStmt(stmt *ast.BadStmt) {
+	panic(i.Error(stmt.From, "Cannot run a statement that failed to parse."))
 }
 
 func (i *Interpreter) Visit_BlockStmt(stmt *ast.Block) {
@@ -106,7 +108,12 @@ func (i *Interpreter) Visit_ClassStmt(stmt *ast.Class) {
 
 	i.Define(stmt.Name, token.NilValue{}) // make class name visible to methods
 
-	saveEnvironment := i.environment
+	// Trait members come first, so the class's own methods replace them.
+	instanceMembers := linkTraitMembers(
+		i.traitMembers(stmt.Name, stmt.Traits, stmt.Methods), superclass)
+
+	previous := i.environment
+	defer func() { i.environment = previous }()
 	if stmt.Superclass != nil {
 		i.environment = NewNestedEnvironment(i.environment)
 		super := &token.Token{Type_: token.Super, Lexeme_: "super"}
@@ -114,22 +121,32 @@ func (i *Interpreter) Visit_ClassStmt(stmt *ast.Class) {
 	}
 
 	env := i.GetCurrentEnvironment()
-	methods := make(map[string]*LoxFunction)
 	for _, method := range stmt.Methods {
 		var function *LoxFunction = NewLoxFunction(
 			method, env,
-			method.Name.Lexeme() == "init",
+			method.Name.Lexeme() == "init" &&
+				method.Kind == ast.OrdinaryFunction,
 		)
-		methods[method.Name.Lexeme()] = function
+		instanceMembers.Add(function)
 	}
-
-	class := NewLoxClass(stmt.Name, superclass, methods)
-
-	if superclass != nil {
-		i.environment = saveEnvironment
+	staticMembers := NewMethodTable()
+	for _, method := range stmt.StaticMethods {
+		staticMembers.Add(NewLoxFunction(method, env, false))
 	}
 
+	class := NewLoxClass(stmt.Name, superclass, instanceMembers, staticMembers)
 	i.Assign(stmt.Name, token.ObjectValue{class})
+
+	// Static field initializers run in order, with `this` bound to the class.
+	if len(stmt.StaticFields) > 0 {
+		this := &token.Token{Type_: token.This, Lexeme_: "this"}
+		staticEnv := NewNestedEnvironment(env)
+		staticEnv.Define(this, token.ObjectValue{class})
+		i.environment = staticEnv
+		for _, field := range stmt.StaticFields {
+			class.Fields[field.Name.Lexeme()] = i.evaluate(field.Initializer)
+		}
+	}
 }
 
 func (i *Interpreter) Visit_FunctionStmt(stmt *ast.Function) {
@@ -140,15 +157,21 @@ func (i *Interpreter) Visit_FunctionStmt(stmt *ast.Function) {
 }
 
 func (i *Interpreter) Visit_IfStmt(stmt *ast.If) {
-	if isTruthy(i.evaluate(stmt.Condition)) {
+	if token.IsTruthy(i.evaluate(stmt.Condition)) {
+		i.branch(stmt, ThenArm)
 		i.execute(stmt.ThenBranch)
-	} else if stmt.ElseBranch != nil {
-		i.execute(stmt.ElseBranch)
+	} else {
+		i.branch(stmt, ElseArm)
+		if stmt.ElseBranch != nil {
+			i.execute(stmt.ElseBranch)
+		}
 	}
 }
 
 func (i *Interpreter) Visit_WhileStmt(stmt *ast.While) {
-	for isTruthy(i.evaluate(stmt.Condition)) {
+	for token.IsTruthy(i.evaluate(stmt.Condition)) {
+		i.branch(stmt, BodyArm)
 		i.execute(stmt.Body)
 	}
+	i.branch(stmt, ExitArm)
 }
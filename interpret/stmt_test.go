@@ -1,6 +1,8 @@
 package interpret
 
 import (
+	"fmt"
+
 	"github.com/perlmonger42/go-lox/config"
 	"github.com/perlmonger42/go-lox/lox"
 	"github.com/perlmonger42/go-lox/parse"
@@ -9,7 +11,10 @@ import (
 )
 
 func exec(text string) {
-	config := config.New()
+	execWithConfig(config.New(), text)
+}
+
+func execWithConfig(config *config.T, text string) {
 	lox := lox.New(config)
 	scanner := scan.New(lox, text)
 	tokens := scanner.ScanTokens()
@@ -69,7 +74,7 @@ func ExampleVar() {
 	// Output:
 	// 3
 	// 6
-	// [line 6] Error at 'Identifier': Undefined variable 'c'.
+	// [line 6] Error at 'c': Undefined variable 'c'.
 }
 
 func ExampleBlocks() {
@@ -119,7 +124,7 @@ func ExampleRedefine() {
 	// 1
 	// 2
 	// 3
-	// [line 8] Error at 'Identifier': Variable 'a' redefined.
+	// [line 8] Error at 'a': Variable 'a' redefined.
 	// 4
 }
 
@@ -266,10 +271,10 @@ func ExampleNestedAndUnnest() {
 		  `)
 	// Output:
 	// global a, outer b, inner c
-	// [line 8] Error at 'Identifier': Undefined variable 'c'.
+	// [line 8] Error at 'c': Undefined variable 'c'.
 	// global a, outer b, {([<nil>])}
-	// [line 10] Error at 'Identifier': Undefined variable 'b'.
-	// [line 10] Error at 'Identifier': Undefined variable 'c'.
+	// [line 10] Error at 'b': Undefined variable 'b'.
+	// [line 10] Error at 'c': Undefined variable 'c'.
 	// global a, {([<nil>])}, {([<nil>])}
 }
 
@@ -289,7 +294,7 @@ func ExampleNestedUndefined() {
 	// global a
 	// outer b
 	// inner c
-	// [line 9] Error at 'Identifier': Undefined variable 'd'.
+	// [line 9] Error at 'd': Undefined variable 'd'.
 	// nil
 }
 
@@ -347,7 +352,7 @@ func ExampleForLoop() {
 func ExampleBadReturn() {
 	exec(`return 17;`)
 	// Output:
-	// [line 1] Error at 'Return': Cannot return from top-level code.
+	// [line 1] Error at 'return': Cannot return from top-level code.
 }
 
 func ExampleFunction() {
@@ -424,7 +429,7 @@ func ExampleRedeclareFormal() {
 	`)
 	// Output:
 	// 13
-	// [line 4] Error at 'Identifier': Variable 'a' redefined.
+	// [line 4] Error at 'a': Variable 'a' redefined.
 	// redefined
 }
 
@@ -468,7 +473,7 @@ func ExampleInvalidThis() {
 print this;
 	`)
 	// Output:
-	// [line 2] Error at 'This': Cannot use `this` outside of a class.
+	// [line 2] Error at 'this': Cannot use `this` outside of a class.
 }
 
 func ExampleClass() {
@@ -506,7 +511,7 @@ print w.nonexistent;
 	// Output:
 	// my shiny new widget
 	// 42
-	// [line 8] Error at 'Identifier': Undefined property `nonexistent`.
+	// [line 8] Error at 'nonexistent': Undefined property `nonexistent`.
 	// runtime error: {Identifier: `nonexistent` Undefined property `nonexistent`.}
 }
 
@@ -583,7 +588,7 @@ class Datum {
 }
 	`)
 	// Output:
-	// [line 4] Error at 'Return': Cannot return a value from an initializer.
+	// [line 4] Error at 'return': Cannot return a value from an initializer.
 }
 
 func ExampleReturnEarlyFromInit() {
@@ -627,7 +632,7 @@ class C < C {
 }
 	`)
 	// Output:
-	// [line 2] Error at 'Identifier': A class can't inherit from itself.
+	// [line 2] Error at 'C': A class can't inherit from itself.
 }
 
 func ExampleNonclassSuperclass() {
@@ -636,7 +641,7 @@ var NotAClass = "I am totally not a class.";
 class Extension < NotAClass { }
 	`)
 	// Output:
-	// [line 3] Error at 'Identifier': Superclass must be a class.
+	// [line 3] Error at 'NotAClass': Superclass must be a class.
 	// runtime error: {Identifier: `NotAClass` Superclass must be a class.}
 }
 
@@ -646,8 +651,8 @@ class Extension < Base {
 }
 	`)
 	// Output:
-	// [line 2] Error at 'Identifier': Undefined variable 'Base'.
-	// [line 2] Error at 'Identifier': Superclass must be a class.
+	// [line 2] Error at 'Base': Undefined variable 'Base'.
+	// [line 2] Error at 'Base': Superclass must be a class.
 	// runtime error: {Identifier: `Base` Superclass must be a class.}
 }
 
@@ -743,6 +748,212 @@ class Eclair {
 super.notEvenInAClass();
 	`)
 	// Output:
-	// [line 4] Error at 'Super': Can't use 'super' in a class with no superclass.
-	// [line 8] Error at 'Super': Can't use 'super' outside of a class.
+	// [line 4] Error at 'super': Can't use 'super' in a class with no superclass.
+	// [line 8] Error at 'super': Can't use 'super' outside of a class.
+}
+
+func ExampleStaticMethods() {
+	exec(`
+class Math {
+  class square(x) { return x * x; }
+  class circle(r) { return this.pi * this.square(r); }
+  class pi = 3;
+  class tau = this.pi * 2;
+}
+print Math.square(3);
+print Math.circle(2);
+print Math.tau;
+var sq = Math.square;
+print sq(5);
+Math.e = 2.7;
+print Math.e;
+print Math().square;
+	`)
+	// Output:
+	// 9
+	// 12
+	// 6
+	// 25
+	// 2.7
+	// [line 15] Error at 'square': Undefined property `square`.
+	// runtime error: {Identifier: `square` Undefined property `square`.}
+}
+
+func ExampleInheritedStatics() {
+	exec(`
+class Counter {
+  class count = 0;
+  class next() { this.count = this.count + 1; return this.count; }
+  class describe() { return "counter"; }
+}
+class Hundreds < Counter {
+  class next() { return super.next() * 100; }
+}
+print Counter.next();
+print Hundreds.next();
+print Counter.count;
+print Hundreds.count;
+print Hundreds.describe();
+	`)
+	// Output:
+	// 1
+	// 200
+	// 1
+	// 2
+	// counter
+}
+
+func ExampleShadowedStatics() {
+	exec(`
+class Shape {
+  class name = "shape";
+  class sides() { return 0; }
+}
+class Square < Shape {
+  class name() { return "square"; }
+  class sides = 4;
+}
+print Square.name();
+print Square.sides;
+print Shape.name;
+	`)
+	// Output:
+	// square
+	// 4
+	// shape
+}
+
+func ExampleGettersAndSetters() {
+	exec(`
+class Circle {
+  init(radius) { this.radius = radius; }
+  area { return 3 * this.radius * this.radius; }
+  diameter { return this.radius * 2; }
+  set diameter(d) { this.radius = d / 2; }
+}
+var c = Circle(2);
+print c.area;
+c.diameter = 10;
+print c.radius;
+print c.diameter;
+c.area = 1;
+	`)
+	// Output:
+	// 12
+	// 5
+	// 10
+	// [line 13] Error at 'area': Cannot assign to property `area`, which has a getter but no setter.
+	// runtime error: {Identifier: `area` Cannot assign to property `area`, which has a getter but no setter.}
+}
+
+func ExampleInheritedGetters() {
+	exec(`
+class Shape {
+  name { return "shape"; }
+  set label(l) { this.text = "<" + l + ">"; }
+}
+class Square < Shape {
+  name { return "square, a kind of " + super.name; }
+  class count { return 4; }
+}
+var s = Square();
+print s.name;
+s.label = "hi";
+print s.text;
+print Square.count;
+	`)
+	// Output:
+	// square, a kind of shape
+	// <hi>
+	// 4
+}
+
+func ExampleTraits() {
+	exec(`
+trait Greets {
+  greet() { print "Hello from " + this.name(); }
+  shout { return "HEY"; }
+}
+trait Named {
+  name() { return "named"; }
+}
+trait Friendly with Greets, Named {}
+class Person with Greets, Named {
+  name() { return "person"; }
+}
+class Robot with Friendly {}
+Person().greet();
+print Person().shout;
+Robot().greet();
+	`)
+	// Output:
+	// Hello from person
+	// HEY
+	// Hello from named
+}
+
+func ExampleTraitSuper() {
+	exec(`
+trait Describes {
+  describe() { return "described " + super.describe(); }
+}
+class Base {
+  describe() { return "base"; }
+}
+class Derived < Base with Describes {}
+class Lone with Describes {}
+print Derived().describe();
+Lone().describe();
+	`)
+	// Output:
+	// described base
+	// [line 3] Error at 'super': Can't use 'super' in a trait method of a class with no superclass.
+	// runtime error: {Super: `super` Can't use 'super' in a trait method of a class with no superclass.}
+}
+
+func ExampleTraitConflicts() {
+	exec(`
+trait A { m() { return "a"; } }
+trait B { m() { return "b"; } }
+trait C with A {}
+class Resolved with A, B { m() { return "resolved"; } }
+class Diamond with A, C {}
+class Conflict with A, B {}
+trait Self with Self {}
+	`)
+	// Output:
+	// [line 7] Error at 'B': Traits A and B both provide 'm'; Conflict must define its own.
+	// [line 8] Error at 'Self': A trait can't include itself.
+}
+
+func ExampleNotATrait() {
+	exec(`
+var x = 1;
+class Bad with x {}
+	`)
+	// Output:
+	// [line 3] Error at 'x': `x` is not a trait.
+	// runtime error: {Identifier: `x` `x` is not a trait.}
+}
+
+func ExampleStaticFieldError() {
+	lox := lox.New(config.New())
+	interpreter := New(lox)
+	for _, text := range []string{`
+class A {}
+class B < A { class broken = nil.field; }
+`, `
+var after = "global";
+print after;
+`} {
+		stmts := parse.New(lox, scan.New(lox, text).ScanTokens()).ParseProg()
+		resolve.New(lox, interpreter).ResolveStmtList(stmts)
+		interpreter.InterpretStmts(stmts)
+	}
+	fmt.Println(interpreter.GetCurrentEnvironment() == interpreter.GetGlobalEnvironment())
+	// Output:
+	// [line 3] Error at 'field': Only instances have properties.
+	// runtime error: {Identifier: `field` Only instances have properties.}
+	// global
+	// true
 }
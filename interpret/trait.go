@@ -0,0 +1,145 @@
+package interpret
+
+import (
+	"fmt"
+
+	"github.com/perlmonger42/go-lox/ast"
+	"github.com/perlmonger42/go-lox/token"
+)
+
+// LoxTrait is a trait value: a named set of methods, getters and setters
+// that classes (and other traits) include with a `with` clause.
+//
+// A trait's functions close over the environment of the trait declaration.
+// When a class includes the trait, each function is linked to the class by
+// wrapping its closure in an environment that binds `super` to the class's
+// superclass, so `super.m()` in a trait method finds the m() that the
+// including class inherits. The trait's own functions are never called
+// directly.
+type LoxTrait struct {
+	Name    token.T
+	Methods map[string]*LoxFunction // methods and getters
+	Setters map[string]*LoxFunction
+}
+
+var _ token.Object = &LoxTrait{}
+
+func NewLoxTrait(name token.T, members MethodTable) *LoxTrait {
+	return &LoxTrait{
+		Name:    name,
+		Methods: members.Methods,
+		Setters: members.Setters,
+	}
+}
+
+// members returns every function of the trait, keyed by member name.
+func (t *LoxTrait) members() map[string]*LoxFunction {
+	members := make(map[string]*LoxFunction)
+	for _, f := range t.Methods {
+		members[f.Declaration.MemberName()] = f
+	}
+	for _, f := range t.Setters {
+		members[f.Declaration.MemberName()] = f
+	}
+	return members
+}
+
+func (t *LoxTrait) EqualsObject(o token.Object) bool {
+	if it, ok := o.(*LoxTrait); ok {
+		return t == it
+	}
+	return false
+}
+
+func (t *LoxTrait) TypeName() string { return "trait" }
+
+func (t *LoxTrait) String() string {
+	return "trait " + t.Name.Lexeme()
+}
+
+func (t *LoxTrait) Show() string {
+	return t.String()
+}
+
+func (i *Interpreter) Visit_TraitStmt(stmt *ast.Trait) {
+	members := i.traitMembers(stmt.Name, stmt.Traits, stmt.Methods)
+	env := i.GetCurrentEnvironment()
+	for _, method := range stmt.Methods {
+		members.Add(NewLoxFunction(method, env,
+			method.Name.Lexeme() == "init" &&
+				method.Kind == ast.OrdinaryFunction))
+	}
+	i.Define(stmt.Name, token.ObjectValue{NewLoxTrait(stmt.Name, members)})
+}
+
+// traitMembers evaluates the traits of a `with` clause and collects their
+// functions into a MethodTable. Members that the class or trait declares
+// itself (own) are left out, since they take precedence; any other member
+// provided by two different traits is an error.
+func (i *Interpreter) traitMembers(
+	owner token.T,
+	traits []*ast.Variable,
+	own []*ast.Function,
+) MethodTable {
+	declared := make(map[string]bool)
+	for _, method := range own {
+		declared[method.MemberName()] = true
+	}
+
+	table := NewMethodTable()
+	providers := make(map[string]*LoxTrait)
+	for _, traitVar := range traits {
+		trait := i.evaluateTrait(traitVar)
+		for name, f := range trait.members() {
+			if declared[name] {
+				continue
+			}
+			if other, ok := providers[name]; ok {
+				if existing := other.members()[name]; existing.Declaration != f.Declaration {
+					panic(i.Error(traitVar.Name, fmt.Sprintf(
+						"Traits %s and %s both provide '%s'; %s must define its own.",
+						other.Name.Lexeme(), trait.Name.Lexeme(), name,
+						owner.Lexeme())))
+				}
+				continue
+			}
+			providers[name] = trait
+			table.Add(f)
+		}
+	}
+	return table
+}
+
+func (i *Interpreter) evaluateTrait(traitVar *ast.Variable) *LoxTrait {
+	if value, ok := i.evaluate(traitVar).(token.ObjectValue); ok {
+		if trait, ok := value.V.(*LoxTrait); ok {
+			return trait
+		}
+	}
+	panic(i.Error(traitVar.Name,
+		fmt.Sprintf("`%s` is not a trait.", traitVar.Name.Lexeme())))
+}
+
+// linkTraitMembers links trait functions into a class whose superclass is
+// superclass (which may be nil), so that `super` in them refers to it.
+func linkTraitMembers(table MethodTable, superclass *LoxClass) MethodTable {
+	var superValue token.Value = token.NilValue{}
+	if superclass != nil {
+		superValue = token.ObjectValue{superclass}
+	}
+	super := &token.Token{Type_: token.Super, Lexeme_: "super"}
+
+	linked := NewMethodTable()
+	link := func(f *LoxFunction) {
+		env := NewNestedEnvironment(f.Closure)
+		env.Define(super, superValue)
+		linked.Add(NewLoxFunction(f.Declaration, env, f.IsInitializer))
+	}
+	for _, f := range table.Methods {
+		link(f)
+	}
+	for _, f := range table.Setters {
+		link(f)
+	}
+	return linked
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/perlmonger42/go-lox/config"
+	"github.com/perlmonger42/go-lox/lint"
+	"github.com/perlmonger42/go-lox/lox"
+	"github.com/perlmonger42/go-lox/parse"
+	"github.com/perlmonger42/go-lox/scan"
+	"github.com/perlmonger42/go-lox/token"
+)
+
+// lintConfigFile is the configuration `go-lox lint` reads, if it exists and
+// no other is given.
+const lintConfigFile = ".loxlint"
+
+// lintCommand implements `go-lox lint`, which reports suspicious code in
+// Lox files. It returns the process's exit status: 1 if there were any
+// problems, 65 if a file has syntax errors.
+func lintCommand(args []string) int {
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	configFile := flags.String("config", "",
+		"read the rules to enable from `file` (default "+lintConfigFile+", if present)")
+	list := flags.Bool("list", false, "list the rules, and whether each is enabled")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: go-lox lint [-config file] [-list] files...\n")
+		fmt.Fprintf(os.Stderr, "Flags:\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	conf := lint.DefaultConfig()
+	filename := *configFile
+	if filename == "" {
+		if _, err := os.Stat(lintConfigFile); err == nil {
+			filename = lintConfigFile
+		}
+	}
+	if filename != "" {
+		f, err := os.Open(filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "go-lox lint: %s\n", err)
+			return 66 // see "sysexits.h"
+		}
+		conf, err = lint.ReadConfig(filename, f)
+		f.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "go-lox lint: %s\n", err)
+			return 78 // see "sysexits.h"
+		}
+	}
+
+	if *list {
+		for _, rule := range lint.Rules {
+			state := "disabled"
+			if conf.Enabled(rule.Name) {
+				state = "enabled"
+			}
+			fmt.Printf("%-20s %-8s %s\n", rule.Name, state, rule.Doc)
+		}
+		return 0
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 64 // see "sysexits.h"
+	}
+
+	status := 0
+	for _, filename := range flags.Args() {
+		content, err := ioutil.ReadFile(filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "go-lox lint: %s\n", err)
+			status = 66 // see "sysexits.h"
+			continue
+		}
+		if s := lintText(os.Stdout, filename, string(content), conf); s > status {
+			status = s
+		}
+	}
+	return status
+}
+
+// lintText checks the source of one file, printing its problems to out.
+func lintText(out io.Writer, filename, src string, conf *lint.Config) int {
+	reporter := &lintReporter{out: out, filename: filename}
+	config := config.New()
+	config.Reporter = reporter
+	config.Warnings = true
+	lox := lox.New(config)
+
+	tokens := scan.New(lox, src).ScanTokens()
+	stmts := parse.New(lox, tokens).Parse()
+	if lox.HadError {
+		return 65 // see "sysexits.h"
+	}
+	lint.Check(lox, stmts, conf)
+	if reporter.problems > 0 {
+		return 1
+	}
+	return 0
+}
+
+// A lintReporter is a report.Warner that prints errors and warnings as
+// "file:line:column: message", and counts the warnings.
+type lintReporter struct {
+	out      io.Writer
+	filename string
+	problems int
+}
+
+func (r *lintReporter) Report(pos token.Pos, where string, message string) {
+	fmt.Fprintf(r.out, "%s: error: %s\n", r.position(pos), message)
+}
+
+func (r *lintReporter) Warn(pos token.Pos, where string, message string) {
+	r.problems++
+	fmt.Fprintf(r.out, "%s: %s\n", r.position(pos), message)
+}
+
+func (r *lintReporter) position(pos token.Pos) string {
+	if pos.Column() == 0 {
+		return fmt.Sprintf("%s:%d", r.filename, pos.Line())
+	}
+	return fmt.Sprintf("%s:%d:%d", r.filename, pos.Line(), pos.Column())
+}
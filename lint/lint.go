@@ -0,0 +1,153 @@
+// Package lint checks Lox programs for code that is legal but likely to be
+// wrong, or that breaks conventions, for `go-lox lint`.
+//
+// Each Rule inspects every node of a program's syntax tree. Which rules run
+// is given by a Config, which is read from a file of lines like
+//
+//	# Comments and blank lines are ignored.
+//	enable naming
+//	disable empty-block
+//	disable all
+//
+// applied in order, starting from the rules' defaults. Problems are reported
+// as warnings, by lox.T.Warn, so a `// lox:ignore rule` comment suppresses
+// them.
+package lint
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/perlmonger42/go-lox/ast"
+	"github.com/perlmonger42/go-lox/lox"
+	"github.com/perlmonger42/go-lox/token"
+)
+
+// A Rule is a check of a program.
+type Rule struct {
+	Name    string
+	Doc     string // what it reports, in a sentence
+	Default bool   // whether it is enabled unless configured otherwise
+
+	// check is called for each node of the program, with the nodes that
+	// enclose it, outermost first.
+	check func(p *pass, n ast.Node, stack []ast.Node)
+}
+
+// Rules are all the rules, in the order `go-lox lint -list` shows them.
+var Rules = []*Rule{
+	selfCompare,
+	constantCondition,
+	emptyBlock,
+	stringPlusNil,
+	plusNil,
+	initWithoutFields,
+	methodWithoutThis,
+	naming,
+}
+
+// Lookup returns the rule with the given name, or nil.
+func Lookup(name string) *Rule {
+	for _, rule := range Rules {
+		if rule.Name == name {
+			return rule
+		}
+	}
+	return nil
+}
+
+// A Config says which rules are enabled.
+type Config struct {
+	enabled map[string]bool
+}
+
+// DefaultConfig returns a Config enabling the rules enabled by default.
+func DefaultConfig() *Config {
+	c := &Config{enabled: make(map[string]bool)}
+	for _, rule := range Rules {
+		c.enabled[rule.Name] = rule.Default
+	}
+	return c
+}
+
+// Enabled reports whether the named rule is enabled.
+func (c *Config) Enabled(name string) bool {
+	return c.enabled[name]
+}
+
+// ReadConfig reads a Config file, reporting its first error by filename
+// and line.
+func ReadConfig(filename string, r io.Reader) (*Config, error) {
+	c := DefaultConfig()
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if i := strings.Index(text, "#"); i >= 0 {
+			text = text[:i]
+		}
+		words := strings.Fields(text)
+		if len(words) == 0 {
+			continue
+		}
+		if len(words) != 2 || words[0] != "enable" && words[0] != "disable" {
+			return nil, fmt.Errorf("%s:%d: want `enable rule` or `disable rule`",
+				filename, line)
+		}
+		on := words[0] == "enable"
+		if words[1] == "all" {
+			for _, rule := range Rules {
+				c.enabled[rule.Name] = on
+			}
+		} else if Lookup(words[1]) != nil {
+			c.enabled[words[1]] = on
+		} else {
+			return nil, fmt.Errorf("%s:%d: unknown rule %q", filename, line, words[1])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %s", filename, err)
+	}
+	return c, nil
+}
+
+// Check runs the enabled rules over a program, warning of the problems they
+// find through lox.Warn. Those are only reported if lox.Config.Warnings is
+// set.
+func Check(lox *lox.T, stmts []ast.Stmt, config *Config) {
+	var rules []*Rule
+	for _, rule := range Rules {
+		if config.Enabled(rule.Name) {
+			rules = append(rules, rule)
+		}
+	}
+	var stack []ast.Node
+	visit := func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return false
+		}
+		for _, rule := range rules {
+			rule.check(&pass{lox, rule}, n, stack)
+		}
+		stack = append(stack, n)
+		return true
+	}
+	for _, stmt := range stmts {
+		ast.Inspect(stmt, visit)
+	}
+}
+
+// A pass is a rule being checked against a program.
+type pass struct {
+	lox  *lox.T
+	rule *Rule
+}
+
+// report reports a problem at tok.
+func (p *pass) report(tok token.T, format string, args ...interface{}) {
+	if tok != nil {
+		p.lox.Warn(tok, p.rule.Name, fmt.Sprintf(format, args...))
+	}
+}
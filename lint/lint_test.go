@@ -0,0 +1,83 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/perlmonger42/go-lox/config"
+	"github.com/perlmonger42/go-lox/lox"
+	"github.com/perlmonger42/go-lox/parse"
+	"github.com/perlmonger42/go-lox/scan"
+)
+
+func lint(text string, conf *Config) {
+	config := config.New()
+	config.Warnings = true
+	lox := lox.New(config)
+	stmts := parse.New(lox, scan.New(lox, text).ScanTokens()).Parse()
+	Check(lox, stmts, conf)
+}
+
+func ExampleCheck() {
+	lint(`
+if (x == x) print "same";
+while (nil) {}
+while (true) { print "forever"; }
+print "value: " + nil;
+class Point {
+  init(x, y) { print x + y; }
+  norm() { return 1; }
+}
+var BadName = 1;
+`, DefaultConfig())
+	// Output:
+	// [line 2] Warning at '==': Comparison of an expression with itself. (self-compare)
+	// [line 3] Warning at 'while': `while` condition is always false. (constant-condition)
+	// [line 3] Warning at '{': Empty block. (empty-block)
+	// [line 5] Warning at '+': Adding `nil` to a string appends "{([<nil>])}". (string-plus-nil)
+	// [line 7] Warning at 'init': `init` doesn't set any field of `this`. (init-without-fields)
+}
+
+func ExampleReadConfig() {
+	conf, err := ReadConfig("lint.conf", strings.NewReader(`
+# Style only.
+disable all
+enable naming   # names
+enable method-without-this
+`))
+	fmt.Println(err)
+	lint(`
+class point {
+  norm() { return 1; }
+  area() { return this.w * this.h; }
+}
+if (true) {} // lox:ignore
+fun Area(the_point) { return the_point.area(); }
+`, conf)
+	_, err = ReadConfig("lint.conf", strings.NewReader("enable everything\n"))
+	fmt.Println(err)
+	// Output:
+	// <nil>
+	// [line 2] Warning at 'point': Class name `point` should be UpperCamelCase. (naming)
+	// [line 3] Warning at 'norm': Method `norm` doesn't use `this`; it could be static. (method-without-this)
+	// [line 7] Warning at 'Area': Function name `Area` should be lowerCamelCase. (naming)
+	// [line 7] Warning at 'the_point': Parameter name `the_point` should be lowerCamelCase. (naming)
+	// lint.conf:1: unknown rule "everything"
+}
+
+func ExampleCheck_plusNil() {
+	lint(`
+var s = "a";
+print s + nil;
+print 1 + (nil);
+print nil + s;
+print s + 1 + nil;
+print true + nil;
+`, DefaultConfig())
+	// Output:
+	// [line 3] Warning at '+': Adding `nil` to a string appends "{([<nil>])}". (string-plus-nil)
+	// [line 4] Warning at '+': Adding `nil` to a number is always an error. (plus-nil)
+	// [line 5] Warning at '+': Adding to `nil` is always an error. (plus-nil)
+	// [line 6] Warning at '+': Adding `nil` to a string appends "{([<nil>])}". (string-plus-nil)
+	// [line 7] Warning at '+': Adding `nil` to a boolean is always an error. (plus-nil)
+}
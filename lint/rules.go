@@ -0,0 +1,260 @@
+package lint
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/perlmonger42/go-lox/ast"
+	"github.com/perlmonger42/go-lox/token"
+)
+
+var selfCompare = &Rule{
+	Name:    "self-compare",
+	Doc:     "comparisons of an expression with itself",
+	Default: true,
+	check: func(p *pass, n ast.Node, stack []ast.Node) {
+		b, ok := n.(*ast.Binary)
+		if !ok {
+			return
+		}
+		switch b.Operator.Type() {
+		case token.EqualEqual, token.BangEqual, token.Less, token.LessEqual,
+			token.Greater, token.GreaterEqual:
+		default:
+			return
+		}
+		if ast.Equal(b.Left, b.Right) && !hasCall(b.Left) {
+			p.report(b.Operator, "Comparison of an expression with itself.")
+		}
+	},
+}
+
+var constantCondition = &Rule{
+	Name:    "constant-condition",
+	Doc:     "`if` and `while` conditions that are literals (but not `while (true)`)",
+	Default: true,
+	check: func(p *pass, n ast.Node, stack []ast.Node) {
+		switch n := n.(type) {
+		case *ast.If:
+			if lit, ok := unparen(n.Condition).(*ast.Literal); ok {
+				p.report(n.Keyword, "`%s` condition is always %t.",
+					n.Keyword.Lexeme(), token.IsTruthy(lit.Value))
+			}
+		case *ast.While:
+			if lit, ok := unparen(n.Condition).(*ast.Literal); ok {
+				if b, ok := lit.Value.(token.BooleanValue); !ok || !b.V {
+					p.report(n.Keyword, "`%s` condition is always %t.",
+						n.Keyword.Lexeme(), token.IsTruthy(lit.Value))
+				}
+			}
+		}
+	},
+}
+
+var emptyBlock = &Rule{
+	Name:    "empty-block",
+	Doc:     "blocks with no statements",
+	Default: true,
+	check: func(p *pass, n ast.Node, stack []ast.Node) {
+		if b, ok := n.(*ast.Block); ok && len(b.Statements) == 0 {
+			p.report(b.Token, "Empty block.")
+		}
+	},
+}
+
+var stringPlusNil = &Rule{
+	Name:    "string-plus-nil",
+	Doc:     "adding `nil` to what may be a string, which appends \"{([<nil>])}\"",
+	Default: true,
+	check: func(p *pass, n ast.Node, stack []ast.Node) {
+		b, ok := n.(*ast.Binary)
+		if !ok || b.Operator.Type() != token.Plus || !isNil(b.Right) {
+			return
+		}
+		if lit, ok := unparen(b.Left).(*ast.Literal); !ok || isString(lit) {
+			p.report(b.Operator, "Adding `nil` to a string appends \"{([<nil>])}\".")
+		}
+	},
+}
+
+var plusNil = &Rule{
+	Name:    "plus-nil",
+	Doc:     "adding to `nil`, or adding `nil` to a literal that isn't a string, which is always an error",
+	Default: true,
+	check: func(p *pass, n ast.Node, stack []ast.Node) {
+		b, ok := n.(*ast.Binary)
+		if !ok || b.Operator.Type() != token.Plus {
+			return
+		}
+		lit, ok := unparen(b.Left).(*ast.Literal)
+		switch {
+		case !ok:
+		case isNil(lit):
+			p.report(b.Operator, "Adding to `nil` is always an error.")
+		case isNil(b.Right) && !isString(lit):
+			p.report(b.Operator, "Adding `nil` to a %s is always an error.",
+				lit.Value.TypeName())
+		}
+	},
+}
+
+var initWithoutFields = &Rule{
+	Name:    "init-without-fields",
+	Doc:     "`init` methods that neither set fields of `this` nor call other methods",
+	Default: true,
+	check: func(p *pass, n ast.Node, stack []ast.Node) {
+		fn := method(n, stack)
+		if fn == nil || fn.Name.Lexeme() != "init" || fn.Kind != ast.OrdinaryFunction {
+			return
+		}
+		initializes := false
+		inspectMethod(fn, func(n ast.Node) {
+			switch n := n.(type) {
+			case *ast.Set:
+				_, initializes = n.Object.(*ast.This)
+			case *ast.Call:
+				if get, ok := n.Callee.(*ast.Get); ok {
+					_, initializes = get.Object.(*ast.This)
+				}
+			case *ast.Super:
+				initializes = true
+			}
+		}, func() bool { return initializes })
+		if !initializes {
+			p.report(fn.Name, "`init` doesn't set any field of `this`.")
+		}
+	},
+}
+
+var methodWithoutThis = &Rule{
+	Name: "method-without-this",
+	Doc:  "methods that never use `this` or `super`, and so could be static",
+	check: func(p *pass, n ast.Node, stack []ast.Node) {
+		fn := method(n, stack)
+		if fn == nil || fn.Name.Lexeme() == "init" || len(fn.Body) == 0 {
+			return
+		}
+		uses := false
+		inspectMethod(fn, func(n ast.Node) {
+			switch n.(type) {
+			case *ast.This, *ast.Super:
+				uses = true
+			}
+		}, func() bool { return uses })
+		if !uses {
+			p.report(fn.Name, "Method `%s` doesn't use `this`; it could be static.",
+				fn.Name.Lexeme())
+		}
+	},
+}
+
+var naming = &Rule{
+	Name: "naming",
+	Doc:  "classes and traits not named in UpperCamelCase, and other names not in lowerCamelCase",
+	check: func(p *pass, n ast.Node, stack []ast.Node) {
+		switch n := n.(type) {
+		case *ast.Class:
+			checkUpper(p, "Class", n.Name)
+		case *ast.Trait:
+			checkUpper(p, "Trait", n.Name)
+		case *ast.Function:
+			checkLower(p, "Function", n.Name)
+			for _, param := range n.Params {
+				checkLower(p, "Parameter", param)
+			}
+		case *ast.VarInitialized:
+			checkLower(p, "Variable", n.Name)
+		case *ast.VarUninitialized:
+			checkLower(p, "Variable", n.Name)
+		}
+	},
+}
+
+func checkUpper(p *pass, what string, name token.T) {
+	text := name.Lexeme()
+	first, _ := utf8.DecodeRuneInString(text)
+	if text != "" && (!unicode.IsUpper(first) || strings.Contains(text, "_")) {
+		p.report(name, "%s name `%s` should be UpperCamelCase.", what, text)
+	}
+}
+
+func checkLower(p *pass, what string, name token.T) {
+	text := strings.TrimLeft(name.Lexeme(), "_")
+	first, _ := utf8.DecodeRuneInString(text)
+	if text != "" && (unicode.IsUpper(first) || strings.Contains(text, "_")) {
+		p.report(name, "%s name `%s` should be lowerCamelCase.", what, name.Lexeme())
+	}
+}
+
+// method returns n if it is an instance method of the class enclosing it.
+func method(n ast.Node, stack []ast.Node) *ast.Function {
+	fn, ok := n.(*ast.Function)
+	if !ok || len(stack) == 0 {
+		return nil
+	}
+	if class, ok := stack[len(stack)-1].(*ast.Class); ok {
+		for _, m := range class.Methods {
+			if m == fn {
+				return fn
+			}
+		}
+	}
+	return nil
+}
+
+// inspectMethod calls f for each node in the body of a method, until done
+// reports true, skipping classes declared inside it, whose `this` is not
+// the method's.
+func inspectMethod(fn *ast.Function, f func(ast.Node), done func() bool) {
+	for _, stmt := range fn.Body {
+		ast.Inspect(stmt, func(n ast.Node) bool {
+			if n == nil || done() {
+				return false
+			}
+			if _, ok := n.(*ast.Class); ok {
+				return false
+			}
+			f(n)
+			return true
+		})
+	}
+}
+
+// hasCall reports whether evaluating expr calls a function, which may give
+// a different result each time.
+func hasCall(expr ast.Expr) bool {
+	found := false
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if _, ok := n.(*ast.Call); ok {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// isNil reports whether expr is the literal `nil`.
+func isNil(expr ast.Expr) bool {
+	if lit, ok := unparen(expr).(*ast.Literal); ok {
+		_, ok := lit.Value.(token.NilValue)
+		return ok
+	}
+	return false
+}
+
+// isString reports whether lit is a string literal.
+func isString(lit *ast.Literal) bool {
+	_, ok := lit.Value.(token.StringValue)
+	return ok
+}
+
+func unparen(expr ast.Expr) ast.Expr {
+	for {
+		group, ok := expr.(*ast.Grouping)
+		if !ok {
+			return expr
+		}
+		expr = group.Expression
+	}
+}
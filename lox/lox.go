@@ -2,8 +2,10 @@ package lox
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/perlmonger42/go-lox/config"
+	"github.com/perlmonger42/go-lox/report"
 	"github.com/perlmonger42/go-lox/token"
 )
 
@@ -11,6 +13,8 @@ type T struct {
 	Config      *config.T
 	Interactive bool
 	HadError    bool
+
+	ignored map[int][]string // by line, the warnings `lox:ignore` suppresses
 }
 
 func New(config *config.T) *T {
@@ -19,15 +23,64 @@ func New(config *config.T) *T {
 
 func (lox *T) Error(tok token.T, message string) {
 	lox.HadError = true
-	pos := tok.Whence()
-	typ := tok.Type()
-	if typ == token.EOF {
-		lox.Config.Reporter.Report(pos, "at end", message)
-	} else {
-		lox.Config.Reporter.Report(pos, fmt.Sprintf("at '%s'", typ), message)
+	lox.Config.Reporter.Report(tok.Whence(), where(tok), message)
+}
+
+// where describes where tok is, for a report: by its text, as in "at ';'",
+// or by what it is, if it has none.
+func where(tok token.T) string {
+	if tok.Type() == token.EOF {
+		return "at end"
+	}
+	if tok.Lexeme() == "" {
+		return "at " + tok.Type().Describe()
 	}
+	return fmt.Sprintf("at '%s'", tok.Lexeme())
 }
 
 func (l *T) Report(pos token.Pos, where string, message string) {
 	l.Config.Reporter.Report(pos, where, message)
 }
+
+// Warn reports a warning at tok, unless warnings are off, or the line has a
+// `lox:ignore` comment suppressing rule. Unlike an error, a warning doesn't
+// set HadError.
+func (lox *T) Warn(tok token.T, rule string, message string) {
+	if !lox.Config.Warnings || lox.isIgnored(tok.Whence().Line(), rule) {
+		return
+	}
+	warner, ok := lox.Config.Reporter.(report.Warner)
+	if !ok {
+		return
+	}
+	warner.Warn(tok.Whence(), where(tok), fmt.Sprintf("%s (%s)", message, rule))
+}
+
+// NoteComment notes a comment that applies to line. A comment of the form
+// `// lox:ignore rule...` suppresses warnings of the rules named on that
+// line, or if it names none, all warnings there; other comments are
+// ignored. (The scanner applies a comment to its own line, if it follows
+// code there, and otherwise to the next.)
+func (lox *T) NoteComment(line int, text string) {
+	words := strings.Fields(strings.TrimPrefix(text, "//"))
+	if len(words) == 0 || words[0] != "lox:ignore" {
+		return
+	}
+	if lox.ignored == nil {
+		lox.ignored = make(map[int][]string)
+	}
+	rules := words[1:]
+	if len(rules) == 0 {
+		rules = []string{"all"}
+	}
+	lox.ignored[line] = append(lox.ignored[line], rules...)
+}
+
+func (lox *T) isIgnored(line int, rule string) bool {
+	for _, r := range lox.ignored[line] {
+		if r == rule || r == "all" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,296 @@
+// Package loxtest runs unit tests written in Lox, as `go-lox test` does.
+//
+// A test file is a Lox file whose name ends in "_test.lox". Its tests are
+// the top-level functions whose names begin with "test" and take no
+// parameters, and, for each top-level class whose name begins with "Test",
+// the methods whose names begin with "test" and take no parameters; such a
+// class's init, if it has one, must take no parameters either. A test
+// fails if it raises a runtime error, which is usually the doing of one of
+// the assertion natives (assert, assertEqual, assertNotEqual and fail).
+//
+// Each test runs in a fresh interpreter, which first runs the whole file,
+// so the top level of a file can define helpers and data for its tests
+// without one test's changes to them being seen by another. A method test
+// is called on a new instance of its class.
+package loxtest
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/perlmonger42/go-lox/ast"
+	"github.com/perlmonger42/go-lox/capture"
+	"github.com/perlmonger42/go-lox/config"
+)
+
+// A Runner runs test files, reporting on them in the manner of `go test`.
+type Runner struct {
+	Out          io.Writer
+	Verbose      bool           // report every test, and its output, not just failures
+	Match        *regexp.Regexp // if not nil, run only the tests whose names it matches
+	Capabilities config.Capability
+
+	// Now returns the current time, for timing tests. If it is nil, it is
+	// the real time; tests of the runner may supply another.
+	Now func() time.Time
+}
+
+// A Test names a test in a file: a function, or a method of a class.
+type Test struct {
+	Class    string // "" for a function
+	Function string
+
+	initParams int // the number of parameters of the class's init
+}
+
+// Name returns the name by which the test is reported and matched.
+func (t Test) Name() string {
+	if t.Class == "" {
+		return t.Function
+	}
+	return t.Class + "." + t.Function
+}
+
+// call returns the Lox statement that runs the test.
+func (t Test) call() string {
+	if t.Class == "" {
+		return t.Function + "();"
+	}
+	return t.Class + "()." + t.Function + "();"
+}
+
+// Tests returns the tests declared by the top level of a program.
+func Tests(stmts []ast.Stmt) []Test {
+	var tests []Test
+	d := declarations{make(map[string]*ast.Class), make(map[string]*ast.Trait)}
+	for _, stmt := range stmts {
+		switch stmt := stmt.(type) {
+		case *ast.Class:
+			d.classes[stmt.Name.Lexeme()] = stmt
+		case *ast.Trait:
+			d.traits[stmt.Name.Lexeme()] = stmt
+		}
+	}
+	for _, stmt := range stmts {
+		switch stmt := stmt.(type) {
+		case *ast.Function:
+			if isTest(stmt, "test") {
+				tests = append(tests, Test{Function: stmt.Name.Lexeme()})
+			}
+		case *ast.Class:
+			if !strings.HasPrefix(stmt.Name.Lexeme(), "Test") {
+				continue
+			}
+			params := 0
+			if init := d.init(stmt.Methods, stmt.Traits, stmt.Superclass, 0); init != nil {
+				params = len(init.Params)
+			}
+			for _, method := range stmt.Methods {
+				if isTest(method, "test") {
+					tests = append(tests, Test{stmt.Name.Lexeme(), method.Name.Lexeme(), params})
+				}
+			}
+		}
+	}
+	return tests
+}
+
+// declarations are the top-level classes and traits of a program, by name.
+type declarations struct {
+	classes map[string]*ast.Class
+	traits  map[string]*ast.Trait
+}
+
+// init returns the init method among methods, or else that of the traits
+// or the superclass, if it can be found; depth guards against cycles.
+func (d declarations) init(
+	methods []*ast.Function, traits []*ast.Variable, superclass *ast.Variable, depth int,
+) *ast.Function {
+	if depth > len(d.classes)+len(d.traits) {
+		return nil
+	}
+	for _, method := range methods {
+		if method.Name.Lexeme() == "init" && method.Kind == ast.OrdinaryFunction {
+			return method
+		}
+	}
+	for _, name := range traits {
+		if trait, ok := d.traits[name.Name.Lexeme()]; ok {
+			if init := d.init(trait.Methods, trait.Traits, nil, depth+1); init != nil {
+				return init
+			}
+		}
+	}
+	if superclass != nil {
+		if class, ok := d.classes[superclass.Name.Lexeme()]; ok {
+			return d.init(class.Methods, class.Traits, class.Superclass, depth+1)
+		}
+	}
+	return nil
+}
+
+func isTest(f *ast.Function, prefix string) bool {
+	return f.Kind == ast.OrdinaryFunction && len(f.Params) == 0 &&
+		strings.HasPrefix(f.Name.Lexeme(), prefix)
+}
+
+// Files returns the test files named by paths: each path is a file, or a
+// directory searched recursively for files ending in "_test.lox".
+func Files(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		var found []string
+		err = filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && strings.HasSuffix(p, "_test.lox") {
+				found = append(found, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(found)
+		files = append(files, found...)
+	}
+	return files, nil
+}
+
+// Run runs the tests in the files named by paths (see Files), and reports
+// whether they all passed.
+func (r *Runner) Run(paths []string) (bool, error) {
+	files, err := Files(paths)
+	if err != nil {
+		return false, err
+	}
+	ok := true
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return false, err
+		}
+		if !r.File(file, string(content)) {
+			ok = false
+		}
+	}
+	return ok, nil
+}
+
+// File runs the tests in one file, and reports whether they all passed. A
+// file that fails to compile, or whose top level raises a runtime error,
+// fails as a whole.
+func (r *Runner) File(filename, source string) bool {
+	start := r.now()
+	failed := false
+	if tests, problem := r.load(filename, source); problem != "" {
+		fmt.Fprintf(r.Out, "%s", problem)
+		failed = true
+	} else {
+		for _, test := range tests {
+			if r.Match != nil && !r.Match.MatchString(test.Name()) {
+				continue
+			}
+			if !r.test(filename, source, test) {
+				failed = true
+			}
+		}
+	}
+
+	status := "ok  "
+	if failed {
+		status = "FAIL"
+	}
+	fmt.Fprintf(r.Out, "%s\t%s\t%.3fs\n", status, filename, r.now().Sub(start).Seconds())
+	return !failed
+}
+
+// load finds the tests in a file, checking that it compiles and that its
+// top level runs. If not, it returns a description of the problem.
+func (r *Runner) load(filename, source string) ([]Test, string) {
+	setup := r.run(filename, source, "")
+	if len(setup.errors) > 0 {
+		return nil, fmt.Sprintf("--- FAIL: %s [setup failed]\n%s%s",
+			filename, indent(setup.output), indent(setup.errors))
+	}
+	return Tests(setup.stmts), ""
+}
+
+// test runs one test, reporting on it, and reports whether it passed.
+func (r *Runner) test(filename, source string, test Test) bool {
+	if r.Verbose {
+		fmt.Fprintf(r.Out, "=== RUN   %s\n", test.Name())
+	}
+	if test.initParams > 0 {
+		fmt.Fprintf(r.Out, "--- FAIL: %s (0.00s)\n%s", test.Name(), indent(fmt.Sprintf(
+			"%s.init takes %d parameter(s), but a test class's must take none.\n",
+			test.Class, test.initParams)))
+		return false
+	}
+	start := r.now()
+	result := r.run(filename, source, test.call())
+	elapsed := fmt.Sprintf("%.2fs", r.now().Sub(start).Seconds())
+	if len(result.errors) > 0 {
+		fmt.Fprintf(r.Out, "--- FAIL: %s (%s)\n%s%s",
+			test.Name(), elapsed, indent(result.output), indent(result.errors))
+		return false
+	}
+	if r.Verbose {
+		fmt.Fprintf(r.Out, "%s--- PASS: %s (%s)\n", result.output, test.Name(), elapsed)
+	}
+	return true
+}
+
+// An outcome is the result of running a file, and perhaps a test, in a
+// fresh interpreter.
+type outcome struct {
+	stmts  []ast.Stmt
+	output string // printed by the program
+	errors string // errors reported, one per line
+}
+
+// run runs a file in a fresh interpreter, then the statement call, if any.
+func (r *Runner) run(filename, source, call string) outcome {
+	sources := []string{source}
+	if call != "" {
+		sources = append(sources, call)
+	}
+	result := capture.Run(r.Capabilities, sources...)
+	var errors strings.Builder
+	for _, err := range result.Errors {
+		fmt.Fprintf(&errors, "%s:%d: %s\n", filename, err.Pos.Line(), err.Message)
+	}
+	return outcome{stmts: result.Stmts, output: result.Output, errors: errors.String()}
+}
+
+func (r *Runner) now() time.Time {
+	if r.Now == nil {
+		return time.Now()
+	}
+	return r.Now()
+}
+
+// indent indents each line of text, as `go test` does the log of a test.
+func indent(text string) string {
+	if text == "" {
+		return ""
+	}
+	lines := strings.SplitAfter(strings.TrimSuffix(text, "\n"), "\n")
+	return "    " + strings.Join(lines, "    ") + "\n"
+}
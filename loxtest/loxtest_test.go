@@ -0,0 +1,61 @@
+package loxtest
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+)
+
+// runner returns a Runner with a clock that advances a millisecond each
+// time it is read.
+func runner(verbose bool) *Runner {
+	clock := time.Unix(0, 0)
+	return &Runner{
+		Out:     os.Stdout,
+		Verbose: verbose,
+		Now: func() time.Time {
+			clock = clock.Add(time.Millisecond)
+			return clock
+		},
+	}
+}
+
+func ExampleRunner_Run() {
+	ok, err := runner(false).Run([]string{"testdata"})
+	fmt.Println(ok, err)
+	// Output:
+	// --- FAIL: testdata/broken_test.lox [setup failed]
+	//     testdata/broken_test.lox:2: Undefined variable 'undefinedAtTopLevel'.
+	// FAIL	testdata/broken_test.lox	0.001s
+	// --- FAIL: TestNeedsArgument.testSize (0.00s)
+	//     TestNeedsArgument.init takes 1 parameter(s), but a test class's must take none.
+	// FAIL	testdata/init_test.lox	0.003s
+	// --- FAIL: testPop (0.00s)
+	//     popping
+	//     testdata/stack_test.lox:25: assertEqual: got "b", want "a"
+	// --- FAIL: TestStack.testAssert (0.00s)
+	//     testdata/stack_test.lox:31: assertion failed: stack is empty
+	// FAIL	testdata/stack_test.lox	0.011s
+	// ok  	testdata/string_test.lox	0.003s
+	// false <nil>
+}
+
+func ExampleRunner_File_verbose() {
+	r := runner(true)
+	r.Match = regexp.MustCompile(`Pop|TestStack`)
+	content, _ := os.ReadFile("testdata/stack_test.lox")
+	fmt.Println(r.File("stack_test.lox", string(content)))
+	// Output:
+	// === RUN   testPop
+	// --- FAIL: testPop (0.00s)
+	//     popping
+	//     stack_test.lox:25: assertEqual: got "b", want "a"
+	// === RUN   TestStack.testEmpty
+	// --- PASS: TestStack.testEmpty (0.00s)
+	// === RUN   TestStack.testAssert
+	// --- FAIL: TestStack.testAssert (0.00s)
+	//     stack_test.lox:31: assertion failed: stack is empty
+	// FAIL	stack_test.lox	0.007s
+	// false
+}
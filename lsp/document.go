@@ -0,0 +1,235 @@
+package lsp
+
+import (
+	"strings"
+
+	"github.com/perlmonger42/go-lox/ast"
+	"github.com/perlmonger42/go-lox/config"
+	"github.com/perlmonger42/go-lox/lox"
+	"github.com/perlmonger42/go-lox/parse"
+	"github.com/perlmonger42/go-lox/resolve"
+	"github.com/perlmonger42/go-lox/scan"
+	"github.com/perlmonger42/go-lox/token"
+	"github.com/perlmonger42/go-lox/typecheck"
+)
+
+// A document is an open Lox file, analyzed afresh each time its text
+// changes.
+type document struct {
+	uri     string
+	version int
+	text    string
+	lines   []string
+
+	tokens      []token.T
+	stmts       []ast.Stmt
+	bindings    *resolve.Bindings
+	diagnostics []Diagnostic
+}
+
+// diagnosticCollector is a report.Warner that keeps errors and warnings as
+// diagnostics.
+type diagnosticCollector struct {
+	doc *document
+}
+
+func (c *diagnosticCollector) Report(pos token.Pos, where string, message string) {
+	c.doc.diagnostics = append(c.doc.diagnostics, Diagnostic{
+		Range:    c.doc.rangeAt(pos),
+		Severity: SeverityError,
+		Source:   "go-lox",
+		Message:  message,
+	})
+}
+
+func (c *diagnosticCollector) Warn(pos token.Pos, where string, message string) {
+	c.doc.diagnostics = append(c.doc.diagnostics, Diagnostic{
+		Range:    c.doc.rangeAt(pos),
+		Severity: SeverityWarning,
+		Source:   "go-lox",
+		Message:  message,
+	})
+}
+
+// noLocals is a resolve.Resolver that discards what it is told; the server
+// doesn't run programs, so it has no use for variable depths.
+type noLocals struct{}
+
+func (noLocals) Resolve(expr ast.Expr, name token.T, depth int) {}
+
+// newDocument analyzes text as the scanner, parser and resolver would
+// before running it. Diagnostics come from the first of those phases that
+// finds errors, since later phases mostly repeat them; but the document is
+// parsed and resolved regardless, so that navigation works in the parts of
+// the program without errors.
+func newDocument(uri string, version int, text string) *document {
+	doc := &document{
+		uri:         uri,
+		version:     version,
+		text:        text,
+		lines:       strings.Split(text, "\n"),
+		diagnostics: []Diagnostic{},
+	}
+	config := config.New()
+	config.Reporter = &diagnosticCollector{doc}
+	config.Warnings = true
+	lox := lox.New(config)
+
+	doc.tokens = scan.New(lox, text).ScanTokens()
+	phaseErrors := len(doc.diagnostics)
+
+	doc.stmts = parse.New(lox, doc.tokens).Parse()
+	if phaseErrors > 0 {
+		doc.diagnostics = doc.diagnostics[:phaseErrors]
+	}
+	phaseErrors = len(doc.diagnostics)
+
+	resolver := resolve.New(lox, noLocals{})
+	resolver.Bindings = resolve.NewBindings()
+	func() {
+		defer func() { recover() }() // a badly broken tree is no reason to stop serving
+		resolver.ResolveStmtList(doc.stmts)
+		typecheck.Check(lox, doc.stmts, resolver.Bindings)
+	}()
+	doc.bindings = resolver.Bindings
+	if phaseErrors > 0 {
+		doc.diagnostics = doc.diagnostics[:phaseErrors]
+	}
+	return doc
+}
+
+// character converts a 1-based column on a 1-based source line into the
+// UTF-16 offset that LSP uses.
+func (doc *document) character(line, column int) int {
+	if line < 1 || line > len(doc.lines) || column < 1 {
+		return 0
+	}
+	text := doc.lines[line-1]
+	units := 0
+	for _, r := range text {
+		if column <= 1 {
+			break
+		}
+		column--
+		units += utf16Len(r)
+	}
+	return units
+}
+
+func utf16Len(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
+
+func (doc *document) position(pos token.Pos) Position {
+	return Position{pos.Line() - 1, doc.character(pos.Line(), pos.Column())}
+}
+
+// tokenRange returns the range that tok covers.
+func (doc *document) tokenRange(tok token.T) Range {
+	start := doc.position(tok.Whence())
+	width := 0
+	for _, r := range tok.Lexeme() {
+		width += utf16Len(r)
+	}
+	return Range{start, Position{start.Line, start.Character + width}}
+}
+
+// rangeAt returns the range of the token that starts at pos, or an empty
+// range at pos if there is none.
+func (doc *document) rangeAt(pos token.Pos) Range {
+	for _, tok := range doc.tokens {
+		if samePos(tok.Whence(), pos) {
+			return doc.tokenRange(tok)
+		}
+	}
+	start := doc.position(pos)
+	return Range{start, start}
+}
+
+func samePos(a, b token.Pos) bool {
+	return a.Line() == b.Line() && a.Column() == b.Column()
+}
+
+func (doc *document) location(tok token.T) Location {
+	return Location{doc.uri, doc.tokenRange(tok)}
+}
+
+// tokenAt returns the index of the token at pos, preferring an identifier
+// when pos lies between two tokens, or -1 if there is none.
+func (doc *document) tokenAt(pos Position) int {
+	found := -1
+	for n, tok := range doc.tokens {
+		if tok.Type() == token.EOF || !doc.tokenRange(tok).contains(pos) {
+			continue
+		}
+		if found < 0 || tok.Type() == token.Identifier {
+			found = n
+		}
+	}
+	return found
+}
+
+// declarationRange returns the range of the whole declaration whose name
+// is the token at index n: from the keyword before the name through the
+// closing `}` of its body, or through its `;`.
+func (doc *document) declarationRange(n int) Range {
+	start := n
+	if start > 0 {
+		switch doc.tokens[start-1].Type() {
+		case token.Var, token.Fun, token.Class, token.Trait:
+			start--
+		case token.Identifier:
+			if doc.tokens[start-1].Lexeme() == "set" {
+				start--
+			}
+		}
+	}
+
+	end := n
+	for end < len(doc.tokens)-1 {
+		typ := doc.tokens[end].Type()
+		if typ == token.Semicolon {
+			break
+		}
+		if typ == token.LeftBrace {
+			end = doc.matchingBrace(end)
+			break
+		}
+		end++
+	}
+	return Range{
+		doc.tokenRange(doc.tokens[start]).Start,
+		doc.tokenRange(doc.tokens[end]).End,
+	}
+}
+
+// matchingBrace returns the index of the `}` that closes the `{` at index
+// open, or of the last token if it is never closed.
+func (doc *document) matchingBrace(open int) int {
+	depth := 0
+	for n := open; n < len(doc.tokens); n++ {
+		switch doc.tokens[n].Type() {
+		case token.LeftBrace:
+			depth++
+		case token.RightBrace:
+			depth--
+			if depth == 0 {
+				return n
+			}
+		}
+	}
+	return len(doc.tokens) - 1
+}
+
+// indexOf returns the index of tok in doc.tokens, or -1.
+func (doc *document) indexOf(tok token.T) int {
+	for n, t := range doc.tokens {
+		if t == tok {
+			return n
+		}
+	}
+	return -1
+}
@@ -0,0 +1,323 @@
+package lsp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/perlmonger42/go-lox/ast"
+	"github.com/perlmonger42/go-lox/resolve"
+	"github.com/perlmonger42/go-lox/scan"
+	"github.com/perlmonger42/go-lox/token"
+)
+
+// declarationAt returns the declaration of the name at pos, if any.
+func (doc *document) declarationAt(pos Position) (token.T, *resolve.Declaration) {
+	n := doc.tokenAt(pos)
+	if n < 0 || doc.tokens[n].Type() != token.Identifier {
+		return nil, nil
+	}
+	tok := doc.tokens[n]
+	return tok, doc.bindings.DeclarationOf(tok)
+}
+
+// definition finds where the name at pos is declared. A property name
+// (following `.`) can't be resolved statically, so it goes to every method
+// of that name in the document.
+func (doc *document) definition(pos Position) []Location {
+	locations := []Location{}
+	n := doc.tokenAt(pos)
+	if n < 0 || doc.tokens[n].Type() != token.Identifier {
+		return locations
+	}
+	if n > 0 && doc.tokens[n-1].Type() == token.Dot {
+		for _, member := range doc.members() {
+			if member.Name.Lexeme() == doc.tokens[n].Lexeme() {
+				locations = append(locations, doc.location(member.Name))
+			}
+		}
+		return locations
+	}
+	if decl := doc.bindings.DeclarationOf(doc.tokens[n]); decl != nil {
+		locations = append(locations, doc.location(decl.Name))
+	}
+	return locations
+}
+
+// references finds the uses of the name at pos.
+func (doc *document) references(pos Position, includeDeclaration bool) []Location {
+	locations := []Location{}
+	_, decl := doc.declarationAt(pos)
+	if decl == nil {
+		return locations
+	}
+	if includeDeclaration {
+		locations = append(locations, doc.location(decl.Name))
+	}
+	for _, use := range doc.bindings.References(decl) {
+		locations = append(locations, doc.location(use))
+	}
+	return locations
+}
+
+// hover describes the declaration of the name at pos, or the native it
+// names.
+func (doc *document) hover(pos Position, natives map[string]string) *Hover {
+	tok, decl := doc.declarationAt(pos)
+	if tok == nil {
+		return nil
+	}
+	var text string
+	if decl != nil {
+		text = describe(decl)
+	} else if native, ok := natives[tok.Lexeme()]; ok {
+		text = native
+	} else {
+		return nil
+	}
+	return &Hover{
+		Contents: MarkupContent{"markdown", "```lox\n" + text + "\n```"},
+		Range:    doc.tokenRange(tok),
+	}
+}
+
+// describe renders the heading of a declaration, as Lox source.
+func describe(decl *resolve.Declaration) string {
+	name := decl.Name.Lexeme()
+	switch node := decl.Node.(type) {
+	case *ast.Function:
+		if decl.Kind == resolve.ParameterDeclaration {
+			return fmt.Sprintf("(parameter) %s of %s", name, signature(node))
+		}
+		return "fun " + signature(node)
+	case *ast.Class:
+		text := "class " + name
+		if node.Superclass != nil {
+			text += " < " + node.Superclass.Name.Lexeme()
+		}
+		return text + withClause(node.Traits)
+	case *ast.Trait:
+		return "trait " + name + withClause(node.Traits)
+	case *ast.VarInitialized:
+		return "var " + name + " = " + ast.ExprToString(node.Initializer)
+	}
+	return decl.Kind.String() + " " + name
+}
+
+func signature(f *ast.Function) string {
+	params := []string{}
+	for _, param := range f.Params {
+		params = append(params, param.Lexeme())
+	}
+	return f.Name.Lexeme() + "(" + strings.Join(params, ", ") + ")"
+}
+
+func withClause(traits []*ast.Variable) string {
+	if len(traits) == 0 {
+		return ""
+	}
+	names := []string{}
+	for _, trait := range traits {
+		names = append(names, trait.Name.Lexeme())
+	}
+	return " with " + strings.Join(names, ", ")
+}
+
+// members returns the methods, getters and setters of every class and
+// trait declared at the top level of the document.
+func (doc *document) members() []*ast.Function {
+	members := []*ast.Function{}
+	for _, stmt := range doc.stmts {
+		switch stmt := stmt.(type) {
+		case *ast.Class:
+			members = append(members, stmt.Methods...)
+			members = append(members, stmt.StaticMethods...)
+		case *ast.Trait:
+			members = append(members, stmt.Methods...)
+		}
+	}
+	return members
+}
+
+// symbols lists the top-level declarations of the document, with the
+// members of classes and traits as their children.
+func (doc *document) symbols() []DocumentSymbol {
+	symbols := []DocumentSymbol{}
+	for _, stmt := range doc.stmts {
+		switch stmt := stmt.(type) {
+		case *ast.Class:
+			class := doc.symbol(stmt.Name, SymbolClass, "")
+			for _, field := range stmt.StaticFields {
+				class.Children = append(class.Children,
+					doc.symbol(field.Name, SymbolField, "static"))
+			}
+			for _, method := range stmt.StaticMethods {
+				class.Children = append(class.Children, doc.memberSymbol(method, "static "))
+			}
+			for _, method := range stmt.Methods {
+				class.Children = append(class.Children, doc.memberSymbol(method, ""))
+			}
+			symbols = append(symbols, class)
+		case *ast.Trait:
+			trait := doc.symbol(stmt.Name, SymbolInterface, "trait")
+			for _, method := range stmt.Methods {
+				trait.Children = append(trait.Children, doc.memberSymbol(method, ""))
+			}
+			symbols = append(symbols, trait)
+		case *ast.Function:
+			symbols = append(symbols,
+				doc.symbol(stmt.Name, SymbolFunction, paramList(stmt)))
+		case *ast.VarInitialized:
+			symbols = append(symbols, doc.symbol(stmt.Name, SymbolVariable, ""))
+		case *ast.VarUninitialized:
+			symbols = append(symbols, doc.symbol(stmt.Name, SymbolVariable, ""))
+		}
+	}
+	return symbols
+}
+
+func (doc *document) memberSymbol(method *ast.Function, static string) DocumentSymbol {
+	switch {
+	case method.Kind == ast.GetterFunction:
+		return doc.symbol(method.Name, SymbolProperty, static+"getter")
+	case method.Kind == ast.SetterFunction:
+		return doc.symbol(method.Name, SymbolProperty, static+"setter")
+	case method.Name.Lexeme() == "init" && static == "":
+		return doc.symbol(method.Name, SymbolConstructor, paramList(method))
+	}
+	return doc.symbol(method.Name, SymbolMethod, static+paramList(method))
+}
+
+func paramList(f *ast.Function) string {
+	sig := signature(f)
+	return sig[strings.Index(sig, "("):]
+}
+
+func (doc *document) symbol(name token.T, kind int, detail string) DocumentSymbol {
+	selection := doc.tokenRange(name)
+	whole := selection
+	if n := doc.indexOf(name); n >= 0 {
+		whole = doc.declarationRange(n)
+	}
+	return DocumentSymbol{
+		Name:           name.Lexeme(),
+		Detail:         detail,
+		Kind:           kind,
+		Range:          whole,
+		SelectionRange: selection,
+	}
+}
+
+// completion offers the names that could be typed at pos: after a `.`, the
+// members of the document's classes and traits; otherwise keywords,
+// natives, and the variables in scope.
+func (doc *document) completion(pos Position, natives map[string]string) []CompletionItem {
+	items := map[string]CompletionItem{}
+	add := func(item CompletionItem) {
+		if _, ok := items[item.Label]; !ok {
+			items[item.Label] = item
+		}
+	}
+
+	if doc.afterDot(pos) {
+		for _, member := range doc.members() {
+			kind := CompletionMethod
+			if member.Kind != ast.OrdinaryFunction {
+				kind = CompletionProperty
+			}
+			add(CompletionItem{member.Name.Lexeme(), kind, ""})
+		}
+	} else {
+		for _, decl := range doc.bindings.Declarations {
+			if doc.inScope(decl, pos) {
+				add(CompletionItem{decl.Name.Lexeme(), completionKind(decl), describe(decl)})
+			}
+		}
+		for name, native := range natives {
+			add(CompletionItem{name, CompletionFunction, native})
+		}
+		for _, keyword := range scan.Keywords() {
+			add(CompletionItem{keyword, CompletionKeyword, ""})
+		}
+	}
+
+	result := make([]CompletionItem, 0, len(items))
+	for _, item := range items {
+		result = append(result, item)
+	}
+	sort.Slice(result, func(a, b int) bool { return result[a].Label < result[b].Label })
+	return result
+}
+
+func completionKind(decl *resolve.Declaration) int {
+	switch decl.Kind {
+	case resolve.FunctionDeclaration:
+		return CompletionFunction
+	case resolve.ClassDeclaration:
+		return CompletionClass
+	case resolve.TraitDeclaration:
+		return CompletionInterface
+	}
+	return CompletionVariable
+}
+
+// afterDot reports whether pos follows a `.`, perhaps with part of a name
+// typed after it.
+func (doc *document) afterDot(pos Position) bool {
+	if pos.Line >= len(doc.lines) {
+		return false
+	}
+	line := doc.lines[pos.Line]
+	units := 0
+	prefix := []rune{}
+	for _, r := range line {
+		if units >= pos.Character {
+			break
+		}
+		prefix = append(prefix, r)
+		units += utf16Len(r)
+	}
+	text := strings.TrimRight(string(prefix), "_abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789")
+	return strings.HasSuffix(text, ".")
+}
+
+// inScope reports whether decl is visible at pos. Globals are visible
+// everywhere; a local is visible after its declaration, to the end of the
+// innermost braces around it (for a parameter, the function's body).
+func (doc *document) inScope(decl *resolve.Declaration, pos Position) bool {
+	if decl.Global {
+		return true
+	}
+	n := doc.indexOf(decl.Name)
+	if n < 0 {
+		return false
+	}
+	declPos := doc.tokenRange(decl.Name).End
+	if before(pos, declPos) {
+		return false
+	}
+
+	var open int
+	if decl.Kind == resolve.ParameterDeclaration {
+		for open = n; open < len(doc.tokens) && doc.tokens[open].Type() != token.LeftBrace; open++ {
+		}
+	} else {
+		depth := 0
+		for open = n - 1; open >= 0; open-- {
+			switch doc.tokens[open].Type() {
+			case token.RightBrace:
+				depth++
+			case token.LeftBrace:
+				depth--
+			}
+			if depth < 0 {
+				break
+			}
+		}
+	}
+	if open < 0 || open >= len(doc.tokens) {
+		return true // at the top level of a block-less scope
+	}
+	closing := doc.matchingBrace(open)
+	return !before(doc.tokenRange(doc.tokens[closing]).Start, pos)
+}
@@ -0,0 +1,268 @@
+// Package lsp implements a Language Server Protocol server for Lox, as run
+// by `go-lox lsp`. It keeps each open document analyzed by the scanner,
+// parser and resolver, publishing their errors as diagnostics, and answers
+// requests for definitions, references, hovers, document symbols and
+// completions from the resolver's bindings.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/perlmonger42/go-lox/config"
+	"github.com/perlmonger42/go-lox/interpret"
+	"github.com/perlmonger42/go-lox/lox"
+	"github.com/perlmonger42/go-lox/token"
+	"github.com/perlmonger42/go-lox/wire"
+)
+
+type Server struct {
+	in  *bufio.Reader
+	out io.Writer
+	log io.Writer
+
+	docs         map[string]*document
+	natives      map[string]string // name -> description, for hover and completion
+	initialized  bool
+	shuttingDown bool
+}
+
+// NewServer returns a server that reads messages from in and writes them to
+// out. Problems with the connection itself are logged to log.
+func NewServer(in io.Reader, out io.Writer, log io.Writer) *Server {
+	return &Server{
+		in:      bufio.NewReader(in),
+		out:     out,
+		log:     log,
+		docs:    make(map[string]*document),
+		natives: nativeDescriptions(),
+	}
+}
+
+// nativeDescriptions describes the globals that the interpreter predefines.
+func nativeDescriptions() map[string]string {
+	config := config.New()
+	config.Stdin = nil
+	interpreter := interpret.New(lox.New(config))
+	natives := map[string]string{}
+	for _, name := range interpreter.GlobalNames() {
+		tok := token.New(token.Identifier, name, nil, token.NewPos(0))
+		if value, err := interpreter.GetGlobalEnvironment().GetLocal(tok); err == nil {
+			natives[name] = value.String()
+		}
+	}
+	return natives
+}
+
+// Serve handles messages until the client sends `exit` or closes the
+// connection. It returns an error if the client exits without first asking
+// the server to shut down.
+func (s *Server) Serve() error {
+	for {
+		content, err := wire.ReadMessage(s.in)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(content, &req); err != nil {
+			s.replyError(nil, &ResponseError{codeParseError, err.Error()})
+			continue
+		}
+		if req.Method == "exit" {
+			if !s.shuttingDown {
+				return fmt.Errorf("exit without shutdown")
+			}
+			return nil
+		}
+		s.handle(&req)
+	}
+}
+
+func (s *Server) handle(req *request) {
+	if req.ID == nil {
+		s.handleNotification(req)
+		return
+	}
+
+	var result interface{}
+	var err *ResponseError
+	switch {
+	case req.Method == "initialize":
+		s.initialized = true
+		result = s.initializeResult()
+	case !s.initialized:
+		err = &ResponseError{codeServerNotInitialized, "server not initialized"}
+	case s.shuttingDown:
+		err = &ResponseError{codeInvalidRequest, "server is shutting down"}
+	case req.Method == "shutdown":
+		s.shuttingDown = true
+	default:
+		result, err = s.handleRequest(req)
+	}
+	if err != nil {
+		s.replyError(req.ID, err)
+	} else {
+		s.send(&response{JSONRPC: "2.0", ID: *req.ID, Result: result})
+	}
+}
+
+func (s *Server) initializeResult() *InitializeResult {
+	result := &InitializeResult{
+		Capabilities: ServerCapabilities{
+			TextDocumentSync:       1,
+			DefinitionProvider:     true,
+			ReferencesProvider:     true,
+			HoverProvider:          true,
+			DocumentSymbolProvider: true,
+			CompletionProvider:     CompletionOptions{[]string{"."}},
+		},
+	}
+	result.ServerInfo.Name = "go-lox"
+	return result
+}
+
+func (s *Server) handleRequest(req *request) (interface{}, *ResponseError) {
+	switch req.Method {
+	case "textDocument/definition":
+		var params TextDocumentPositionParams
+		doc, err := s.document(req, &params, &params.TextDocument)
+		if err != nil {
+			return nil, err
+		}
+		return doc.definition(params.Position), nil
+
+	case "textDocument/references":
+		var params ReferenceParams
+		doc, err := s.document(req, &params, &params.TextDocument)
+		if err != nil {
+			return nil, err
+		}
+		return doc.references(params.Position, params.Context.IncludeDeclaration), nil
+
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		doc, err := s.document(req, &params, &params.TextDocument)
+		if err != nil {
+			return nil, err
+		}
+		if hover := doc.hover(params.Position, s.natives); hover != nil {
+			return hover, nil
+		}
+		return nil, nil
+
+	case "textDocument/documentSymbol":
+		var params DocumentSymbolParams
+		doc, err := s.document(req, &params, &params.TextDocument)
+		if err != nil {
+			return nil, err
+		}
+		return doc.symbols(), nil
+
+	case "textDocument/completion":
+		var params TextDocumentPositionParams
+		doc, err := s.document(req, &params, &params.TextDocument)
+		if err != nil {
+			return nil, err
+		}
+		return doc.completion(params.Position, s.natives), nil
+	}
+	return nil, &ResponseError{codeMethodNotFound,
+		fmt.Sprintf("method not supported: %s", req.Method)}
+}
+
+// document decodes the request's params and returns the open document they
+// name in textDocument.
+func (s *Server) document(
+	req *request,
+	params interface{},
+	textDocument *TextDocumentIdentifier,
+) (*document, *ResponseError) {
+	if err := json.Unmarshal(req.Params, params); err != nil {
+		return nil, &ResponseError{codeInvalidParams, err.Error()}
+	}
+	doc, ok := s.docs[textDocument.URI]
+	if !ok {
+		return nil, &ResponseError{codeInvalidParams,
+			fmt.Sprintf("document not open: %s", textDocument.URI)}
+	}
+	return doc, nil
+}
+
+func (s *Server) handleNotification(req *request) {
+	if !s.initialized {
+		return
+	}
+	switch req.Method {
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if s.decode(req, &params) {
+			item := params.TextDocument
+			s.update(newDocument(item.URI, item.Version, item.Text))
+		}
+
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if s.decode(req, &params) && len(params.ContentChanges) > 0 {
+			// With full synchronization, the last change is the whole text.
+			text := params.ContentChanges[len(params.ContentChanges)-1].Text
+			doc := params.TextDocument
+			s.update(newDocument(doc.URI, doc.Version, text))
+		}
+
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if s.decode(req, &params) {
+			delete(s.docs, params.TextDocument.URI)
+			s.send(&notification{"2.0", "textDocument/publishDiagnostics",
+				&PublishDiagnosticsParams{
+					URI:         params.TextDocument.URI,
+					Diagnostics: []Diagnostic{},
+				}})
+		}
+	}
+}
+
+func (s *Server) decode(req *request, params interface{}) bool {
+	if err := json.Unmarshal(req.Params, params); err != nil {
+		fmt.Fprintf(s.log, "go-lox lsp: %s: %s\n", req.Method, err)
+		return false
+	}
+	return true
+}
+
+// update replaces a document with its new analysis and publishes its
+// diagnostics.
+func (s *Server) update(doc *document) {
+	s.docs[doc.uri] = doc
+	s.send(&notification{"2.0", "textDocument/publishDiagnostics",
+		&PublishDiagnosticsParams{
+			URI:         doc.uri,
+			Version:     doc.version,
+			Diagnostics: doc.diagnostics,
+		}})
+}
+
+func (s *Server) replyError(id *json.RawMessage, err *ResponseError) {
+	rawID := json.RawMessage("null")
+	if id != nil {
+		rawID = *id
+	}
+	s.send(&errorResponse{JSONRPC: "2.0", ID: rawID, Error: err})
+}
+
+func (s *Server) send(message interface{}) {
+	if err := wire.WriteMessage(s.out, message); err != nil {
+		fmt.Fprintf(s.log, "go-lox lsp: %s\n", err)
+	}
+}
+
+// Run serves LSP over standard input and output.
+func Run() error {
+	return NewServer(os.Stdin, os.Stdout, os.Stderr).Serve()
+}
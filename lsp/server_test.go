@@ -0,0 +1,219 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/perlmonger42/go-lox/wire"
+)
+
+// A testClient drives a Server through in-memory pipes, as an editor
+// would, printing what the server sends back.
+type testClient struct {
+	toServer *io.PipeWriter
+	messages chan []byte // everything the server sends, in order
+	done     chan error  // the result of Serve
+	nextID   int
+}
+
+func newTestClient() *testClient {
+	clientOut, serverIn := io.Pipe()
+	serverOut, clientIn := io.Pipe()
+	c := &testClient{
+		toServer: serverIn,
+		messages: make(chan []byte, 100),
+		done:     make(chan error, 1),
+	}
+	go func() {
+		c.done <- NewServer(clientOut, clientIn, io.Discard).Serve()
+		clientIn.Close()
+	}()
+	go func() {
+		r := bufio.NewReader(serverOut)
+		for {
+			content, err := wire.ReadMessage(r)
+			if err != nil {
+				close(c.messages)
+				return
+			}
+			c.messages <- content
+		}
+	}()
+	c.request("initialize", map[string]interface{}{}, false)
+	c.notify("initialized", map[string]interface{}{})
+	return c
+}
+
+func (c *testClient) notify(method string, params interface{}) {
+	wire.WriteMessage(c.toServer, &notification{"2.0", method, params})
+}
+
+// request sends a request, then prints the messages the server sends up to
+// and including its response (whose result is printed only if show is set).
+// It returns the result.
+func (c *testClient) request(method string, params interface{}, show bool) json.RawMessage {
+	c.nextID++
+	id := json.RawMessage(fmt.Sprint(c.nextID))
+	wire.WriteMessage(c.toServer, &request{"2.0", &id, method, mustMarshal(params)})
+	for content := range c.messages {
+		var msg struct {
+			ID     *json.RawMessage `json:"id"`
+			Method string           `json:"method"`
+			Params json.RawMessage  `json:"params"`
+			Result json.RawMessage  `json:"result"`
+			Error  json.RawMessage  `json:"error"`
+		}
+		json.Unmarshal(content, &msg)
+		if msg.ID == nil {
+			fmt.Printf("%s %s\n", msg.Method, msg.Params)
+			continue
+		}
+		if msg.Error != nil {
+			fmt.Printf("error %s\n", msg.Error)
+		} else if show {
+			fmt.Printf("%s\n", msg.Result)
+		}
+		return msg.Result
+	}
+	return nil
+}
+
+// at makes the params of a request about a position in a document.
+func at(uri string, line, character int) map[string]interface{} {
+	return map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+		"position":     Position{line, character},
+		"context":      map[string]interface{}{"includeDeclaration": true},
+	}
+}
+
+func (c *testClient) open(uri string, text string) {
+	c.notify("textDocument/didOpen", map[string]interface{}{
+		"textDocument": TextDocumentItem{uri, "lox", 1, text},
+	})
+}
+
+func (c *testClient) change(uri string, version int, text string) {
+	c.notify("textDocument/didChange", map[string]interface{}{
+		"textDocument":   VersionedTextDocumentIdentifier{uri, version},
+		"contentChanges": []map[string]string{{"text": text}},
+	})
+}
+
+// shutdown ends the session, printing anything not yet printed and the
+// result of Serve.
+func (c *testClient) shutdown() {
+	c.request("shutdown", nil, false)
+	c.notify("exit", nil)
+	fmt.Println("serve:", <-c.done)
+}
+
+func mustMarshal(v interface{}) json.RawMessage {
+	content, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return content
+}
+
+const program = `var greeting = "hi";
+fun greet(name) {
+  print greeting + name;
+}
+class Greeter < Base {
+  init(name) { this.name = name; }
+  hello() { greet(this.name); }
+  loud { return true; }
+}
+greet("lox");
+`
+
+func ExampleServer_diagnostics() {
+	c := newTestClient()
+	c.open("file:///a.lox", "var x = 1;\nprint x +;\n")
+	c.change("file:///a.lox", 2, "var x = 1;\nprint x;\n")
+	c.change("file:///a.lox", 3, "fun f() { return; }\nreturn 1;\n")
+	c.shutdown()
+	// Output:
+	// textDocument/publishDiagnostics {"uri":"file:///a.lox","version":1,"diagnostics":[{"range":{"start":{"line":1,"character":9},"end":{"line":1,"character":10}},"severity":1,"source":"go-lox","message":"Expect expression, found `;`."}]}
+	// textDocument/publishDiagnostics {"uri":"file:///a.lox","version":2,"diagnostics":[]}
+	// textDocument/publishDiagnostics {"uri":"file:///a.lox","version":3,"diagnostics":[{"range":{"start":{"line":1,"character":0},"end":{"line":1,"character":6}},"severity":1,"source":"go-lox","message":"Cannot return from top-level code."}]}
+	// serve: <nil>
+}
+
+func ExampleServer_definitionAndReferences() {
+	c := newTestClient()
+	c.open("file:///a.lox", program)
+	c.request("textDocument/definition", at("file:///a.lox", 9, 1), true)  // greet("lox")
+	c.request("textDocument/definition", at("file:///a.lox", 2, 20), true) // name in body
+	c.request("textDocument/definition", at("file:///a.lox", 6, 22), true) // this.name
+	c.request("textDocument/references", at("file:///a.lox", 1, 5), true)  // fun greet
+	c.shutdown()
+	// Output:
+	// textDocument/publishDiagnostics {"uri":"file:///a.lox","version":1,"diagnostics":[]}
+	// [{"uri":"file:///a.lox","range":{"start":{"line":1,"character":4},"end":{"line":1,"character":9}}}]
+	// [{"uri":"file:///a.lox","range":{"start":{"line":1,"character":10},"end":{"line":1,"character":14}}}]
+	// []
+	// [{"uri":"file:///a.lox","range":{"start":{"line":1,"character":4},"end":{"line":1,"character":9}}},{"uri":"file:///a.lox","range":{"start":{"line":6,"character":12},"end":{"line":6,"character":17}}},{"uri":"file:///a.lox","range":{"start":{"line":9,"character":0},"end":{"line":9,"character":5}}}]
+	// serve: <nil>
+}
+
+func ExampleServer_hover() {
+	c := newTestClient()
+	c.open("file:///a.lox", program)
+	c.request("textDocument/hover", at("file:///a.lox", 2, 9), true)  // greeting
+	c.request("textDocument/hover", at("file:///a.lox", 2, 20), true) // name
+	c.request("textDocument/hover", at("file:///a.lox", 4, 7), true)  // Greeter
+	c.request("textDocument/hover", at("file:///a.lox", 3, 0), true)  // }
+	c.shutdown()
+	// Output:
+	// textDocument/publishDiagnostics {"uri":"file:///a.lox","version":1,"diagnostics":[]}
+	// {"contents":{"kind":"markdown","value":"```lox\nvar greeting = \"hi\"\n```"},"range":{"start":{"line":2,"character":8},"end":{"line":2,"character":16}}}
+	// {"contents":{"kind":"markdown","value":"```lox\n(parameter) name of greet(name)\n```"},"range":{"start":{"line":2,"character":19},"end":{"line":2,"character":23}}}
+	// {"contents":{"kind":"markdown","value":"```lox\nclass Greeter < Base\n```"},"range":{"start":{"line":4,"character":6},"end":{"line":4,"character":13}}}
+	// null
+	// serve: <nil>
+}
+
+func ExampleServer_documentSymbols() {
+	c := newTestClient()
+	c.open("file:///a.lox", program)
+	c.request("textDocument/documentSymbol", map[string]interface{}{
+		"textDocument": TextDocumentIdentifier{"file:///a.lox"},
+	}, true)
+	c.shutdown()
+	// Output:
+	// textDocument/publishDiagnostics {"uri":"file:///a.lox","version":1,"diagnostics":[]}
+	// [{"name":"greeting","kind":13,"range":{"start":{"line":0,"character":0},"end":{"line":0,"character":20}},"selectionRange":{"start":{"line":0,"character":4},"end":{"line":0,"character":12}}},{"name":"greet","detail":"(name)","kind":12,"range":{"start":{"line":1,"character":0},"end":{"line":3,"character":1}},"selectionRange":{"start":{"line":1,"character":4},"end":{"line":1,"character":9}}},{"name":"Greeter","kind":5,"range":{"start":{"line":4,"character":0},"end":{"line":8,"character":1}},"selectionRange":{"start":{"line":4,"character":6},"end":{"line":4,"character":13}},"children":[{"name":"init","detail":"(name)","kind":9,"range":{"start":{"line":5,"character":2},"end":{"line":5,"character":34}},"selectionRange":{"start":{"line":5,"character":2},"end":{"line":5,"character":6}}},{"name":"hello","detail":"()","kind":6,"range":{"start":{"line":6,"character":2},"end":{"line":6,"character":31}},"selectionRange":{"start":{"line":6,"character":2},"end":{"line":6,"character":7}}},{"name":"loud","detail":"getter","kind":7,"range":{"start":{"line":7,"character":2},"end":{"line":7,"character":23}},"selectionRange":{"start":{"line":7,"character":2},"end":{"line":7,"character":6}}}]}]
+	// serve: <nil>
+}
+
+func ExampleServer_completion() {
+	c := newTestClient()
+	c.open("file:///a.lox", "var top = 1;\nfun f(param) {\n  var local = 2;\n  \n}\n\nvar obj = Greeter();\nobj.h;\nclass Greeter { hello() {} }\n")
+	labels := func(line, character int) {
+		result := c.request("textDocument/completion", at("file:///a.lox", line, character), false)
+		var items []CompletionItem
+		json.Unmarshal(result, &items)
+		names := []string{}
+		for _, item := range items {
+			if item.Kind != CompletionKeyword && item.Kind != CompletionFunction {
+				names = append(names, item.Label)
+			}
+		}
+		fmt.Println(strings.Join(names, " "))
+	}
+	labels(3, 2)
+	labels(5, 0)
+	labels(7, 4)
+	c.shutdown()
+	// Output:
+	// textDocument/publishDiagnostics {"uri":"file:///a.lox","version":1,"diagnostics":[{"range":{"start":{"line":1,"character":6},"end":{"line":1,"character":11}},"severity":2,"source":"go-lox","message":"Parameter `param` is never used. (unused)"},{"range":{"start":{"line":2,"character":6},"end":{"line":2,"character":11}},"severity":2,"source":"go-lox","message":"Local variable `local` is never used. (unused)"}]}
+	// Greeter local obj param top
+	// Greeter obj top
+	// hello
+	// serve: <nil>
+}
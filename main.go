@@ -11,22 +11,59 @@ import (
 	"github.com/bobappleyard/readline"
 
 	"github.com/perlmonger42/go-lox/ast"
+	"github.com/perlmonger42/go-lox/astio"
 	"github.com/perlmonger42/go-lox/config"
+	"github.com/perlmonger42/go-lox/cover"
+	"github.com/perlmonger42/go-lox/dap"
+	"github.com/perlmonger42/go-lox/golden"
 	"github.com/perlmonger42/go-lox/interpret"
 	"github.com/perlmonger42/go-lox/lox"
+	"github.com/perlmonger42/go-lox/lsp"
+	"github.com/perlmonger42/go-lox/optimize"
 	"github.com/perlmonger42/go-lox/parse"
+	"github.com/perlmonger42/go-lox/profile"
 	"github.com/perlmonger42/go-lox/resolve"
 	"github.com/perlmonger42/go-lox/scan"
 	"github.com/perlmonger42/go-lox/token"
+	"github.com/perlmonger42/go-lox/typecheck"
 )
 
 var (
 	execute = flag.Bool("e", false, "execute arguments as a program")
-	testing = flag.Bool("test", false, "execute Read Eval Read Compare Loop")
+	testing = flag.Bool("test", false,
+		"run the files and directories given as golden tests, checking their expect comments")
+	sandbox = flag.Bool("sandbox", false,
+		"deny natives that read stdin, files, environment or arguments")
+	profileFile = flag.String("profile", "",
+		"write a pprof profile of the program to `file`, and a report to stderr")
+	coverProfile = flag.String("coverprofile", "",
+		"write LCOV coverage of the program to `file`, and HTML to file.html")
+	dumpAST = flag.String("dump-ast", "",
+		"print the program's syntax tree in `format` json, sexpr or dot, instead of running it")
+	warn = flag.Bool("warn", false,
+		"warn about unused variables and parameters, shadowing, and unreachable code")
+	optimizing = flag.Bool("O", false,
+		"fold constants and remove dead code before running the program")
+	dumpOptimized = flag.String("dump-optimized", "",
+		"print the program's syntax tree in `format` (as for -dump-ast) after optimizing it, instead of running it")
 )
 
+// astWriters are the writers of the formats of -dump-ast and -dump-optimized.
+var astWriters = map[string]func(io.Writer, []ast.Stmt) error{
+	"json":  astio.WriteJSON,
+	"sexpr": astio.WriteSExpr,
+	"dot":   astio.WriteDot,
+}
+
 func usage() {
-	fmt.Fprintf(os.Stderr, "usage: go-lox [options] [file]\n")
+	fmt.Fprintf(os.Stderr, "usage: go-lox [options] [file [args...]]\n")
+	fmt.Fprintf(os.Stderr, "       go-lox -test [-sandbox] files or directories...\n")
+	fmt.Fprintf(os.Stderr, "       go-lox fmt [-w] [-d] [files...]\n")
+	fmt.Fprintf(os.Stderr, "       go-lox test [-v] [-run regexp] [files or directories...]\n")
+	fmt.Fprintf(os.Stderr, "       go-lox lint [-config file] [-list] files...\n")
+	fmt.Fprintf(os.Stderr, "       go-lox lsp\n")
+	fmt.Fprintf(os.Stderr, "       go-lox debug file [args...]\n")
+	fmt.Fprintf(os.Stderr, "       go-lox dap\n")
 	fmt.Fprintf(os.Stderr, "Flags:\n")
 	flag.PrintDefaults()
 	os.Exit(64) // see "sysexits.h"
@@ -35,7 +72,52 @@ func usage() {
 func main() {
 	flag.Usage = usage
 	flag.Parse()
+	if *profileFile != "" && *coverProfile != "" {
+		fmt.Fprintf(os.Stderr, "go-lox: cannot use -profile with -coverprofile\n")
+		usage()
+	}
+	if _, ok := astWriters[*dumpAST]; *dumpAST != "" && !ok {
+		fmt.Fprintf(os.Stderr, "go-lox: unknown -dump-ast format %q\n", *dumpAST)
+		usage()
+	}
+	if _, ok := astWriters[*dumpOptimized]; *dumpOptimized != "" && !ok {
+		fmt.Fprintf(os.Stderr, "go-lox: unknown -dump-optimized format %q\n", *dumpOptimized)
+		usage()
+	}
+	if flag.NArg() > 0 {
+		switch flag.Arg(0) {
+		case "fmt":
+			os.Exit(fmtCommand(flag.Args()[1:]))
+		case "test":
+			os.Exit(testCommand(flag.Args()[1:]))
+		case "lint":
+			os.Exit(lintCommand(flag.Args()[1:]))
+		case "debug":
+			os.Exit(debugCommand(flag.Args()[1:]))
+		case "dap":
+			if err := dap.Run(); err != nil {
+				fmt.Fprintf(os.Stderr, "go-lox dap: %s\n", err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+		case "lsp":
+			if err := lsp.Run(); err != nil {
+				fmt.Fprintf(os.Stderr, "go-lox lsp: %s\n", err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+		}
+	}
+	capabilities := config.AllCapabilities
+	if *sandbox {
+		capabilities = config.NoCapabilities
+	}
+	if *testing {
+		os.Exit(runTests(flag.Args(), capabilities))
+	}
 	config := config.New()
+	config.Capabilities = capabilities
+	config.Warnings = *warn
 	lox := lox.New(config)
 
 	if *execute {
@@ -44,14 +126,31 @@ func main() {
 	} else if flag.NArg() == 0 {
 		lox.Interactive = true
 		runPrompt(lox)
-	} else if flag.NArg() == 1 {
-		runFile(lox, config, flag.Arg(0))
 	} else {
-		usage()
+		config.Args = flag.Args()[1:]
+		runFile(lox, config, flag.Arg(0))
 	}
 	os.Exit(0)
 }
 
+// runTests runs golden tests, returning the process's exit status: 1 if any
+// test failed.
+func runTests(paths []string, capabilities config.Capability) int {
+	if len(paths) == 0 {
+		usage()
+	}
+	summary, err := golden.Run(os.Stdout, paths, capabilities)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "go-lox: %s\n", err)
+		return 66 // see "sysexits.h"
+	}
+	fmt.Printf("%s\n", summary)
+	if summary.Failed > 0 {
+		return 1
+	}
+	return 0
+}
+
 func ConsoleReadline(config *config.T) (string, error) {
 	prompt := config.Prompt
 	if prompt == "" {
@@ -114,14 +213,46 @@ func runText(lox *lox.T, text string) {
 	if lox.HadError {
 		return
 	}
+	if *dumpAST != "" {
+		if err := astWriters[*dumpAST](os.Stdout, stmts); err != nil {
+			fmt.Fprintf(os.Stderr, "go-lox: %s\n", err)
+			os.Exit(74) // see "sysexits.h"
+		}
+		return
+	}
 
 	var interpreter interpret.T = interpret.New(lox)
 
 	var resolver *resolve.T = resolve.New(lox, interpreter)
+	resolver.Bindings = resolve.NewBindings()
 	resolver.ResolveStmtList(stmts)
 	if lox.HadError {
 		return
 	}
+	typecheck.Check(lox, stmts, resolver.Bindings)
+	if lox.HadError {
+		return
+	}
+	if *optimizing || *dumpOptimized != "" {
+		stmts = optimize.Optimize(stmts)
+	}
+	if *dumpOptimized != "" {
+		if err := astWriters[*dumpOptimized](os.Stdout, stmts); err != nil {
+			fmt.Fprintf(os.Stderr, "go-lox: %s\n", err)
+			os.Exit(74) // see "sysexits.h"
+		}
+		return
+	}
+
+	var profiler *profile.Profiler
+	var coverage *cover.Profile
+	if *profileFile != "" && !lox.Interactive {
+		profiler = profile.New(nil)
+		interpreter.SetHook(profiler)
+	} else if *coverProfile != "" && !lox.Interactive {
+		coverage = cover.New(stmts)
+		interpreter.SetHook(coverage)
+	}
 
 	fmt.Printf("running interpreter\n")
 	//err := interpreter.InterpretStmts2(stmts)
@@ -129,4 +260,66 @@ func runText(lox *lox.T, text string) {
 	//	fmt.Printf("error: (%s) %s\n", err, err)
 	//}
 	interpreter.InterpretStmts(stmts)
+
+	if profiler != nil {
+		writeProfile(profiler, text)
+	}
+	if coverage != nil {
+		writeCoverage(coverage, text)
+	}
+}
+
+// writeProfile reports on a profiled run to stderr, and writes its pprof
+// profile to the file named by -profile.
+func writeProfile(profiler *profile.Profiler, text string) {
+	profiler.Stop()
+	profiler.WriteReport(os.Stderr, text)
+
+	err := writeFile(*profileFile, func(w io.Writer) error {
+		return profiler.WriteProfile(w, sourceName())
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "go-lox: writing profile: %s\n", err)
+		os.Exit(73) // see "sysexits.h"
+	}
+}
+
+// writeCoverage summarizes a run's coverage on stderr, and writes it to the
+// file named by -coverprofile in LCOV format, and to that name plus ".html"
+// as annotated source.
+func writeCoverage(coverage *cover.Profile, text string) {
+	fmt.Fprintf(os.Stderr, "%s\n", coverage.Summary())
+	err := writeFile(*coverProfile, func(w io.Writer) error {
+		return coverage.WriteLCOV(w, sourceName())
+	})
+	if err == nil {
+		err = writeFile(*coverProfile+".html", func(w io.Writer) error {
+			return coverage.WriteHTML(w, sourceName(), text)
+		})
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "go-lox: writing coverage: %s\n", err)
+		os.Exit(73) // see "sysexits.h"
+	}
+}
+
+// sourceName returns the name of the program's source file.
+func sourceName() string {
+	if *execute {
+		return "<command line>"
+	}
+	return flag.Arg(0)
+}
+
+// writeFile creates a file, and writes it with write.
+func writeFile(filename string, write func(w io.Writer) error) error {
+	out, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	err = write(out)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	return err
 }
@@ -0,0 +1,198 @@
+// Package optimize rewrites a resolved Lox program into a simpler one that
+// does the same thing, for `go-lox -O`:
+//
+//   - Operators whose operands are literals are folded into literals, as in
+//     `60 * 60 * 24` to `86400`, unless applying them would be an error.
+//   - An `if` whose condition is a literal is replaced by the branch it
+//     would take, and a `while` whose condition is a false literal is
+//     removed.
+//   - Statements that do nothing are removed: empty statements and blocks,
+//     and expression statements whose expressions are literals.
+//
+// The program is rewritten in place. Since the nodes of variables are kept,
+// what the resolver recorded about them still holds; but the literals made
+// by folding have no tokens, so they have no line numbers.
+package optimize
+
+import (
+	"github.com/perlmonger42/go-lox/ast"
+	"github.com/perlmonger42/go-lox/token"
+)
+
+// Optimize optimizes a program, returning its remaining statements.
+func Optimize(stmts []ast.Stmt) []ast.Stmt {
+	block := &ast.Block{Statements: stmts}
+	ast.Apply(block, nil, rewrite)
+	return block.Statements
+}
+
+// rewrite simplifies a node whose children have been simplified.
+func rewrite(c *ast.Cursor) bool {
+	switch n := c.Node().(type) {
+	case ast.Expr:
+		if folded := fold(n); folded != nil {
+			c.Replace(folded)
+		}
+	case ast.Stmt:
+		stmt := simplify(n)
+		if isNoop(stmt) && c.Index() >= 0 {
+			c.Delete()
+		} else if stmt != n {
+			c.Replace(stmt)
+		}
+	}
+	return true
+}
+
+// fold returns the literal, or the operand, that expr always evaluates
+// to, or nil if there is none.
+func fold(expr ast.Expr) ast.Expr {
+	switch expr := expr.(type) {
+	case *ast.Grouping:
+		if lit, ok := expr.Expression.(*ast.Literal); ok {
+			return lit
+		}
+	case *ast.Unary:
+		right, ok := literal(expr.Right)
+		if !ok {
+			return nil
+		}
+		switch expr.Operator.Type() {
+		case token.Minus:
+			if r, ok := right.(token.NumberValue); ok {
+				return &ast.Literal{Value: token.NumberValue{V: -r.V}}
+			}
+		case token.Bang:
+			switch r := right.(type) {
+			case token.BooleanValue:
+				return &ast.Literal{Value: token.BooleanValue{V: !r.V}}
+			case token.NilValue:
+				return &ast.Literal{Value: token.BooleanValue{V: true}}
+			}
+		}
+	case *ast.Binary:
+		left, ok := literal(expr.Left)
+		right, ok2 := literal(expr.Right)
+		if !ok || !ok2 {
+			return nil
+		}
+		if value := binary(expr.Operator.Type(), left, right); value != nil {
+			return &ast.Literal{Value: value}
+		}
+	case *ast.Logical:
+		left, ok := literal(expr.Left)
+		if !ok {
+			return nil
+		}
+		// `a or b` is a if a is truthy, and b otherwise; `a and b` is a if
+		// a is falsy.
+		if token.IsTruthy(left) == (expr.Operator.Type() == token.Or) {
+			return expr.Left
+		}
+		return expr.Right
+	}
+	return nil
+}
+
+// binary returns the value of applying a binary operator to literal
+// values, or nil if applying it would be an error.
+func binary(op token.Type, left, right token.Value) token.Value {
+	switch op {
+	case token.EqualEqual:
+		return token.BooleanValue{V: left.IsEqualTo(right)}
+	case token.BangEqual:
+		return token.BooleanValue{V: !left.IsEqualTo(right)}
+	}
+	switch l := left.(type) {
+	case token.NumberValue:
+		r, ok := right.(token.NumberValue)
+		if !ok {
+			return nil
+		}
+		switch op {
+		case token.Plus:
+			return token.NumberValue{V: l.V + r.V}
+		case token.Minus:
+			return token.NumberValue{V: l.V - r.V}
+		case token.Star:
+			return token.NumberValue{V: l.V * r.V}
+		case token.Slash:
+			return token.NumberValue{V: l.V / r.V}
+		case token.Greater:
+			return token.BooleanValue{V: l.V > r.V}
+		case token.GreaterEqual:
+			return token.BooleanValue{V: l.V >= r.V}
+		case token.Less:
+			return token.BooleanValue{V: l.V < r.V}
+		case token.LessEqual:
+			return token.BooleanValue{V: l.V <= r.V}
+		}
+	case token.StringValue:
+		if _, ok := right.(token.NilValue); ok && op == token.Plus {
+			return token.StringValue{V: l.V + "{([<nil>])}"}
+		}
+		r, ok := right.(token.StringValue)
+		if !ok {
+			return nil
+		}
+		switch op {
+		case token.Plus:
+			return token.StringValue{V: l.V + r.V}
+		case token.Greater:
+			return token.BooleanValue{V: l.V > r.V}
+		case token.GreaterEqual:
+			return token.BooleanValue{V: l.V >= r.V}
+		case token.Less:
+			return token.BooleanValue{V: l.V < r.V}
+		case token.LessEqual:
+			return token.BooleanValue{V: l.V <= r.V}
+		}
+	}
+	return nil
+}
+
+// simplify returns a simpler statement doing what stmt does, or stmt.
+func simplify(stmt ast.Stmt) ast.Stmt {
+	switch stmt := stmt.(type) {
+	case *ast.Expression:
+		if _, ok := stmt.Expression.(*ast.Literal); ok {
+			return &ast.Noop{}
+		}
+	case *ast.Block:
+		if len(stmt.Statements) == 0 {
+			return &ast.Noop{}
+		}
+	case *ast.If:
+		if cond, ok := literal(stmt.Condition); ok {
+			if token.IsTruthy(cond) {
+				return stmt.ThenBranch
+			}
+			if stmt.ElseBranch == nil {
+				return &ast.Noop{}
+			}
+			return stmt.ElseBranch
+		}
+		if isNoop(stmt.ThenBranch) && (stmt.ElseBranch == nil || isNoop(stmt.ElseBranch)) {
+			// Only the condition's side effects are left.
+			return &ast.Expression{Expression: stmt.Condition}
+		}
+	case *ast.While:
+		if cond, ok := literal(stmt.Condition); ok && !token.IsTruthy(cond) {
+			return &ast.Noop{}
+		}
+	}
+	return stmt
+}
+
+func isNoop(stmt ast.Stmt) bool {
+	_, ok := stmt.(*ast.Noop)
+	return ok
+}
+
+// literal returns the value of expr, if it is a literal.
+func literal(expr ast.Expr) (token.Value, bool) {
+	if lit, ok := expr.(*ast.Literal); ok {
+		return lit.Value, true
+	}
+	return nil, false
+}
@@ -0,0 +1,106 @@
+package optimize
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/perlmonger42/go-lox/ast"
+	"github.com/perlmonger42/go-lox/config"
+	"github.com/perlmonger42/go-lox/interpret"
+	"github.com/perlmonger42/go-lox/lox"
+	"github.com/perlmonger42/go-lox/parse"
+	"github.com/perlmonger42/go-lox/resolve"
+	"github.com/perlmonger42/go-lox/scan"
+)
+
+// run resolves and runs a program, optimizing it first if optimizing is
+// set, and returns what it printed.
+func run(text string, optimizing bool) string {
+	var out bytes.Buffer
+	config := config.New()
+	config.Stdout = &out
+	lox := lox.New(config)
+	stmts := parse.New(lox, scan.New(lox, text).ScanTokens()).Parse()
+	interpreter := interpret.New(lox)
+	resolve.New(lox, interpreter).ResolveStmtList(stmts)
+	if optimizing {
+		stmts = Optimize(stmts)
+	}
+	interpreter.InterpretStmts(stmts)
+	return out.String()
+}
+
+func ExampleOptimize() {
+	lox := lox.New(config.New())
+	stmts := parse.New(lox, scan.New(lox, `
+var day = 60 * 60 * 24;
+var greeting = ("Hello, " + "world") + "!";
+if (day > 1000) print greeting; else print "short";
+while (false) print "never";
+fun f(x) {
+  "a no-op";
+  if (x) {}
+  {}
+  return -(2 + 3) == -5 and x or !nil;
+}
+print 1 - "x";
+`).ScanTokens()).Parse()
+	for _, stmt := range Optimize(stmts) {
+		fmt.Print(ast.ToString(stmt))
+	}
+	// Output:
+	// var day = 86400;
+	// var greeting = "Hello, world!";
+	// if ((> day 1000))
+	//   print greeting;
+	// else
+	//   print "short";
+	// fun (x) {
+	//   x;
+	//   return (or x true);
+	// }
+	// print (- 1 "x");
+}
+
+func ExampleOptimize_semantics() {
+	programs := []string{`
+var total = 0;
+for (var i = 0; i < 10 * 10; i = i + 1) {
+  if (true) total = total + i * (2 - 1);
+  if (nil) total = -1;
+}
+print total;
+`, `
+var a = "global";
+{
+  fun show() { print a; }
+  show();
+  if (1 == 1) { var a = "block"; show(); print a; }
+  show();
+}
+`, `
+class Counter {
+  init() { this.count = 0 * 5; }
+  add() { this.count = this.count + (1 + 1); return this; }
+}
+print Counter().add().add().count;
+print false and 1 / 0;
+print "n" + nil;
+`}
+	for _, program := range programs {
+		plain, optimized := run(program, false), run(program, true)
+		fmt.Print(optimized)
+		if plain != optimized {
+			fmt.Printf("differs from:\n%s", plain)
+		}
+	}
+	// Output:
+	// 4950
+	// global
+	// global
+	// block
+	// global
+	// 4
+	// false
+	// n{([<nil>])}
+}
@@ -15,8 +15,10 @@ func (p *Parser) newLogical(
 	return logical
 }
 
-func (p *Parser) newGrouping(expr ast.Expr) *ast.Grouping {
-	group := &ast.Grouping{expr}
+func (p *Parser) newGrouping(
+	paren token.T, expr ast.Expr, end token.T,
+) *ast.Grouping {
+	group := &ast.Grouping{expr, paren, end}
 	p.traceNode(group)
 	return group
 }
@@ -43,6 +45,22 @@ func (p *Parser) newSet(expr ast.Expr, name token.T, value ast.Expr) *ast.Set {
 	return set
 }
 
+func (p *Parser) newIndex(
+	expr ast.Expr, bracket token.T, index ast.Expr,
+) *ast.Index {
+	idx := &ast.Index{expr, bracket, index}
+	p.traceNode(idx)
+	return idx
+}
+
+func (p *Parser) newSetIndex(
+	expr ast.Expr, bracket token.T, index ast.Expr, value ast.Expr,
+) *ast.SetIndex {
+	set := &ast.SetIndex{expr, bracket, index, value}
+	p.traceNode(set)
+	return set
+}
+
 func (p *Parser) newUnary(op token.T, right ast.Expr) *ast.Unary {
 	unary := &ast.Unary{op, right}
 	p.traceNode(unary)
@@ -69,8 +87,8 @@ func (p *Parser) newVariable(tok token.T) *ast.Variable {
 	return variable
 }
 
-func (p *Parser) newLiteral(value token.Value) *ast.Literal {
-	literal := &ast.Literal{value}
+func (p *Parser) newLiteral(value token.Value, tok token.T) *ast.Literal {
+	literal := &ast.Literal{value, tok}
 	p.traceNode(literal)
 	return literal
 }
@@ -87,6 +105,12 @@ func (p *Parser) newSuper(keyword, method token.T) *ast.Super {
 	return this
 }
 
+func (p *Parser) newBadExpr(from, to token.T) *ast.BadExpr {
+	bad := &ast.BadExpr{from, to}
+	p.traceNode(bad)
+	return bad
+}
+
 // ===== Parsing =====
 
 // expressionOnly parses an expression as the entire input.
@@ -94,9 +118,10 @@ func (p *Parser) newSuper(keyword, method token.T) *ast.Super {
 func (p *Parser) expressionOnly() (result ast.Expr) {
 	defer func() {
 		if r := recover(); r != nil {
-			if _, ok := r.(ParseError); ok {
+			switch r.(type) {
+			case ParseError, bailout:
 				result = nil
-			} else {
+			default:
 				panic(r)
 			}
 		}
@@ -104,7 +129,7 @@ func (p *Parser) expressionOnly() (result ast.Expr) {
 
 	result = p.expression()
 	if p.peek().Type() != token.EOF {
-		p.Error(p.peek(), "unexpected input after expression")
+		p.found(p.peek(), "Expect end of input after expression.")
 	}
 	return result
 }
@@ -125,6 +150,8 @@ func (p *Parser) assignment() ast.Expr {
 			return p.newAssign(name, value)
 		} else if get, ok := expr.(*ast.Get); ok {
 			return p.newSet(get.Object, get.Name, value)
+		} else if idx, ok := expr.(*ast.Index); ok {
+			return p.newSetIndex(idx.Object, idx.Bracket, idx.Index, value)
 		}
 
 		p.Error(equals, "Invalid assignment target.")
@@ -226,6 +253,10 @@ func (p *Parser) call() ast.Expr {
 			name := p.consume(token.Identifier,
 				"Expect property name after `.`.")
 			expr = p.newGet(expr, name)
+		} else if p.match(token.LeftBrack) {
+			index := p.expression()
+			bracket := p.consume(token.RightBrack, "Expect `]` after index.")
+			expr = p.newIndex(expr, bracket, index)
 		} else {
 			break
 		}
@@ -241,6 +272,9 @@ func (p *Parser) finishCall(callee ast.Expr) ast.Expr {
 		}
 		args = append(args, p.expression())
 		if !p.match(token.Comma) {
+			if !p.check(token.RightParen) {
+				panic(p.expect("after argument", token.Comma, token.RightParen))
+			}
 			break
 		}
 	}
@@ -250,27 +284,27 @@ func (p *Parser) finishCall(callee ast.Expr) ast.Expr {
 
 func (p *Parser) primary() ast.Expr {
 	if p.match(token.False) {
-		return p.newLiteral(token.BooleanValue{false})
+		return p.newLiteral(token.BooleanValue{false}, p.previous())
 	}
 	if p.match(token.True) {
-		return p.newLiteral(token.BooleanValue{true})
+		return p.newLiteral(token.BooleanValue{true}, p.previous())
 	}
 	if p.match(token.Nil) {
-		return p.newLiteral(token.NilValue{})
+		return p.newLiteral(token.NilValue{}, p.previous())
 	}
 	if p.match(token.This) {
 		return p.newThis(p.previous())
 	}
 	if p.match(token.Super) {
 		keyword := p.previous()
-		p.consume(token.Dot, "Expect '.' after 'super'.")
+		p.consume(token.Dot, "Expect `.` after `super`.")
 		method := p.consume(token.Identifier,
 			"Expect superclass method name after `super.`.")
 		return p.newSuper(keyword, method)
 	}
 
 	if p.match(token.Number, token.String) {
-		return p.newLiteral(p.previous().Literal())
+		return p.newLiteral(p.previous().Literal(), p.previous())
 	}
 
 	if p.match(token.Identifier) {
@@ -278,10 +312,34 @@ func (p *Parser) primary() ast.Expr {
 	}
 
 	if p.match(token.LeftParen) {
+		paren := p.previous()
 		var expr ast.Expr = p.expression()
-		p.consume(token.RightParen, "Expect ')' after expression.")
-		return p.newGrouping(expr)
+		end := p.consume(token.RightParen, "Expect `)` after expression.")
+		return p.newGrouping(paren, expr, end)
 	}
 
-	panic(p.Error(p.peek(), "Expect expression."))
+	return p.badExpression()
+}
+
+// badExpression reports that the current token can't start an expression,
+// and returns a BadExpr in place of one, so that parsing can go on. The
+// BadExpr holds the tokens skipped up to one that can follow an expression
+// (as the `;` in `print ;` can) or start a statement; if there are none, it
+// starts and ends at that token.
+func (p *Parser) badExpression() ast.Expr {
+	from := p.peek()
+	p.found(from, "Expect expression.")
+	p.quiet = true
+	to := from
+	for !p.isAtEnd() {
+		switch p.peek().Type() {
+		case token.RightParen, token.RightBrack, token.LeftBrace,
+			token.RightBrace, token.Semicolon, token.Comma,
+			token.Class, token.Trait, token.Fun, token.Var, token.For,
+			token.If, token.While, token.Print, token.Return:
+			return p.newBadExpr(from, to)
+		}
+		to = p.advance()
+	}
+	return p.newBadExpr(from, to)
 }
@@ -26,20 +26,20 @@ func dumpAst(text string) {
 func ExampleEmpty() {
 	dumpAst("")
 	// Output:
-	// [line 1] Error at end: Expect expression.
+	// [line 1] Error at end: Expect expression, found end of file.
 }
 
 func ExampleParens() {
 	dumpAst("()")
 	// Output:
-	// [line 1] Error at 'RightParen': Expect expression.
+	// [line 1] Error at ')': Expect expression, found `)`.
 }
 
 func ExampleUnexpectedInput() {
 	dumpAst("(~")
 	// Output:
-	// [line 1] Error at 'Other': Unexpected character ('~').
-	// [line 1] Error at 'Other': Expect expression.
+	// [line 1] Error at '~': Unexpected character ('~').
+	// [line 1] Error at '~': Expect expression, found unrecognized character `~`.
 }
 
 func ExampleNumber() {
@@ -66,3 +66,9 @@ func ExampleComplex() {
 //	//dumpAst("Ťėšťǐňġ + ṫẹṡṫịṅḡ * 𝕠𝕟𝕖 / 𝕥𝕨𝕠 - -𝕥𝕙𝕣𝕖𝕖")
 //	// Output:
 //}
+
+func ExampleIndex() {
+	dumpAst("a.b[1 + 2][c] = d[e]")
+	// Output:
+	// (a).b[(+ 1 2)][c] = d[e]
+}
@@ -2,6 +2,7 @@ package parse
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/perlmonger42/go-lox/ast"
 	"github.com/perlmonger42/go-lox/lox"
@@ -26,8 +27,25 @@ type Parser struct {
 	lox     *lox.T
 	tokens  []token.T
 	current int // index of current token in tokens[]
+	blocks  int // how many blocks the current token is nested in
+
+	// quiet is set when an expression fails to parse, and is replaced by a
+	// BadExpr. Errors are not reported again until the next declaration,
+	// since they're likely to follow from the first.
+	quiet bool
+
+	errors    int // how many errors have been reported
+	errorLine int // the line of the last one
 }
 
+// maxErrors is how many errors the parser reports before it gives up.
+const maxErrors = 10
+
+// A bailout is panicked to stop parsing after too many errors. It is
+// recovered by program, which returns the statements parsed so far, and by
+// expressionOnly.
+type bailout struct{}
+
 var _ T = &Parser{}
 
 type ParseError struct {
@@ -49,10 +67,77 @@ func (p *Parser) ParseProg() (result []ast.Stmt) { return p.program() }
 func (p *Parser) ParseExpr() (result ast.Expr) { return p.expressionOnly() }
 
 func (p *Parser) Error(tok token.T, message string) ParseError {
-	p.lox.Error(tok, message)
+	if !p.quiet {
+		p.report(tok, message)
+	}
 	return ParseError{tok, message}
 }
 
+// report reports an error, unless another was reported on the same line:
+// after synchronizing, the next error is often caused by the last. After
+// maxErrors errors, it reports that there are too many, and bails out.
+func (p *Parser) report(tok token.T, message string) {
+	line := tok.Whence().Line()
+	if p.errors > 0 && line == p.errorLine {
+		return
+	}
+	p.errors++
+	p.errorLine = line
+	if p.errors > maxErrors {
+		p.lox.Error(tok, "Too many errors.")
+		panic(bailout{})
+	}
+	p.lox.Error(tok, message)
+}
+
+// recoverBailout, deferred, stops a bailout from going any further.
+func recoverBailout() {
+	if r := recover(); r != nil {
+		if _, ok := r.(bailout); !ok {
+			panic(r)
+		}
+	}
+}
+
+// found reports that message's expectation was not met by tok, the current
+// token, saying what was found instead: "Expect `)` after arguments, found
+// `;`." A missing `;` at the end of a line is reported there, rather than
+// at the token on the next line.
+func (p *Parser) found(tok token.T, message string) ParseError {
+	message = strings.TrimSuffix(message, ".")
+	if p.current > 0 && p.tokens[p.current-1].Whence().Line() < tok.Whence().Line() &&
+		strings.HasPrefix(message, "Expect `;`") {
+		return p.Error(p.previous(), message+", found end of line.")
+	}
+	return p.Error(tok, message+", found "+describe(tok)+".")
+}
+
+// expect reports that the current token is none of types, which could
+// have come next where context says: "Expect `,` or `)` after argument,
+// found `;`."
+func (p *Parser) expect(context string, types ...token.Type) ParseError {
+	names := make([]string, len(types))
+	for i, typ := range types {
+		names[i] = typ.Describe()
+	}
+	list := names[len(names)-1]
+	if len(names) > 1 {
+		list = strings.Join(names[:len(names)-1], ", ") + " or " + list
+	}
+	return p.found(p.peek(), "Expect "+list+" "+context+".")
+}
+
+// describe returns what error messages call a token: its type's
+// description, followed by its text if that is not implied.
+func describe(tok token.T) string {
+	switch tok.Type() {
+	case token.String, token.InvalidString, token.Number,
+		token.InvalidNumber, token.Identifier, token.Other:
+		return tok.Type().Describe() + " `" + tok.Lexeme() + "`"
+	}
+	return tok.Type().Describe()
+}
+
 func (p *Parser) traceToken() {
 	if p.lox.Config.TraceParseTokens {
 		tok := p.peek()
@@ -68,17 +153,21 @@ func (p *Parser) traceNode(node ast.Node) ast.Node {
 }
 
 // synchronize discards tokens until it finds what looks like
-// a statement boundary
+// a statement boundary. Inside a block, the `}` closing it is one, so that
+// the rest of the block, and what follows it, parse as they should.
 func (p *Parser) synchronize() {
+	if p.atBlockEnd() {
+		return
+	}
 	p.advance()
 
 	for !p.isAtEnd() {
-		if p.previous().Type() == token.Semicolon {
+		if p.previous().Type() == token.Semicolon || p.atBlockEnd() {
 			return
 		}
 
 		switch p.peek().Type() {
-		case token.Class, token.Fun, token.Var, token.For,
+		case token.Class, token.Trait, token.Fun, token.Var, token.For,
 			token.If, token.While, token.Print, token.Return:
 			return
 		}
@@ -86,6 +175,11 @@ func (p *Parser) synchronize() {
 	}
 }
 
+// atBlockEnd reports whether the current token closes a block being parsed.
+func (p *Parser) atBlockEnd() bool {
+	return p.blocks > 0 && p.check(token.RightBrace)
+}
+
 func (p *Parser) match(types ...token.Type) bool {
 	for _, t := range types {
 		if p.check(t) {
@@ -101,8 +195,19 @@ func (p *Parser) consume(typ token.Type, message string) token.T {
 		return p.advance()
 	}
 
-	tok := p.peek()
-	panic(p.Error(tok, fmt.Sprintf("found %s; %s", tok.Type(), message)))
+	panic(p.found(p.peek(), message))
+}
+
+// closeBrace consumes the `}` closing the body opened by lbrace. If the
+// input ends first, the error is reported at lbrace, since that is where
+// the missing `}` was needed to match.
+func (p *Parser) closeBrace(lbrace token.T, message string) token.T {
+	if p.isAtEnd() {
+		message = strings.TrimSuffix(message, ".")
+		panic(p.Error(lbrace, "This `{` is never closed: "+
+			strings.ToLower(message[:1])+message[1:]+", found end of file."))
+	}
+	return p.consume(token.RightBrace, message)
 }
 
 func (p *Parser) check(typ token.Type) bool {
@@ -112,6 +217,14 @@ func (p *Parser) check(typ token.Type) bool {
 	return p.peek().Type() == typ
 }
 
+// checkNext reports whether the token after the current one has type typ.
+func (p *Parser) checkNext(typ token.Type) bool {
+	if p.isAtEnd() {
+		return false
+	}
+	return p.tokens[p.current+1].Type() == typ
+}
+
 func (p *Parser) advance() token.T {
 	if !p.isAtEnd() {
 		p.traceToken()
@@ -1,8 +1,6 @@
 package parse
 
 import (
-	"fmt"
-
 	"github.com/perlmonger42/go-lox/ast"
 	"github.com/perlmonger42/go-lox/token"
 )
@@ -10,25 +8,28 @@ import (
 // ===== Node construction =====
 
 func (p *Parser) newVarInitializedStatement(
-	name token.T, init ast.Expr,
+	keyword, name token.T, init ast.Expr, typ, end token.T,
 ) *ast.VarInitialized {
-	varStmt := &ast.VarInitialized{name, init}
+	varStmt := &ast.VarInitialized{name, init, typ, keyword, end}
 	p.traceNode(varStmt)
 	return varStmt
 }
 
 func (p *Parser) newVarUninitializedStatement(
-	name token.T,
+	keyword, name, typ, end token.T,
 ) *ast.VarUninitialized {
-	varStmt := &ast.VarUninitialized{name}
+	varStmt := &ast.VarUninitialized{name, typ, keyword, end}
 	p.traceNode(varStmt)
 	return varStmt
 }
 
 func (p *Parser) newFunction(
-	name token.T, params []token.T, body []ast.Stmt,
+	keyword, name token.T, params []token.T, body []ast.Stmt,
+	kind ast.FunctionKind, paramTypes []token.T, returnType, end token.T,
 ) *ast.Function {
-	function := &ast.Function{name, params, body}
+	function := &ast.Function{
+		name, params, body, kind, paramTypes, returnType, keyword, end,
+	}
 	p.traceNode(function)
 	return function
 }
@@ -39,79 +40,117 @@ func (p *Parser) newNoopStatement() *ast.Noop {
 	return nullStmt
 }
 
-func (p *Parser) newExpressionStatement(expr ast.Expr) *ast.Expression {
-	expressionStmt := &ast.Expression{expr}
+func (p *Parser) newExpressionStatement(
+	expr ast.Expr, end token.T,
+) *ast.Expression {
+	expressionStmt := &ast.Expression{expr, end}
 	p.traceNode(expressionStmt)
 	return expressionStmt
 }
 
-func (p *Parser) newPrintStatement(tok token.T, expr ast.Expr) *ast.Print {
-	printStmt := &ast.Print{tok, expr}
+func (p *Parser) newPrintStatement(
+	tok token.T, expr ast.Expr, end token.T,
+) *ast.Print {
+	printStmt := &ast.Print{tok, expr, end}
 	p.traceNode(printStmt)
 	return printStmt
 }
 
-func (p *Parser) newReturnStatement(tok token.T, expr ast.Expr) *ast.Return {
-	returnStmt := &ast.Return{tok, expr}
+func (p *Parser) newReturnStatement(
+	tok token.T, expr ast.Expr, end token.T,
+) *ast.Return {
+	returnStmt := &ast.Return{tok, expr, end}
 	p.traceNode(returnStmt)
 	return returnStmt
 }
 
-func (p *Parser) newPanicStatement(tok token.T, expr ast.Expr) *ast.Panic {
-	panicStmt := &ast.Panic{tok, expr}
-	p.traceNode(panicStmt)
-	return panicStmt
+func (p *Parser) newBadStatement(from, to token.T) *ast.BadStmt {
+	badStmt := &ast.BadStmt{from, to}
+	p.traceNode(badStmt)
+	return badStmt
 }
 
-func (p *Parser) newBlockStatement(tok token.T, body []ast.Stmt) *ast.Block {
-	blockStmt := &ast.Block{tok, body}
+func (p *Parser) newBlockStatement(
+	tok token.T, body []ast.Stmt, end token.T,
+) *ast.Block {
+	blockStmt := &ast.Block{tok, body, end}
 	p.traceNode(blockStmt)
 	return blockStmt
 }
 
-func (p *Parser) newIfStatement(cond ast.Expr, thenB, elseB ast.Stmt) *ast.If {
-	ifStmt := &ast.If{cond, thenB, elseB}
+func (p *Parser) newIfStatement(
+	keyword token.T, cond ast.Expr, thenB, elseB ast.Stmt,
+) *ast.If {
+	ifStmt := &ast.If{keyword, cond, thenB, elseB}
 	p.traceNode(ifStmt)
 	return ifStmt
 }
 
-func (p *Parser) newWhileStatement(cond ast.Expr, body ast.Stmt) *ast.While {
-	whileStmt := &ast.While{cond, body}
+func (p *Parser) newWhileStatement(
+	keyword token.T, cond ast.Expr, body ast.Stmt,
+) *ast.While {
+	whileStmt := &ast.While{keyword, cond, body}
 	p.traceNode(whileStmt)
 	return whileStmt
 }
 
 func (p *Parser) newClass(
-	name token.T, superclass *ast.Variable, methods []*ast.Function,
+	keyword token.T,
+	name token.T,
+	superclass *ast.Variable,
+	traits []*ast.Variable,
+	methods []*ast.Function,
+	staticMethods []*ast.Function,
+	staticFields []*ast.VarInitialized,
+	end token.T,
 ) *ast.Class {
-	class := &ast.Class{name, superclass, methods}
+	class := &ast.Class{
+		name, superclass, traits, methods, staticMethods, staticFields,
+		keyword, end,
+	}
 	p.traceNode(class)
 	return class
 }
 
+func (p *Parser) newTrait(
+	keyword token.T,
+	name token.T,
+	traits []*ast.Variable,
+	methods []*ast.Function,
+	end token.T,
+) *ast.Trait {
+	trait := &ast.Trait{name, traits, methods, keyword, end}
+	p.traceNode(trait)
+	return trait
+}
+
 // ===== Parsing =====
 
-func (p *Parser) program() (result []ast.Stmt) {
-	var statements []ast.Stmt = []ast.Stmt{}
+func (p *Parser) program() (statements []ast.Stmt) {
+	statements = []ast.Stmt{}
+	defer recoverBailout()
 	for !p.isAtEnd() {
 		statements = append(statements, p.declaration())
 	}
 	return statements
 }
 
+// declaration parses a declaration or statement. If that fails, it skips
+// to what looks like the start of the next one, and returns a BadStmt
+// holding the tokens skipped.
 func (p *Parser) declaration() (result ast.Stmt) {
+	start := p.current
+	p.quiet = false
 	defer func() {
 		if r := recover(); r != nil {
-			if perr, ok := r.(ParseError); ok {
+			if _, ok := r.(ParseError); ok {
 				p.synchronize()
-				msg := fmt.Sprintf(
-					"parse error at %s; this code shouldn't be run",
-					perr.Token.Whence(),
-				)
-				result = p.newPanicStatement(
-					perr.Token,
-					p.newLiteral(token.StringValue{msg}),
-				)
+				p.quiet = false
+				from, to := p.tokens[start], p.tokens[start]
+				if p.current > start {
+					to = p.previous()
+				}
+				result = p.newBadStatement(from, to)
 			} else {
 				panic(r)
 			}
@@ -121,6 +160,9 @@ func (p *Parser) declaration() (result ast.Stmt) {
 	if p.match(token.Class) {
 		return p.classDeclaration()
 	}
+	if p.match(token.Trait) {
+		return p.traitDeclaration()
+	}
 	if p.match(token.Fun) {
 		return p.function("function")
 	}
@@ -131,6 +173,7 @@ func (p *Parser) declaration() (result ast.Stmt) {
 }
 
 func (p *Parser) classDeclaration() ast.Stmt {
+	keyword := p.previous()
 	var name token.T = p.consume(token.Identifier, "Expect class name.")
 
 	var superclass *ast.Variable = nil
@@ -138,50 +181,189 @@ func (p *Parser) classDeclaration() ast.Stmt {
 		p.consume(token.Identifier, "Expect superclass name.")
 		superclass = p.newVariable(p.previous())
 	}
+	traits := p.withClause()
 
 	var methods []*ast.Function
+	var staticMethods []*ast.Function
+	var staticFields []*ast.VarInitialized
 
-	p.consume(token.LeftBrace, "Expect '{' before class body.")
+	lbrace := p.consume(token.LeftBrace, "Expect `{` before class body.")
 	for !p.check(token.RightBrace) && !p.isAtEnd() {
-		methods = append(methods, p.function("method"))
+		if p.match(token.Class) {
+			static := p.previous()
+			if p.checkSetter() ||
+				p.checkNext(token.LeftParen) || p.checkNext(token.LeftBrace) {
+				staticMethods = append(staticMethods, p.method("static method", static))
+			} else {
+				member := p.consume(token.Identifier,
+					"Expect static method or field name after `class`.")
+				staticFields = append(staticFields, p.staticField(static, member))
+			}
+		} else {
+			methods = append(methods, p.method("method", nil))
+		}
 	}
-	p.consume(token.RightBrace, "Expect '}' after class body.")
+	end := p.closeBrace(lbrace, "Expect `}` after class body.")
 
-	return p.newClass(name, superclass, methods)
+	return p.newClass(keyword, name, superclass, traits, methods,
+		staticMethods, staticFields, end)
+}
+
+func (p *Parser) traitDeclaration() ast.Stmt {
+	keyword := p.previous()
+	var name token.T = p.consume(token.Identifier, "Expect trait name.")
+	traits := p.withClause()
+
+	var methods []*ast.Function
+	lbrace := p.consume(token.LeftBrace, "Expect `{` before trait body.")
+	for !p.check(token.RightBrace) && !p.isAtEnd() {
+		if p.match(token.Class) {
+			p.Error(p.previous(), "A trait cannot have static members.")
+		}
+		methods = append(methods, p.method("method", nil))
+	}
+	end := p.closeBrace(lbrace, "Expect `}` after trait body.")
+
+	return p.newTrait(keyword, name, traits, methods, end)
+}
+
+// withClause parses the optional `with Trait1, Trait2` that follows the
+// name (and superclass) of a class or trait.
+func (p *Parser) withClause() []*ast.Variable {
+	var traits []*ast.Variable
+	if p.match(token.With) {
+		for {
+			p.consume(token.Identifier, "Expect trait name.")
+			traits = append(traits, p.newVariable(p.previous()))
+			if !p.match(token.Comma) {
+				break
+			}
+		}
+	}
+	return traits
+}
+
+// checkSetter reports whether the next tokens begin a setter declaration,
+// `set name(value) { ... }`. The word `set` is not reserved, so a method
+// named "set" is still declared as `set(...) { ... }`.
+func (p *Parser) checkSetter() bool {
+	return p.check(token.Identifier) && p.peek().Lexeme() == "set" &&
+		p.checkNext(token.Identifier)
+}
+
+// method parses a method, getter (`name { ... }`) or setter
+// (`set name(value) { ... }`) declaration in a class body. The keyword is
+// the `class` before a static one, or nil.
+func (p *Parser) method(kind string, keyword token.T) *ast.Function {
+	if p.checkSetter() {
+		set := p.advance()
+		if keyword == nil {
+			keyword = set
+		}
+		var name token.T = p.consume(token.Identifier, "Expect setter name.")
+		setter := p.functionRest("setter", keyword, name)
+		setter.Kind = ast.SetterFunction
+		if len(setter.Params) != 1 {
+			p.Error(name, "A setter must have exactly one parameter.")
+		}
+		return setter
+	}
+
+	var name token.T = p.consume(token.Identifier, "Expect "+kind+" name.")
+	if p.check(token.Colon) || p.check(token.LeftBrace) {
+		returnType := p.annotation()
+		p.consume(token.LeftBrace, "Expect `{` before getter body.")
+		body, end := p.block()
+		return p.newFunction(keyword, name, []token.T{}, body,
+			ast.GetterFunction, []token.T{}, returnType, end)
+	}
+	return p.functionRest(kind, keyword, name)
+}
+
+// staticField parses the rest of a `class name = value;` or `class name;`
+// member declaration, after the name.
+func (p *Parser) staticField(keyword, name token.T) *ast.VarInitialized {
+	typ := p.annotation()
+	var value ast.Expr
+	if p.match(token.Equal) {
+		value = p.expression()
+	} else {
+		value = p.newLiteral(token.NilValue{}, nil)
+	}
+	end := p.consume(token.Semicolon, "Expect `;` after static field declaration.")
+	return p.newVarInitializedStatement(keyword, name, value, typ, end)
 }
 
 func (p *Parser) varDeclaration() ast.Stmt {
+	keyword := p.previous()
 	var name token.T = p.consume(token.Identifier, "Expect variable name.")
+	typ := p.annotation()
 
-	var stmt ast.Stmt
+	var init ast.Expr
 	if p.match(token.Equal) {
-		stmt = p.newVarInitializedStatement(name, p.expression())
-	} else {
-		stmt = p.newVarUninitializedStatement(name)
+		init = p.expression()
+	} else if !p.check(token.Semicolon) {
+		if typ != nil {
+			panic(p.expect("after variable type", token.Equal, token.Semicolon))
+		}
+		panic(p.expect("after variable name", token.Colon, token.Equal, token.Semicolon))
 	}
 
-	p.consume(token.Semicolon, "Expect `;` after variable declaration.")
-	return stmt
+	end := p.consume(token.Semicolon, "Expect `;` after variable declaration.")
+	if init == nil {
+		return p.newVarUninitializedStatement(keyword, name, typ, end)
+	}
+	return p.newVarInitializedStatement(keyword, name, init, typ, end)
 }
 
 func (p *Parser) function(kind string) *ast.Function {
+	keyword := p.previous()
 	var name token.T = p.consume(token.Identifier, "Expect "+kind+" name.")
+	return p.functionRest(kind, keyword, name)
+}
+
+// functionRest parses the parameter list and body of a function whose
+// keyword, if any, and name have already been consumed.
+func (p *Parser) functionRest(kind string, keyword, name token.T) *ast.Function {
 	p.consume(token.LeftParen, "Expect `(` after "+kind+" name.")
 	params := []token.T{}
+	paramTypes := []token.T{}
 	for !p.check(token.RightParen) {
 		if len(params) >= 255 {
 			p.Error(p.peek(), "Cannot have more than 255 parameters.")
 		}
 		params = append(params,
 			p.consume(token.Identifier, "Expect parameter name."))
+		paramTypes = append(paramTypes, p.annotation())
 		if !p.match(token.Comma) {
+			if !p.check(token.RightParen) {
+				if paramTypes[len(paramTypes)-1] != nil {
+					panic(p.expect("after parameter type", token.Comma, token.RightParen))
+				}
+				panic(p.expect("after parameter", token.Colon, token.Comma, token.RightParen))
+			}
 			break
 		}
 	}
 	p.consume(token.RightParen, "Expect `)` after parameters.")
+	returnType := p.annotation()
 
 	p.consume(token.LeftBrace, "Expect `{` before "+kind+" body.")
-	return p.newFunction(name, params, p.block())
+	body, end := p.block()
+	return p.newFunction(keyword, name, params, body, ast.OrdinaryFunction,
+		paramTypes, returnType, end)
+}
+
+// annotation parses an optional type annotation, `: type`, returning the
+// type's name, or nil if there is none.
+func (p *Parser) annotation() token.T {
+	if !p.match(token.Colon) {
+		return nil
+	}
+	if p.match(token.Identifier, token.Nil) {
+		return p.previous()
+	}
+	panic(p.found(p.peek(), "Expect type name after `:`."))
 }
 
 func (p *Parser) statement() ast.Stmt {
@@ -202,14 +384,16 @@ func (p *Parser) statement() ast.Stmt {
 	}
 	if p.match(token.LeftBrace) {
 		lbrace := p.previous()
-		return p.newBlockStatement(lbrace, p.block())
+		body, end := p.block()
+		return p.newBlockStatement(lbrace, body, end)
 	}
 	return p.expressionStatement()
 }
 
 func (p *Parser) ifStatement() ast.Stmt {
+	keyword := p.previous()
 	p.consume(token.LeftParen, "Expect `(` after `if`.")
-	var condition ast.Expr = p.expression()
+	var condition ast.Expr = p.condition()
 	p.consume(token.RightParen, "Expect `)` after `if` condition.")
 
 	var thenBranch ast.Stmt = p.statement()
@@ -218,20 +402,41 @@ func (p *Parser) ifStatement() ast.Stmt {
 		elseBranch = p.statement()
 	}
 
-	return p.newIfStatement(condition, thenBranch, elseBranch)
+	return p.newIfStatement(keyword, condition, thenBranch, elseBranch)
+}
+
+// condition parses the condition of an `if`, `while` or `for` statement.
+// An assignment there is most likely a mistyped comparison, so it is
+// warned of, unless it's in parentheses of its own.
+func (p *Parser) condition() ast.Expr {
+	var condition ast.Expr = p.expression()
+	var name token.T
+	switch expr := condition.(type) {
+	case *ast.Assign:
+		name = expr.Name
+	case *ast.Set:
+		name = expr.Name
+	}
+	if name != nil {
+		p.lox.Warn(name, "assign-in-condition",
+			"Assignment in a condition; use `==` to compare, or put the assignment in parentheses.")
+	}
+	return condition
 }
 
 func (p *Parser) whileStatement() ast.Stmt {
+	keyword := p.previous()
 	p.consume(token.LeftParen, "Expect `(` after `while`.")
-	var condition ast.Expr = p.expression()
+	var condition ast.Expr = p.condition()
 	p.consume(token.RightParen, "Expect `)` after `while` condition.")
 
 	var body ast.Stmt = p.statement()
 
-	return p.newWhileStatement(condition, body)
+	return p.newWhileStatement(keyword, condition, body)
 }
 
 func (p *Parser) forStatement() ast.Stmt {
+	keyword := p.previous()
 	lParen := p.peek()
 	p.consume(token.LeftParen, "Expect `(` after `for`.")
 	var initializer ast.Stmt
@@ -245,27 +450,27 @@ func (p *Parser) forStatement() ast.Stmt {
 
 	var condition ast.Expr
 	if !p.check(token.Semicolon) {
-		condition = p.expression()
+		condition = p.condition()
 	}
 	p.consume(token.Semicolon, "Expect `;` after `for` condition.")
 
 	var increment ast.Stmt
 	if !p.check(token.Semicolon) {
-		increment = p.newExpressionStatement(p.expression())
+		increment = p.newExpressionStatement(p.expression(), nil)
 	}
 	p.consume(token.RightParen, "Expect `)` after `for` clauses.")
 
 	var body ast.Stmt = p.statement()
 
 	if increment != nil {
-		body = p.newBlockStatement(lParen, []ast.Stmt{body, increment})
+		body = p.newBlockStatement(lParen, []ast.Stmt{body, increment}, nil)
 	}
 	if condition == nil {
-		condition = p.newLiteral(token.BooleanValue{true})
+		condition = p.newLiteral(token.BooleanValue{true}, nil)
 	}
-	body = p.newWhileStatement(condition, body)
+	body = p.newWhileStatement(keyword, condition, body)
 	if initializer != nil {
-		body = p.newBlockStatement(lParen, []ast.Stmt{initializer, body})
+		body = p.newBlockStatement(lParen, []ast.Stmt{initializer, body}, nil)
 	}
 
 	return body
@@ -274,36 +479,43 @@ func (p *Parser) forStatement() ast.Stmt {
 func (p *Parser) printStatement() ast.Stmt {
 	var keyword token.T = p.previous()
 	var value ast.Expr = p.expression()
-	p.consume(token.Semicolon, "Expect `;` after value.")
-	return p.newPrintStatement(keyword, value)
+	end := p.consume(token.Semicolon, "Expect `;` after value.")
+	return p.newPrintStatement(keyword, value, end)
 }
 
 func (p *Parser) returnStatement() ast.Stmt {
 	var keyword token.T = p.previous()
 	var value ast.Expr
+	var end token.T
 	if p.check(token.Semicolon) {
-		p.advance()
+		end = p.advance()
 	} else {
 		value = p.expression()
-		p.consume(token.Semicolon, "Expect expression or `;` after `return`.")
+		end = p.consume(token.Semicolon, "Expect expression or `;` after `return`.")
 	}
 
-	return p.newReturnStatement(keyword, value)
+	return p.newReturnStatement(keyword, value, end)
 }
 
 func (p *Parser) expressionStatement() ast.Stmt {
 	var expr ast.Expr = p.expression()
-	p.consume(token.Semicolon, "Expect `;` after expression statement.")
-	return p.newExpressionStatement(expr)
+	end := p.consume(token.Semicolon, "Expect `;` after expression statement.")
+	return p.newExpressionStatement(expr, end)
 }
 
-func (p *Parser) block() []ast.Stmt {
+// block parses the statements of a block, or body, after its `{`,
+// returning them and its `}`.
+func (p *Parser) block() ([]ast.Stmt, token.T) {
+	lbrace := p.previous()
 	var statements []ast.Stmt = make([]ast.Stmt, 0, 5)
 
+	p.blocks++
+	defer func() { p.blocks-- }()
+
 	for !p.check(token.RightBrace) && !p.isAtEnd() {
 		statements = append(statements, p.declaration())
 	}
 
-	p.consume(token.RightBrace, "Expect `}` after block.")
-	return statements
+	end := p.closeBrace(lbrace, "Expect `}` after block.")
+	return statements, end
 }
@@ -2,6 +2,7 @@ package parse
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/perlmonger42/go-lox/ast"
 	"github.com/perlmonger42/go-lox/config"
@@ -29,8 +30,8 @@ func ExampleEmptyProgram() {
 func ExampleMissingSemicolon() {
 	dumpProgram("7")
 	// Output:
-	// [line 1] Error at end: found EOF; Expect `;` after expression statement.
-	//  1: panic "parse error at line 1; this code shouldn't be run";
+	// [line 1] Error at end: Expect `;` after expression statement, found end of file.
+	//  1: (bad);
 }
 
 func ExampleExpressionStatement() {
@@ -49,10 +50,143 @@ func ExampleTwoStatements() {
 func ExampleSyncOnSemicolon() {
 	dumpProgram("7+;\nprint 1;\n);\nprint 2;")
 	// Output:
-	// [line 1] Error at 'Semicolon': Expect expression.
-	// [line 3] Error at 'RightParen': Expect expression.
-	//  1: panic "parse error at line 1; this code shouldn't be run";
+	// [line 1] Error at ';': Expect expression, found `;`.
+	// [line 3] Error at ')': Expect expression, found `)`.
+	//  1: (+ 7 (bad));
 	//  2: print 1;
-	//  3: panic "parse error at line 3; this code shouldn't be run";
+	//  3: (bad);
 	//  4: print 2;
 }
+
+func ExampleSyncInBlock() {
+	dumpProgram("fun f() {\n  print 1\n}\nprint 2;\nwhile (x) { var = 3; print x; }")
+	// Output:
+	// [line 2] Error at '1': Expect `;` after value, found end of line.
+	// [line 5] Error at '=': Expect variable name, found `=`.
+	//  1: fun () {
+	//   (bad);
+	// }
+	//  2: print 2;
+	//  3: while (x) {
+	//   (bad);
+	//   print x;
+	// }
+}
+
+func ExampleBadExpressions() {
+	dumpProgram("print f(1, , 3);\nprint -* 2;\nvar a = (1 + );")
+	// Output:
+	// [line 1] Error at ',': Expect expression, found `,`.
+	// [line 2] Error at '*': Expect expression, found `*`.
+	// [line 3] Error at ')': Expect expression, found `)`.
+	//  1: print f(1, (bad), 3);
+	//  2: print (- (bad));
+	//  3: var a = (group (+ 1 (bad)));
+}
+
+func ExampleExpectedTokens() {
+	dumpProgram("print f(1 2);\nvar x 3;\nfun g(a b) {}")
+	// Output:
+	// [line 1] Error at '2': Expect `,` or `)` after argument, found number `2`.
+	// [line 2] Error at '3': Expect `:`, `=` or `;` after variable name, found number `3`.
+	// [line 3] Error at 'b': Expect `:`, `,` or `)` after parameter, found identifier `b`.
+	//  1: (bad);
+	//  2: (bad);
+	//  3: (bad);
+}
+
+func ExampleAssignmentInCondition() {
+	config := config.New()
+	config.Warnings = true
+	lox := lox.New(config)
+	New(lox, scan.New(lox, `
+if (a = 1) print a;
+while ((a = next())) print a;
+if (p.x = 2) print p; // lox:ignore assign-in-condition
+`).ScanTokens()).ParseProg()
+	fmt.Println(lox.HadError)
+	dumpProgram("if (a = 1) print a;\nwhile ((a = next())) print a;")
+	// Output:
+	// [line 2] Warning at 'a': Assignment in a condition; use `==` to compare, or put the assignment in parentheses. (assign-in-condition)
+	// false
+	//  1: if (a = 1;)
+	//   print a;
+	//  2: while ((group a = next();))
+	//   print a;
+}
+
+func ExampleUnclosedBrace() {
+	dumpProgram("fun f() {\n  if (a) {\n    print a;\n}\n")
+	// Output:
+	// [line 1] Error at '{': This `{` is never closed: expect `}` after block, found end of file.
+	//  1: (bad);
+}
+
+func ExampleTooManyErrors() {
+	dumpProgram(strings.Repeat("print;\n", 12) + "print 1;")
+	// Output:
+	// [line 1] Error at ';': Expect expression, found `;`.
+	// [line 2] Error at ';': Expect expression, found `;`.
+	// [line 3] Error at ';': Expect expression, found `;`.
+	// [line 4] Error at ';': Expect expression, found `;`.
+	// [line 5] Error at ';': Expect expression, found `;`.
+	// [line 6] Error at ';': Expect expression, found `;`.
+	// [line 7] Error at ';': Expect expression, found `;`.
+	// [line 8] Error at ';': Expect expression, found `;`.
+	// [line 9] Error at ';': Expect expression, found `;`.
+	// [line 10] Error at ';': Expect expression, found `;`.
+	// [line 11] Error at ';': Too many errors.
+	//  1: print (bad);
+	//  2: print (bad);
+	//  3: print (bad);
+	//  4: print (bad);
+	//  5: print (bad);
+	//  6: print (bad);
+	//  7: print (bad);
+	//  8: print (bad);
+	//  9: print (bad);
+	// 10: print (bad);
+}
+
+func ExampleClassWithStatics() {
+	dumpProgram(`class A < B { class x = 1; class y; class f(a) { return a; } g() { print 1; } }`)
+	// Output:
+	//  1: class A < B {
+	//   class x = 1;
+	//   class y = nil;
+	//   class f(a) {
+	//     return a;
+	//   }
+	//   g() {
+	//     print 1;
+	//   }
+	// }
+}
+
+func ExampleClassWithAccessors() {
+	dumpProgram(`class A { area { return 1; } set area(a) { print a; } class n { return 2; } }`)
+	// Output:
+	//  1: class A {
+	//   class n {
+	//     return 2;
+	//   }
+	//   area {
+	//     return 1;
+	//   }
+	//   set area(a) {
+	//     print a;
+	//   }
+	// }
+}
+
+func ExampleTrait() {
+	dumpProgram(`trait T with U, V { m() { return 1; } } class A < B with T { }`)
+	// Output:
+	//  1: trait T with U, V {
+	//   m() {
+	//     return 1;
+	//   }
+	// }
+	//  2: class A < B with T {
+	// }
+}
@@ -0,0 +1,143 @@
+package profile
+
+import (
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"strings"
+)
+
+// WriteProfile writes the profile, gzipped, in pprof's protocol buffer
+// format (see profile.proto in https://github.com/google/pprof). Each
+// sample is a call stack of Lox functions and lines, with two values: the
+// number of calls made with that stack, and the nanoseconds spent in it.
+// The filename is the name of the program's source file.
+func (p *Profiler) WriteProfile(w io.Writer, filename string) error {
+	table := newStringTable()
+	var profile message
+
+	// sample_type, then its default
+	for _, vt := range [][2]string{{"calls", "count"}, {"time", "nanoseconds"}} {
+		var valueType message
+		valueType.int(1, table.index(vt[0]))
+		valueType.int(2, table.index(vt[1]))
+		profile.bytes(1, valueType.Bytes())
+	}
+	defaultSampleType := table.index("time")
+
+	// sample
+	locations := map[location]int{}
+	var order []location
+	for _, key := range p.order {
+		s := p.samples[key]
+		ids := []uint64{}
+		for _, loc := range s.stack {
+			id, ok := locations[loc]
+			if !ok {
+				id = len(locations) + 1
+				locations[loc] = id
+				order = append(order, loc)
+			}
+			ids = append(ids, uint64(id))
+		}
+		var sample message
+		sample.packed(1, ids)
+		sample.packed(2, []uint64{uint64(s.calls), uint64(s.time.Nanoseconds())})
+		profile.bytes(2, sample.Bytes())
+	}
+
+	// location
+	for n, loc := range order {
+		var line message
+		line.int(1, int64(loc.function.id))
+		line.int(2, int64(loc.line))
+		var entry message
+		entry.int(1, int64(n+1))
+		entry.bytes(4, line.Bytes())
+		profile.bytes(4, entry.Bytes())
+	}
+
+	// function
+	file := table.index(filename)
+	for _, f := range p.Functions {
+		// pprof would strip "<script>", taking it for C++ template
+		// arguments, and show the top level as "<unknown>".
+		name := strings.Trim(f.Name, "<>")
+		var function message
+		function.int(1, int64(f.id))
+		function.int(2, table.index(name))
+		function.int(3, table.index(name))
+		function.int(4, file)
+		function.int(5, int64(f.Line))
+		profile.bytes(5, function.Bytes())
+	}
+
+	// The string table must come after everything that adds to it.
+	for _, s := range table.strings {
+		profile.bytes(6, []byte(s))
+	}
+	profile.int(9, p.start.UnixNano())   // time_nanos
+	profile.int(10, int64(p.Duration())) // duration_nanos
+	profile.int(14, defaultSampleType)   // default_sample_type
+
+	zw := gzip.NewWriter(w)
+	if _, err := zw.Write(profile.Bytes()); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// A stringTable numbers the strings of a profile; string 0 is "".
+type stringTable struct {
+	strings []string
+	indexes map[string]int64
+}
+
+func newStringTable() *stringTable {
+	return &stringTable{strings: []string{""}, indexes: map[string]int64{"": 0}}
+}
+
+func (t *stringTable) index(s string) int64 {
+	if n, ok := t.indexes[s]; ok {
+		return n
+	}
+	n := int64(len(t.strings))
+	t.strings = append(t.strings, s)
+	t.indexes[s] = n
+	return n
+}
+
+// A message is a protocol buffer message being encoded. Only the wire
+// types a profile needs are supported: varints (for every integer field)
+// and length-delimited fields (for strings, nested messages and packed
+// repeated integers). Zero integers, being the default, are left out.
+type message struct {
+	buf []byte
+}
+
+func (m *message) Bytes() []byte { return m.buf }
+
+func (m *message) key(field int, wireType uint64) {
+	m.buf = binary.AppendUvarint(m.buf, uint64(field)<<3|wireType)
+}
+
+func (m *message) int(field int, v int64) {
+	if v != 0 {
+		m.key(field, 0)
+		m.buf = binary.AppendUvarint(m.buf, uint64(v))
+	}
+}
+
+func (m *message) bytes(field int, b []byte) {
+	m.key(field, 2)
+	m.buf = binary.AppendUvarint(m.buf, uint64(len(b)))
+	m.buf = append(m.buf, b...)
+}
+
+func (m *message) packed(field int, vs []uint64) {
+	var b []byte
+	for _, v := range vs {
+		b = binary.AppendUvarint(b, v)
+	}
+	m.bytes(field, b)
+}
@@ -0,0 +1,186 @@
+// Package profile implements the instrumenting profiler run by
+// `go-lox -profile`. A Profiler follows a program through the
+// interpreter's Hook, counting the calls of each Lox function and the
+// executions of each line, and timing each function inclusively (counting
+// the functions it calls) and exclusively (not counting them).
+//
+// Time is also recorded for each distinct call stack, so that the profile
+// written by WriteProfile, in the format of pprof
+// (https://github.com/google/pprof), shows where time goes with Lox
+// functions and lines in place of Go's: `go tool pprof -http=: file` draws
+// it as a flame graph.
+package profile
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/perlmonger42/go-lox/ast"
+	"github.com/perlmonger42/go-lox/interpret"
+)
+
+// A Function holds what was recorded for one Lox function (or, under the
+// name "<script>", for the top level of the program).
+type Function struct {
+	Name      string
+	Line      int // where it is declared; 0 for the script
+	Calls     int
+	Inclusive time.Duration
+	Exclusive time.Duration
+
+	id int // its position in Profiler.Functions, from 1
+}
+
+// A Profiler records the progress of a program. Install it with the
+// interpreter's SetHook before running the program, then call Stop after.
+type Profiler struct {
+	Functions []*Function // in order of first call
+	Lines     map[int]int // line -> number of statements executed on it
+
+	now       func() time.Time
+	start     time.Time
+	last      time.Time // when time was last accounted for
+	functions map[*ast.Function]*Function
+	stack     []*activation // innermost last
+	samples   map[string]*sample
+	order     []string // keys of samples, in order of creation
+}
+
+var _ interpret.Hook = &Profiler{}
+
+// An activation is a call in progress.
+type activation struct {
+	function *Function
+	start    time.Time
+	line     int // the line executing
+}
+
+// A sample is the time spent, and the calls made, with one call stack.
+type sample struct {
+	stack []location // innermost first
+	calls int
+	time  time.Duration
+}
+
+// A location is a line of a function.
+type location struct {
+	function *Function
+	line     int
+}
+
+// New returns a profiler whose clock starts now. If now is nil, it is the
+// real time; tests may supply another.
+func New(now func() time.Time) *Profiler {
+	if now == nil {
+		now = time.Now
+	}
+	p := &Profiler{
+		Lines:     make(map[int]int),
+		now:       now,
+		functions: make(map[*ast.Function]*Function),
+		samples:   make(map[string]*sample),
+	}
+	script := &Function{Name: "<script>", Calls: 1, id: 1}
+	p.Functions = append(p.Functions, script)
+	p.start = p.now()
+	p.last = p.start
+	p.stack = []*activation{{function: script, start: p.start}}
+	p.sampleNow().calls++
+	return p
+}
+
+// Statement counts a hit on the line of stmt.
+func (p *Profiler) Statement(i interpret.T, stmt ast.Stmt) {
+	if _, ok := stmt.(*ast.Block); ok {
+		return // its statements are counted instead
+	}
+	line := ast.StmtLine(stmt)
+	if line == 0 {
+		return
+	}
+	p.tick()
+	p.stack[len(p.stack)-1].line = line
+	p.Lines[line]++
+}
+
+// Enter counts a call of f, and starts its clock.
+func (p *Profiler) Enter(i interpret.T, f *interpret.LoxFunction) {
+	now := p.tick()
+	function, ok := p.functions[f.Declaration]
+	if !ok {
+		function = &Function{
+			Name: f.Declaration.Name.Lexeme(),
+			Line: f.Declaration.Name.Whence().Line(),
+			id:   len(p.Functions) + 1,
+		}
+		p.functions[f.Declaration] = function
+		p.Functions = append(p.Functions, function)
+	}
+	function.Calls++
+	p.stack = append(p.stack, &activation{function, now, function.Line})
+	p.sampleNow().calls++
+}
+
+// Leave stops the clock of a call of f.
+func (p *Profiler) Leave(i interpret.T, f *interpret.LoxFunction) {
+	now := p.tick()
+	p.pop(now)
+}
+
+// Stop ends the profile, once the program has finished.
+func (p *Profiler) Stop() {
+	now := p.tick()
+	for len(p.stack) > 0 {
+		p.pop(now)
+	}
+}
+
+// Duration returns how long the program ran.
+func (p *Profiler) Duration() time.Duration {
+	return p.last.Sub(p.start)
+}
+
+// pop ends the innermost activation. Its time counts as inclusive time
+// for its function unless the function is recursive and an outer call of
+// it is still active, whose time includes this one's.
+func (p *Profiler) pop(now time.Time) {
+	top := p.stack[len(p.stack)-1]
+	p.stack = p.stack[:len(p.stack)-1]
+	for _, a := range p.stack {
+		if a.function == top.function {
+			return
+		}
+	}
+	top.function.Inclusive += now.Sub(top.start)
+}
+
+// tick charges the time since the last tick to the innermost activation
+// and the current call stack, and returns the time now.
+func (p *Profiler) tick() time.Time {
+	now := p.now()
+	elapsed := now.Sub(p.last)
+	p.last = now
+	if len(p.stack) > 0 {
+		p.stack[len(p.stack)-1].function.Exclusive += elapsed
+		p.sampleNow().time += elapsed
+	}
+	return now
+}
+
+// sampleNow returns the sample for the current call stack.
+func (p *Profiler) sampleNow() *sample {
+	stack := make([]location, len(p.stack))
+	key := make([]byte, 0, 2*binary.MaxVarintLen64*len(p.stack))
+	for n, a := range p.stack {
+		stack[len(p.stack)-1-n] = location{a.function, a.line}
+		key = binary.AppendUvarint(key, uint64(a.function.id))
+		key = binary.AppendUvarint(key, uint64(a.line))
+	}
+	s, ok := p.samples[string(key)]
+	if !ok {
+		s = &sample{stack: stack}
+		p.samples[string(key)] = s
+		p.order = append(p.order, string(key))
+	}
+	return s
+}
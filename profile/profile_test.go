@@ -0,0 +1,186 @@
+package profile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/perlmonger42/go-lox/config"
+	"github.com/perlmonger42/go-lox/interpret"
+	"github.com/perlmonger42/go-lox/lox"
+	"github.com/perlmonger42/go-lox/parse"
+	"github.com/perlmonger42/go-lox/resolve"
+	"github.com/perlmonger42/go-lox/scan"
+)
+
+const program = `fun fib(n) {
+  if (n < 2) return n;
+  return fib(n - 1) + fib(n - 2);
+}
+fun work() {
+  var total = 0;
+  for (var i = 0; i < 4; i = i + 1) {
+    total = total + fib(i);
+  }
+  return total;
+}
+print work();
+`
+
+// run profiles text with a clock that advances a millisecond each time it
+// is read.
+func run(text string) *Profiler {
+	lox := lox.New(config.New())
+	stmts := parse.New(lox, scan.New(lox, text).ScanTokens()).ParseProg()
+	interpreter := interpret.New(lox)
+	resolve.New(lox, interpreter).ResolveStmtList(stmts)
+	if lox.HadError {
+		return nil
+	}
+
+	clock := time.Unix(0, 0)
+	p := New(func() time.Time {
+		clock = clock.Add(time.Millisecond)
+		return clock
+	})
+	interpreter.SetHook(p)
+	interpreter.InterpretStmts(stmts)
+	p.Stop()
+	return p
+}
+
+func ExampleProfiler_WriteReport() {
+	run(program).WriteReport(os.Stdout, program)
+	// Output:
+	// 4
+	// total time 58ms
+	//
+	//    calls    inclusive    exclusive  function
+	//       10         36ms         36ms  fib (line 1)
+	//        1         53ms         17ms  work (line 5)
+	//        1         58ms          5ms  <script>
+	//
+	//     line     hits  source
+	//        1        1  fun fib(n) {
+	//        2       17  if (n < 2) return n;
+	//        3        3  return fib(n - 1) + fib(n - 2);
+	//        5        1  fun work() {
+	//        6        1  var total = 0;
+	//        7        6  for (var i = 0; i < 4; i = i + 1) {
+	//        8        4  total = total + fib(i);
+	//       10        1  return total;
+	//       12        1  print work();
+}
+
+// A field is a field of an encoded protocol buffer message, with either
+// an integer value or an encoded one.
+type field struct {
+	number int
+	value  uint64
+	bytes  []byte
+}
+
+// decode splits a protocol buffer message into its fields.
+func decode(content []byte) []field {
+	fields := []field{}
+	for len(content) > 0 {
+		key, n := binary.Uvarint(content)
+		content = content[n:]
+		f := field{number: int(key >> 3)}
+		if key&7 == 0 {
+			f.value, n = binary.Uvarint(content)
+			content = content[n:]
+		} else {
+			length, n := binary.Uvarint(content)
+			f.bytes = content[n : n+int(length)]
+			content = content[n+int(length):]
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// packed decodes packed repeated integers.
+func packed(b []byte) []uint64 {
+	vs := []uint64{}
+	for len(b) > 0 {
+		v, n := binary.Uvarint(b)
+		vs = append(vs, v)
+		b = b[n:]
+	}
+	return vs
+}
+
+func ExampleProfiler_WriteProfile() {
+	var buf bytes.Buffer
+	run(program).WriteProfile(&buf, "fib.lox")
+	zr, err := gzip.NewReader(&buf)
+	if err != nil {
+		panic(err)
+	}
+	content, _ := ioutil.ReadAll(zr)
+
+	// Print each sample as a stack of function:line, outermost first,
+	// followed by its values.
+	var samples [][]byte
+	table := []string{}
+	functions := map[uint64]uint64{}    // id -> name
+	locations := map[uint64][2]uint64{} // id -> function id, line
+	for _, f := range decode(content) {
+		switch f.number {
+		case 2:
+			samples = append(samples, f.bytes)
+		case 4:
+			var id uint64
+			var line [2]uint64
+			for _, g := range decode(f.bytes) {
+				switch g.number {
+				case 1:
+					id = g.value
+				case 4:
+					for _, h := range decode(g.bytes) {
+						line[h.number-1] = h.value
+					}
+				}
+			}
+			locations[id] = line
+		case 5:
+			fields := decode(f.bytes)
+			functions[fields[0].value] = fields[1].value
+		case 6:
+			table = append(table, string(f.bytes))
+		}
+	}
+	for _, sample := range samples {
+		fields := decode(sample)
+		ids, values := packed(fields[0].bytes), packed(fields[1].bytes)
+		for n := len(ids) - 1; n >= 0; n-- {
+			loc := locations[ids[n]]
+			fmt.Printf("%s:%d ", table[functions[loc[0]]], loc[1])
+		}
+		fmt.Printf("calls=%d time=%s\n", values[0], time.Duration(values[1]))
+	}
+	// Output:
+	// 4
+	// script:0 calls=1 time=1ms
+	// script:1 calls=0 time=1ms
+	// script:5 calls=0 time=1ms
+	// script:12 calls=0 time=2ms
+	// script:12 work:5 calls=1 time=1ms
+	// script:12 work:6 calls=0 time=1ms
+	// script:12 work:7 calls=0 time=6ms
+	// script:12 work:8 calls=0 time=8ms
+	// script:12 work:8 fib:1 calls=4 time=4ms
+	// script:12 work:8 fib:2 calls=0 time=6ms
+	// script:12 work:8 fib:3 calls=0 time=6ms
+	// script:12 work:8 fib:3 fib:1 calls=4 time=4ms
+	// script:12 work:8 fib:3 fib:2 calls=0 time=7ms
+	// script:12 work:8 fib:3 fib:3 calls=0 time=3ms
+	// script:12 work:8 fib:3 fib:3 fib:1 calls=2 time=2ms
+	// script:12 work:8 fib:3 fib:3 fib:2 calls=0 time=4ms
+	// script:12 work:10 calls=0 time=1ms
+}
@@ -0,0 +1,47 @@
+package profile
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// WriteReport writes a human-readable summary of the profile: the functions,
+// by decreasing exclusive time, then the count of statements executed on
+// each line of source that ran.
+func (p *Profiler) WriteReport(w io.Writer, source string) error {
+	functions := append([]*Function{}, p.Functions...)
+	sort.SliceStable(functions, func(a, b int) bool {
+		return functions[a].Exclusive > functions[b].Exclusive
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "total time %s\n\n", p.Duration())
+	fmt.Fprintf(&b, "%8s %12s %12s  %s\n", "calls", "inclusive", "exclusive", "function")
+	for _, f := range functions {
+		name := f.Name
+		if f.Line > 0 {
+			name = fmt.Sprintf("%s (line %d)", f.Name, f.Line)
+		}
+		fmt.Fprintf(&b, "%8d %12s %12s  %s\n", f.Calls, f.Inclusive, f.Exclusive, name)
+	}
+
+	lines := make([]int, 0, len(p.Lines))
+	for line := range p.Lines {
+		lines = append(lines, line)
+	}
+	sort.Ints(lines)
+	text := strings.Split(source, "\n")
+	fmt.Fprintf(&b, "\n%8s %8s  %s\n", "line", "hits", "source")
+	for _, line := range lines {
+		src := ""
+		if line <= len(text) {
+			src = strings.TrimSpace(text[line-1])
+		}
+		fmt.Fprintf(&b, "%8d %8d  %s\n", line, p.Lines[line], src)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -13,6 +13,15 @@ type T interface {
 	Report(pos token.Pos, where string, message string)
 }
 
+// A Warner is a report.T that also reports warnings: problems that, unlike
+// errors, don't stop a program from running. Warnings given to a report.T
+// that is not a Warner are dropped.
+type Warner interface {
+	T
+	// Warn generates a warning report for a given location.
+	Warn(pos token.Pos, where string, message string)
+}
+
 // StderrReporter is a Reporter that simply prints messages to os.Stderr
 type StderrReporter struct {
 }
@@ -25,6 +34,14 @@ func (c *StderrReporter) Report(pos token.Pos, where string, message string) {
 	fmt.Fprintf(os.Stderr, "[%s] Error%s%s: %s\n", pos, pad, where, message)
 }
 
+func (c *StderrReporter) Warn(pos token.Pos, where string, message string) {
+	pad := ""
+	if where != "" {
+		pad = " "
+	}
+	fmt.Fprintf(os.Stderr, "[%s] Warning%s%s: %s\n", pos, pad, where, message)
+}
+
 func NewStderrReporter() T {
 	return &StderrReporter{}
 }
@@ -41,6 +58,14 @@ func (c *StdoutReporter) Report(pos token.Pos, where string, message string) {
 	fmt.Fprintf(os.Stdout, "[%s] Error%s%s: %s\n", pos, pad, where, message)
 }
 
+func (c *StdoutReporter) Warn(pos token.Pos, where string, message string) {
+	pad := ""
+	if where != "" {
+		pad = " "
+	}
+	fmt.Fprintf(os.Stdout, "[%s] Warning%s%s: %s\n", pos, pad, where, message)
+}
+
 func NewStdoutReporter() T {
 	return &StdoutReporter{}
 }
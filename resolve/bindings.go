@@ -0,0 +1,112 @@
+package resolve
+
+import (
+	"github.com/perlmonger42/go-lox/ast"
+	"github.com/perlmonger42/go-lox/token"
+)
+
+// DeclarationKind says what sort of statement introduced a name.
+type DeclarationKind int
+
+const (
+	VariableDeclaration DeclarationKind = iota
+	FunctionDeclaration
+	ParameterDeclaration
+	ClassDeclaration
+	TraitDeclaration
+)
+
+func (k DeclarationKind) String() string {
+	switch k {
+	case VariableDeclaration:
+		return "var"
+	case FunctionDeclaration:
+		return "fun"
+	case ParameterDeclaration:
+		return "parameter"
+	case ClassDeclaration:
+		return "class"
+	case TraitDeclaration:
+		return "trait"
+	}
+	return "declaration"
+}
+
+// A Declaration is a name introduced into a scope.
+type Declaration struct {
+	Name   token.T
+	Kind   DeclarationKind
+	Node   ast.Node // the declaring statement; for a parameter, its *ast.Function
+	Global bool
+}
+
+// Bindings records, for tools such as the language server, which
+// declaration each use of a name refers to. A resolver fills in its
+// Bindings, if it has any, as it resolves.
+type Bindings struct {
+	Declarations []*Declaration // in the order resolved
+
+	uses    []token.T                // in the order resolved
+	binding map[token.T]*Declaration // nil for a global not yet declared
+	globals map[string]*Declaration
+}
+
+func NewBindings() *Bindings {
+	return &Bindings{
+		binding: make(map[token.T]*Declaration),
+		globals: make(map[string]*Declaration),
+	}
+}
+
+func (b *Bindings) declare(name token.T, kind DeclarationKind, node ast.Node, global bool) *Declaration {
+	decl := &Declaration{Name: name, Kind: kind, Node: node, Global: global}
+	b.Declarations = append(b.Declarations, decl)
+	if global {
+		b.globals[name.Lexeme()] = decl
+	}
+	return decl
+}
+
+func (b *Bindings) use(name token.T, decl *Declaration) {
+	if decl == nil && b.globals[name.Lexeme()] != nil {
+		decl = b.globals[name.Lexeme()]
+	}
+	b.uses = append(b.uses, name)
+	b.binding[name] = decl
+}
+
+// DeclarationOf returns the declaration that name, a use or a declaration
+// of a name, refers to; or nil if there is none (as for natives). A use of a
+// global inside a function may precede the global's declaration, so such a
+// use is matched by name once resolution is complete.
+func (b *Bindings) DeclarationOf(name token.T) *Declaration {
+	for _, decl := range b.Declarations {
+		if decl.Name == name {
+			return decl
+		}
+	}
+	decl, ok := b.binding[name]
+	if !ok {
+		return nil
+	}
+	if decl == nil {
+		decl = b.globals[name.Lexeme()]
+	}
+	return decl
+}
+
+// Uses returns every resolved use of a name, in the order resolved.
+func (b *Bindings) Uses() []token.T {
+	return b.uses
+}
+
+// References returns the uses of decl, in the order resolved.
+func (b *Bindings) References(decl *Declaration) []token.T {
+	refs := []token.T{}
+	for _, use := range b.uses {
+		if b.DeclarationOf(use) == decl {
+			refs = append(refs, use)
+		}
+	}
+	return refs
+}
@@ -17,6 +17,7 @@ const (
 	FUNCTION
 	INITIALIZER // "init" method of a class
 	METHOD
+	STATIC_METHOD // `this` refers to the class
 )
 
 type ClassType int
@@ -25,14 +26,24 @@ const (
 	NOT_CLASS ClassType = iota
 	CLASS
 	SUBCLASS
+	TRAIT // `super` refers to the superclass of the including class
 )
 
 type T struct {
 	lox             *lox.T
 	resolver        Resolver
 	scopes          []map[string]bool
+	traitScopes     []map[string]*ast.Trait   // parallels scopes; see trait.go
+	declScopes      []map[string]*Declaration // parallels scopes, for Bindings
+	locals          []map[string]*local       // parallels scopes; see warn.go
+	warnings        []warning                 // not yet reported; see warn.go
+	globalTraits    map[string]*ast.Trait
 	currentFunction FunctionType
 	currentClass    ClassType
+
+	// Bindings, if not nil, is filled in with the declaration of each name
+	// resolved.
+	Bindings *Bindings
 }
 
 var _ ast.Visitor_Stmt = &T{}
@@ -40,8 +51,9 @@ var _ ast.Visitor_Expr = &T{}
 
 func New(lox *lox.T, resolver Resolver) *T {
 	return &T{
-		lox:      lox,
-		resolver: resolver,
+		lox:          lox,
+		resolver:     resolver,
+		globalTraits: make(map[string]*ast.Trait),
 	}
 }
 
@@ -68,12 +80,23 @@ func (r *T) topScopeFetch(name token.T) (defined bool, ok bool) {
 
 func (r *T) beginScope() {
 	r.scopes = append(r.scopes, make(map[string]bool))
+	r.traitScopes = append(r.traitScopes, make(map[string]*ast.Trait))
+	r.declScopes = append(r.declScopes, make(map[string]*Declaration))
+	r.locals = append(r.locals, make(map[string]*local))
 }
 
-func (r *T) declare(name token.T) {
+func (r *T) declare(name token.T, kind DeclarationKind, node ast.Node) {
 	if s := r.topScope(); s != nil {
 		s[name.Lexeme()] = false
 	}
+	r.declareLocal(name, kind)
+	r.recordTrait(name, nil)
+	if r.Bindings != nil {
+		decl := r.Bindings.declare(name, kind, node, len(r.scopes) == 0)
+		if n := len(r.declScopes); n > 0 {
+			r.declScopes[n-1][name.Lexeme()] = decl
+		}
+	}
 }
 
 func (r *T) define(name token.T) {
@@ -83,13 +106,46 @@ func (r *T) define(name token.T) {
 }
 
 func (r *T) endScope() {
+	r.warnUnused()
 	r.scopes = r.scopes[0 : len(r.scopes)-1]
+	r.traitScopes = r.traitScopes[0 : len(r.traitScopes)-1]
+	r.declScopes = r.declScopes[0 : len(r.declScopes)-1]
+	r.locals = r.locals[0 : len(r.locals)-1]
 }
 
 func (r *T) ResolveStmtList(statements []ast.Stmt) {
 	for _, statement := range statements {
 		r.resolveStmt(statement)
 	}
+	r.warnUnreachable(statements)
+	if len(r.scopes) == 0 {
+		r.reportWarnings()
+	}
+}
+
+// ResolveExprIn resolves expr as though it appeared where the given local
+// scopes, outermost first, are visible. A debugger uses it to evaluate an
+// expression in the frame of a paused program.
+func (r *T) ResolveExprIn(expr ast.Expr, scopes [][]string) {
+	savedScopes, savedClass, savedLocals := r.scopes, r.currentClass, r.locals
+	r.scopes, r.locals = nil, nil
+	for _, names := range scopes {
+		r.beginScope()
+		for _, name := range names {
+			r.topScope()[name] = true
+			switch {
+			case name == "this" && r.currentClass == NOT_CLASS:
+				r.currentClass = CLASS
+			case name == "super":
+				r.currentClass = SUBCLASS
+			}
+		}
+	}
+	r.resolveExpr(expr)
+	r.reportWarnings()
+	r.scopes, r.currentClass, r.locals = savedScopes, savedClass, savedLocals
+	r.traitScopes = r.traitScopes[:len(savedScopes)]
+	r.declScopes = r.declScopes[:len(savedScopes)]
 }
 
 func (r *T) resolveStmt(statement ast.Stmt) {
@@ -105,12 +161,27 @@ func (r *T) resolveLocal(expr ast.Expr, name token.T) {
 	for i := len(r.scopes) - 1; i >= 0; i-- {
 		if _, ok := r.scopes[i][name.Lexeme()]; ok {
 			r.resolver.Resolve(expr, name, depth)
+			r.useLocal(i, expr, name)
+			r.bindUse(expr, name, r.declScopes[i][name.Lexeme()])
 			return
 		}
 		depth++
 	}
 
 	// Not found. Assume it is global.
+	r.bindUse(expr, name, nil)
+}
+
+// bindUse records a use of a variable in r.Bindings. `this` and `super` are
+// not variables, and aren't recorded.
+func (r *T) bindUse(expr ast.Expr, name token.T, decl *Declaration) {
+	if r.Bindings == nil {
+		return
+	}
+	switch expr.(type) {
+	case *ast.Variable, *ast.Assign:
+		r.Bindings.use(name, decl)
+	}
 }
 
 func (r *T) resolveFunction(function *ast.Function, ft FunctionType) {
@@ -118,7 +189,7 @@ func (r *T) resolveFunction(function *ast.Function, ft FunctionType) {
 	r.currentFunction = ft
 	r.beginScope()
 	for _, param := range function.Params {
-		r.declare(param)
+		r.declare(param, ParameterDeclaration, function)
 		r.define(param)
 	}
 	r.ResolveStmtList(function.Body)
@@ -158,19 +229,18 @@ func (r *T) Visit_ReturnStmt(stmt *ast.Return) {
 	}
 }
 
-func (r *T) Visit_PanicStmt(stmt *ast.Panic) {
-	r.resolveExpr(stmt.Expression)
+func (r *T) Visit_BadStmtStmt(stmt *ast.BadStmt) {
 }
 
 func (r *T) Visit_VarInitializedStmt(stmt *ast.VarInitialized) {
 	//r.declare(stmt.Name) // declare here to prevent var a=a+1;
 	r.resolveExpr(stmt.Initializer)
-	r.declare(stmt.Name) // declare here to allow var a=a+1;
+	r.declare(stmt.Name, VariableDeclaration, stmt) // declare here to allow var a=a+1;
 	r.define(stmt.Name)
 }
 
 func (r *T) Visit_VarUninitializedStmt(stmt *ast.VarUninitialized) {
-	r.declare(stmt.Name)
+	r.declare(stmt.Name, VariableDeclaration, stmt)
 	r.define(stmt.Name)
 }
 
@@ -178,7 +248,7 @@ func (r *T) Visit_ClassStmt(stmt *ast.Class) {
 	enclosingClass := r.setCurrentClass(CLASS)
 	defer r.setCurrentClass(enclosingClass)
 
-	r.declare(stmt.Name)
+	r.declare(stmt.Name, ClassDeclaration, stmt)
 	r.define(stmt.Name)
 
 	if stmt.Superclass != nil {
@@ -189,6 +259,7 @@ func (r *T) Visit_ClassStmt(stmt *ast.Class) {
 		}
 		r.resolveExpr(stmt.Superclass)
 	}
+	r.resolveWithClause(stmt.Name, "class", stmt.Traits, stmt.Methods)
 
 	if stmt.Superclass != nil {
 		r.setCurrentClass(SUBCLASS)
@@ -203,15 +274,24 @@ func (r *T) Visit_ClassStmt(stmt *ast.Class) {
 
 	for _, method := range stmt.Methods {
 		var declaration FunctionType = METHOD
-		if method.Name.Lexeme() == "init" {
+		if method.Name.Lexeme() == "init" && method.Kind == ast.OrdinaryFunction {
 			declaration = INITIALIZER
 		}
 		r.resolveFunction(method, declaration)
 	}
+
+	// Static methods and static field initializers see `this` as the class
+	// itself, bound at the same depth that instance methods see the instance.
+	for _, method := range stmt.StaticMethods {
+		r.resolveFunction(method, STATIC_METHOD)
+	}
+	for _, field := range stmt.StaticFields {
+		r.resolveExpr(field.Initializer)
+	}
 }
 
 func (r *T) Visit_FunctionStmt(stmt *ast.Function) {
-	r.declare(stmt.Name)
+	r.declare(stmt.Name, FunctionDeclaration, stmt)
 	r.define(stmt.Name)
 
 	r.resolveFunction(stmt, FUNCTION)
@@ -230,6 +310,9 @@ func (r *T) Visit_WhileStmt(stmt *ast.While) {
 	r.resolveStmt(stmt.Body)
 }
 
+func (r *T) Visit_BadExprExpr(expr *ast.BadExpr) {
+}
+
 func (r *T) Visit_GroupingExpr(expr *ast.Grouping) {
 	r.resolveExpr(expr.Expression)
 }
@@ -250,6 +333,17 @@ func (r *T) Visit_SetExpr(expr *ast.Set) {
 	r.resolveExpr(expr.Value)
 }
 
+func (r *T) Visit_IndexExpr(expr *ast.Index) {
+	r.resolveExpr(expr.Object)
+	r.resolveExpr(expr.Index)
+}
+
+func (r *T) Visit_SetIndexExpr(expr *ast.SetIndex) {
+	r.resolveExpr(expr.Object)
+	r.resolveExpr(expr.Index)
+	r.resolveExpr(expr.Value)
+}
+
 func (r *T) Visit_UnaryExpr(expr *ast.Unary) {
 	r.resolveExpr(expr.Right)
 }
@@ -285,7 +379,7 @@ func (r *T) Visit_ThisExpr(expr *ast.This) {
 func (r *T) Visit_SuperExpr(expr *ast.Super) {
 	if r.currentClass == NOT_CLASS {
 		r.lox.Error(expr.Keyword, "Can't use 'super' outside of a class.")
-	} else if r.currentClass != SUBCLASS {
+	} else if r.currentClass != SUBCLASS && r.currentClass != TRAIT {
 		r.lox.Error(expr.Keyword,
 			"Can't use 'super' in a class with no superclass.")
 	}
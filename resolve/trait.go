@@ -0,0 +1,129 @@
+package resolve
+
+import (
+	"fmt"
+
+	"github.com/perlmonger42/go-lox/ast"
+	"github.com/perlmonger42/go-lox/token"
+)
+
+// The resolver remembers trait declarations by name, scope by scope, so that
+// it can report conflicting trait members in a `with` clause before the
+// program runs. A nil entry records a non-trait declaration that shadows any
+// trait of the same name. Traits that can't be found statically are checked
+// by the interpreter instead.
+
+func (r *T) recordTrait(name token.T, trait *ast.Trait) {
+	if n := len(r.traitScopes); n > 0 {
+		r.traitScopes[n-1][name.Lexeme()] = trait
+	} else {
+		r.globalTraits[name.Lexeme()] = trait
+	}
+}
+
+func (r *T) lookupTrait(name token.T) *ast.Trait {
+	for n := len(r.traitScopes) - 1; n >= 0; n-- {
+		if trait, ok := r.traitScopes[n][name.Lexeme()]; ok {
+			return trait
+		}
+	}
+	return r.globalTraits[name.Lexeme()]
+}
+
+// traitMember is a function provided by a trait, along with the trait whose
+// `with` clause brought it in.
+type traitMember struct {
+	trait  token.T
+	method *ast.Function
+}
+
+// traitMembers returns the statically known members of trait, keyed by
+// member name.
+func (r *T) traitMembers(trait *ast.Trait) map[string]traitMember {
+	members := make(map[string]traitMember)
+	for _, traitVar := range trait.Traits {
+		if included := r.lookupTrait(traitVar.Name); included != nil && included != trait {
+			for name, member := range r.traitMembers(included) {
+				members[name] = member
+			}
+		}
+	}
+	for _, method := range trait.Methods {
+		members[method.MemberName()] = traitMember{trait.Name, method}
+	}
+	return members
+}
+
+// resolveWithClause resolves the traits named by the `with` clause of class
+// or trait owner, and reports any member that two of them provide unless
+// owner declares it itself.
+func (r *T) resolveWithClause(
+	owner token.T,
+	kind string,
+	traits []*ast.Variable,
+	own []*ast.Function,
+) {
+	declared := make(map[string]bool)
+	for _, method := range own {
+		declared[method.MemberName()] = true
+	}
+
+	providers := make(map[string]traitMember)
+	for _, traitVar := range traits {
+		if traitVar.Name.Lexeme() == owner.Lexeme() {
+			r.lox.Error(traitVar.Name,
+				fmt.Sprintf("A %s can't include itself.", kind))
+			continue
+		}
+		r.resolveExpr(traitVar)
+
+		trait := r.lookupTrait(traitVar.Name)
+		if trait == nil {
+			continue
+		}
+		for name, member := range r.traitMembers(trait) {
+			if declared[name] {
+				continue
+			}
+			if other, ok := providers[name]; ok {
+				if other.method != member.method {
+					r.lox.Error(traitVar.Name, fmt.Sprintf(
+						"Traits %s and %s both provide '%s'; %s must define its own.",
+						other.trait.Lexeme(), traitVar.Name.Lexeme(), name,
+						owner.Lexeme()))
+					declared[name] = true // report each conflict once
+				}
+				continue
+			}
+			providers[name] = traitMember{traitVar.Name, member.method}
+		}
+	}
+}
+
+func (r *T) Visit_TraitStmt(stmt *ast.Trait) {
+	r.declare(stmt.Name, TraitDeclaration, stmt)
+	r.define(stmt.Name)
+	r.resolveWithClause(stmt.Name, "trait", stmt.Traits, stmt.Methods)
+	r.recordTrait(stmt.Name, stmt)
+
+	enclosingClass := r.setCurrentClass(TRAIT)
+	defer r.setCurrentClass(enclosingClass)
+
+	// Trait methods are linked into each including class beneath a scope
+	// binding `super`, just as subclass methods are.
+	r.beginScope()
+	r.scopes[len(r.scopes)-1]["super"] = true
+	defer r.endScope()
+
+	r.beginScope()
+	r.scopes[len(r.scopes)-1]["this"] = true
+	defer r.endScope()
+
+	for _, method := range stmt.Methods {
+		var declaration FunctionType = METHOD
+		if method.Name.Lexeme() == "init" && method.Kind == ast.OrdinaryFunction {
+			declaration = INITIALIZER
+		}
+		r.resolveFunction(method, declaration)
+	}
+}
@@ -0,0 +1,118 @@
+package resolve
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/perlmonger42/go-lox/ast"
+	"github.com/perlmonger42/go-lox/token"
+)
+
+// The resolver remembers local declarations, scope by scope, so that it can
+// warn about locals and parameters that are never read, and about locals
+// that shadow others in enclosing scopes. It also warns about statements
+// that follow a `return`, since they can't be reached. Warnings go through
+// lox.Warn, so they don't stop the program from running, and they can be
+// suppressed with `// lox:ignore unused`, `shadow` or `unreachable`. They
+// are held until a list of top-level statements has been resolved, and then
+// reported in the order of their positions, since a scope's unused names
+// are only known at its end.
+//
+// Names beginning with `_` are never reported as unused, so that, for
+// instance, a method can take a parameter it has no need of.
+
+// A local is a declaration in a local scope.
+type local struct {
+	name token.T
+	kind DeclarationKind
+	used bool
+}
+
+// A warning is one that hasn't been reported yet.
+type warning struct {
+	tok     token.T
+	rule    string
+	message string
+}
+
+// warn holds a warning to be reported by reportWarnings.
+func (r *T) warn(tok token.T, rule, message string) {
+	r.warnings = append(r.warnings, warning{tok, rule, message})
+}
+
+// reportWarnings reports the warnings held, in the order of their
+// positions.
+func (r *T) reportWarnings() {
+	sort.SliceStable(r.warnings, func(i, j int) bool {
+		a, b := r.warnings[i].tok.Whence(), r.warnings[j].tok.Whence()
+		return a.Line() < b.Line() || a.Line() == b.Line() && a.Column() < b.Column()
+	})
+	for _, w := range r.warnings {
+		r.lox.Warn(w.tok, w.rule, w.message)
+	}
+	r.warnings = nil
+}
+
+// declareLocal records a declaration in the innermost local scope, warning
+// if it shadows one in an enclosing local scope.
+func (r *T) declareLocal(name token.T, kind DeclarationKind) {
+	n := len(r.locals)
+	if n == 0 {
+		return
+	}
+	for i := n - 2; i >= 0; i-- {
+		if outer, ok := r.locals[i][name.Lexeme()]; ok {
+			r.warn(name, "shadow", fmt.Sprintf(
+				"`%s` shadows the %s declared on line %d.",
+				name.Lexeme(), outer.kind, outer.name.Whence().Line()))
+			break
+		}
+	}
+	r.locals[n-1][name.Lexeme()] = &local{name: name, kind: kind}
+}
+
+// useLocal records a read of the local declared in scope i.
+func (r *T) useLocal(i int, expr ast.Expr, name token.T) {
+	if _, ok := expr.(*ast.Variable); !ok || i >= len(r.locals) {
+		return
+	}
+	if l, ok := r.locals[i][name.Lexeme()]; ok {
+		l.used = true
+	}
+}
+
+// warnUnused warns about the variables and parameters in the innermost
+// local scope that were never read.
+func (r *T) warnUnused() {
+	var unused []*local
+	for _, l := range r.locals[len(r.locals)-1] {
+		if !l.used && !strings.HasPrefix(l.name.Lexeme(), "_") &&
+			(l.kind == VariableDeclaration || l.kind == ParameterDeclaration) {
+			unused = append(unused, l)
+		}
+	}
+	for _, l := range unused {
+		what := "Local variable"
+		if l.kind == ParameterDeclaration {
+			what = "Parameter"
+		}
+		r.warn(l.name, "unused", fmt.Sprintf(
+			"%s `%s` is never used.", what, l.name.Lexeme()))
+	}
+}
+
+// warnUnreachable warns about the statements of a list that follow a
+// `return`, once, at the first of them.
+func (r *T) warnUnreachable(statements []ast.Stmt) {
+	for i, stmt := range statements {
+		if ret, ok := stmt.(*ast.Return); ok && i+1 < len(statements) {
+			tok := ast.StmtToken(statements[i+1])
+			if tok == nil {
+				tok = ret.Keyword
+			}
+			r.warn(tok, "unreachable", "Unreachable code after `return`.")
+			return
+		}
+	}
+}
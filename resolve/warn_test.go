@@ -0,0 +1,55 @@
+package resolve
+
+import (
+	"github.com/perlmonger42/go-lox/ast"
+	"github.com/perlmonger42/go-lox/config"
+	"github.com/perlmonger42/go-lox/lox"
+	"github.com/perlmonger42/go-lox/parse"
+	"github.com/perlmonger42/go-lox/scan"
+	"github.com/perlmonger42/go-lox/token"
+)
+
+type noLocals struct{}
+
+func (noLocals) Resolve(expr ast.Expr, name token.T, depth int) {}
+
+func resolveWithWarnings(text string) {
+	config := config.New()
+	config.Warnings = true
+	lox := lox.New(config)
+	stmts := parse.New(lox, scan.New(lox, text).ScanTokens()).Parse()
+	New(lox, noLocals{}).ResolveStmtList(stmts)
+}
+
+func ExampleT_warnings() {
+	resolveWithWarnings(`
+fun f(a, b, _c) {
+  var total = 0;
+  {
+    var a = 1;
+    print a;
+  }
+  return total;
+  print b;
+}
+`)
+	// Output:
+	// [line 2] Warning at 'a': Parameter `a` is never used. (unused)
+	// [line 5] Warning at 'a': `a` shadows the parameter declared on line 2. (shadow)
+	// [line 9] Warning at 'print': Unreachable code after `return`. (unreachable)
+}
+
+func ExampleT_warningsIgnored() {
+	resolveWithWarnings(`
+fun f(a) { // lox:ignore unused
+  // lox:ignore
+  var unused = 1;
+  var x = 1;
+  { var x = 2; print x; } // lox:ignore shadow
+  return x;
+  print 3; // lox:ignore unused
+}
+`)
+	// Output:
+	// [line 8] Warning at 'print': Unreachable code after `return`. (unreachable)
+}
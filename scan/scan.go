@@ -5,6 +5,7 @@ package scan
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"unicode"
 	"unicode/utf8"
@@ -28,6 +29,9 @@ type Scanner struct {
 	start   int // index in source of first char of current token
 	current int // index in source of char under read head
 	line    int // line number of current character
+	bol     int // index in source of the first char of the current line
+
+	comments []token.Comment // comments waiting to lead the next token
 }
 
 func New(lox *lox.T, source string) T {
@@ -47,23 +51,40 @@ func (s *Scanner) isAtEnd() bool {
 	return s.current >= len(s.source)
 }
 
+// startPos returns the position of the current token's first character.
+func (s *Scanner) startPos() token.Pos {
+	column := utf8.RuneCountInString(s.source[s.bol:s.start]) + 1
+	return token.NewLineColumn(s.line, column)
+}
+
 func (s *Scanner) newToken(typ token.Type, val Value) token.T {
 	text := s.source[s.start:s.current]
-	return token.New(typ, text, val, token.NewPos(s.line))
+	return token.New(typ, text, val, s.startPos())
 }
 
-func (s *Scanner) addFullToken(tok token.T) token.T {
+// appendToken adds tok to the token list, attaching any comments that
+// precede it.
+func (s *Scanner) appendToken(tok token.T) token.T {
+	if len(s.comments) > 0 {
+		if t, ok := tok.(*token.Token); ok {
+			t.Trivia_ = &token.Trivia{Leading: s.comments}
+			s.comments = nil
+		}
+	}
 	s.tokens = append(s.tokens, tok)
 	return tok
 }
 
+func (s *Scanner) addFullToken(tok token.T) token.T {
+	return s.appendToken(tok)
+}
+
 func (s *Scanner) addToken(typ token.Type) token.T {
 	tok := s.newToken(typ, nil)
 	if s.lox.Config.TraceScanTokens {
 		fmt.Printf("token: %s\n", tok)
 	}
-	s.tokens = append(s.tokens, tok)
-	return tok
+	return s.appendToken(tok)
 }
 
 func (s *Scanner) addTokenWithValue(typ token.Type, literal token.Value) token.T {
@@ -71,8 +92,32 @@ func (s *Scanner) addTokenWithValue(typ token.Type, literal token.Value) token.T
 	if s.lox.Config.TraceScanTokens {
 		fmt.Printf("token: %s\n", tok)
 	}
-	s.tokens = append(s.tokens, tok)
-	return tok
+	return s.appendToken(tok)
+}
+
+// addComment keeps the comment just scanned as trivia. A comment on the
+// same line as the preceding token trails that token; any other comment
+// leads the next token.
+func (s *Scanner) addComment() {
+	comment := token.Comment{
+		Text: s.source[s.start:s.current],
+		Pos:  s.startPos(),
+	}
+	if n := len(s.tokens); n > 0 && len(s.comments) == 0 {
+		prev, ok := s.tokens[n-1].(*token.Token)
+		if ok && prev.Whence().Line() == s.line {
+			if prev.Trivia_ == nil {
+				prev.Trivia_ = &token.Trivia{}
+			}
+			if prev.Trivia_.Trailing == nil {
+				prev.Trivia_.Trailing = &comment
+				s.lox.NoteComment(s.line, comment.Text)
+				return
+			}
+		}
+	}
+	s.comments = append(s.comments, comment)
+	s.lox.NoteComment(s.line+1, comment.Text)
 }
 
 func (s *Scanner) ScanTokens() []token.T {
@@ -81,8 +126,8 @@ func (s *Scanner) ScanTokens() []token.T {
 		s.scanToken()
 	}
 
-	pos := token.NewPos(s.line)
-	s.tokens = append(s.tokens, token.New(token.EOF, "", nil, pos))
+	s.start = s.current
+	s.appendToken(token.New(token.EOF, "", nil, s.startPos()))
 	return s.tokens
 }
 
@@ -111,6 +156,8 @@ func (s *Scanner) scanToken() token.T {
 		s.addToken(token.Plus)
 	case ';':
 		s.addToken(token.Semicolon)
+	case ':':
+		s.addToken(token.Colon)
 	case '*':
 		s.addToken(token.Star)
 	case '!':
@@ -127,6 +174,7 @@ func (s *Scanner) scanToken() token.T {
 			for !s.isAtEnd() && s.peek() != '\n' {
 				s.advance()
 			}
+			s.addComment()
 		} else {
 			s.addToken(token.Slash)
 		}
@@ -138,6 +186,7 @@ func (s *Scanner) scanToken() token.T {
 		// Ignore whitespace.
 	case '\n':
 		s.line++
+		s.bol = s.current
 
 	default:
 		if isDigit(c) {
@@ -168,9 +217,21 @@ var keywords map[string]token.Type = map[string]token.Type{
 	"return": token.Return,
 	"super":  token.Super,
 	"this":   token.This,
+	"trait":  token.Trait,
 	"true":   token.True,
 	"var":    token.Var,
 	"while":  token.While,
+	"with":   token.With,
+}
+
+// Keywords returns the reserved words of Lox, sorted.
+func Keywords() []string {
+	words := make([]string, 0, len(keywords))
+	for word := range keywords {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	return words
 }
 
 func (s *Scanner) scanIdentifier() {
@@ -36,7 +36,7 @@ func ExampleParens() {
 func ExampleUnexpectedInput() {
 	dumpTokensWithLineNumbers("(~")
 	// Output:
-	// [line 1] Error at 'Other': Unexpected character ('~').
+	// [line 1] Error at '~': Unexpected character ('~').
 	// LeftParen: `(` at line 1
 	// Other: `~` at line 1
 	// EOF at line 1
@@ -49,8 +49,8 @@ func ExampleUnicode() {
 	dumpTokens("༺ Ťėšťǐňġ, ṫẹṡṫịṅḡ, 𝕠𝕟𝕖, 𝕥𝕨𝕠, 𝕥𝕙𝕣𝕖𝕖 ༻")
 
 	// Output:
-	// [line 1] Error at 'Other': Unexpected character ('༺').
-	// [line 1] Error at 'Other': Unexpected character ('༻').
+	// [line 1] Error at '༺': Unexpected character ('༺').
+	// [line 1] Error at '༻': Unexpected character ('༻').
 	// Other: `༺`
 	// Identifier: `Ťėšťǐňġ`
 	// Comma: `,`
@@ -130,9 +130,9 @@ func ExampleStrings() {
  		 "`,
 	)
 	// Output:
-	// [line 5] Error at 'InvalidString': Unterminated string literal
-	// [line 10] Error at 'InvalidString': Unterminated string literal
-	// [line 13] Error at 'InvalidString': Unterminated string literal
+	// [line 5] Error at '"unterminated': Unterminated string literal
+	// [line 10] Error at '"x\': Unterminated string literal
+	// [line 13] Error at '"': Unterminated string literal
 	// String: `""` = "" at line 1
 	// String: `"x"` = "x" at line 2
 	// InvalidString: `"unterminated` at line 5
@@ -149,7 +149,7 @@ func ExampleStringEscapeBeforeEof() {
  	  "abc\
  	`)
 	// Output:
-	// [line 3] Error at 'InvalidString': Unterminated string literal
+	// [line 3] Error at '"abc\': Unterminated string literal
 	// InvalidString: `"abc\` at line 3
 	// EOF at line 4
 }
@@ -165,10 +165,10 @@ func ExampleNumbers() {
  		1e  2E- 3e+
  	`)
 	// Output:
-	// [line 7] Error at 'InvalidNumber': Invalid number literal (7e5000): strconv.ParseFloat: parsing "7e5000": value out of range
-	// [line 8] Error at 'InvalidNumber': Invalid number literal (1e): strconv.ParseFloat: parsing "1e": invalid syntax
-	// [line 8] Error at 'InvalidNumber': Invalid number literal (2E-): strconv.ParseFloat: parsing "2E-": invalid syntax
-	// [line 8] Error at 'InvalidNumber': Invalid number literal (3e+): strconv.ParseFloat: parsing "3e+": invalid syntax
+	// [line 7] Error at '7e5000': Invalid number literal (7e5000): strconv.ParseFloat: parsing "7e5000": value out of range
+	// [line 8] Error at '1e': Invalid number literal (1e): strconv.ParseFloat: parsing "1e": invalid syntax
+	// [line 8] Error at '2E-': Invalid number literal (2E-): strconv.ParseFloat: parsing "2E-": invalid syntax
+	// [line 8] Error at '3e+': Invalid number literal (3e+): strconv.ParseFloat: parsing "3e+": invalid syntax
 	// Dot: `.`
 	// Number: `1` = 1
 	// Dot: `.`
@@ -221,7 +221,7 @@ func ExampleKeywords() {
 	dumpTokens(`
          an
  		and class else false for fun if nil or
- 		print return super this true var while
+ 		print return super this trait true var while with
  		whiled
  	`)
 	// Output:
@@ -239,9 +239,11 @@ func ExampleKeywords() {
 	// Return: `return`
 	// Super: `super`
 	// This: `this`
+	// Trait: `trait`
 	// True: `true` = true
 	// Var: `var`
 	// While: `while`
+	// With: `with`
 	// Identifier: `whiled`
 	// EOF
 }
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/perlmonger42/go-lox/config"
+	"github.com/perlmonger42/go-lox/loxtest"
+)
+
+// testCommand implements `go-lox test`, which runs the unit tests in Lox
+// test files. It returns the process's exit status: 1 if any test failed.
+func testCommand(args []string) int {
+	flags := flag.NewFlagSet("test", flag.ExitOnError)
+	verbose := flags.Bool("v", false, "report every test, and its output")
+	match := flags.String("run", "", "run only the tests matching `regexp`")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: go-lox test [-v] [-run regexp] [files or directories...]\n")
+		fmt.Fprintf(os.Stderr, "Flags:\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	runner := &loxtest.Runner{
+		Out:          os.Stdout,
+		Verbose:      *verbose,
+		Capabilities: config.AllCapabilities,
+	}
+	if *sandbox {
+		runner.Capabilities = config.NoCapabilities
+	}
+	if *match != "" {
+		re, err := regexp.Compile(*match)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "go-lox test: -run: %s\n", err)
+			return 64 // see "sysexits.h"
+		}
+		runner.Match = re
+	}
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	ok, err := runner.Run(paths)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "go-lox test: %s\n", err)
+		return 66 // see "sysexits.h"
+	}
+	if !ok {
+		return 1
+	}
+	return 0
+}
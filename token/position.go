@@ -3,16 +3,23 @@ package token
 import "fmt"
 
 type Pos interface {
-	Line() int // just the line number, for now
+	Line() int   // 1-based line number
+	Column() int // 1-based column, counted in characters; 0 if unknown
 	String() string
 }
 
 func NewPos(line int) Pos {
-	return &Position{line}
+	return &Position{line: line}
+}
+
+// NewLineColumn returns the position of the character at column of line.
+func NewLineColumn(line, column int) Pos {
+	return &Position{line: line, column: column}
 }
 
 type Position struct {
-	line int
+	line   int
+	column int
 }
 
 var _ Pos = &Position{}
@@ -24,3 +31,7 @@ func (p *Position) String() string {
 func (p *Position) Line() int {
 	return p.line
 }
+
+func (p *Position) Column() int {
+	return p.column
+}
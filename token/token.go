@@ -6,27 +6,30 @@ import "fmt"
 
 // T represents a token or text string returned from the scanner.
 type T interface {
-	Type() Type     // The type of this item.
-	Lexeme() string // The text of this item.
-	Literal() Value // The value of the literal, if literal
-	Whence() Pos    // The position at which this token appears
+	Type() Type      // The type of this item.
+	Lexeme() string  // The text of this item.
+	Literal() Value  // The value of the literal, if literal
+	Whence() Pos     // The position at which this token appears
+	Trivia() *Trivia // The comments attached to this token, if any
 }
 
 type Token struct {
-	Type_    Type   // The type of this item.
-	Lexeme_  string // The text of this item.
-	Literal_ Value  // The value of the literal, if literal
-	Whence_  Pos    // The position at which this token appears
+	Type_    Type    // The type of this item.
+	Lexeme_  string  // The text of this item.
+	Literal_ Value   // The value of the literal, if literal
+	Whence_  Pos     // The position at which this token appears
+	Trivia_  *Trivia // The comments attached to this token, if any
 }
 
 func New(t Type, text string, literal Value, pos Pos) T {
-	return &Token{t, text, literal, pos}
+	return &Token{Type_: t, Lexeme_: text, Literal_: literal, Whence_: pos}
 }
 
-func (t *Token) Type() Type     { return t.Type_ }
-func (t *Token) Lexeme() string { return t.Lexeme_ }
-func (t *Token) Literal() Value { return t.Literal_ }
-func (t *Token) Whence() Pos    { return t.Whence_ }
+func (t *Token) Type() Type      { return t.Type_ }
+func (t *Token) Lexeme() string  { return t.Lexeme_ }
+func (t *Token) Literal() Value  { return t.Literal_ }
+func (t *Token) Whence() Pos     { return t.Whence_ }
+func (t *Token) Trivia() *Trivia { return t.Trivia_ }
 
 // Type identifies the type of lex items.
 type Type int
@@ -43,11 +46,12 @@ const (
 	RightBrace   // "}"
 	Comma        // ","
 	Dot          // "."
-	Minus        // "}"
+	Minus        // "-"
 	Plus         // "+"
 	Star         // "*"
 	Slash        // "/"
 	Semicolon    // ";"
+	Colon        // ":"
 	Bang         // "!"
 	BangEqual    // "!="
 	Equal        // "="
@@ -71,9 +75,11 @@ const (
 	Return // "return"
 	Super  // "super"
 	This   // "this"
+	Trait  // "trait"
 	True   // "true"
 	Var    // "var"
 	While  // "while"
+	With   // "with"
 
 	// Tokens with literal value
 	String        // quoted string (includes quotes)
@@ -85,6 +91,78 @@ const (
 	Other // unrecognized character
 )
 
+// ParseType returns the Type whose String is name.
+func ParseType(name string) (Type, bool) {
+	for t := EOF; t <= Other; t++ {
+		if t.String() == name {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+// Describe returns what error messages call a token of type t: its text in
+// backquotes, for punctuation and keywords, or the kind of token it is.
+func (t Type) Describe() string {
+	if t < 0 || t > Other {
+		return t.String()
+	}
+	return descriptions[t]
+}
+
+var descriptions = [...]string{
+	EOF:          "end of file",
+	LeftParen:    "`(`",
+	RightParen:   "`)`",
+	LeftBrack:    "`[`",
+	RightBrack:   "`]`",
+	LeftBrace:    "`{`",
+	RightBrace:   "`}`",
+	Comma:        "`,`",
+	Dot:          "`.`",
+	Minus:        "`-`",
+	Plus:         "`+`",
+	Star:         "`*`",
+	Slash:        "`/`",
+	Semicolon:    "`;`",
+	Colon:        "`:`",
+	Bang:         "`!`",
+	BangEqual:    "`!=`",
+	Equal:        "`=`",
+	EqualEqual:   "`==`",
+	Less:         "`<`",
+	LessEqual:    "`<=`",
+	Greater:      "`>`",
+	GreaterEqual: "`>=`",
+
+	And:    "`and`",
+	Class:  "`class`",
+	Else:   "`else`",
+	False:  "`false`",
+	For:    "`for`",
+	Fun:    "`fun`",
+	If:     "`if`",
+	Nil:    "`nil`",
+	Or:     "`or`",
+	Print:  "`print`",
+	Return: "`return`",
+	Super:  "`super`",
+	This:   "`this`",
+	Trait:  "`trait`",
+	True:   "`true`",
+	Var:    "`var`",
+	While:  "`while`",
+	With:   "`with`",
+
+	String:        "string",
+	InvalidString: "invalid string",
+	Number:        "number",
+	InvalidNumber: "invalid number",
+	Identifier:    "identifier",
+
+	Other: "unrecognized character",
+}
+
 func (i Token) String() string {
 	if i.Type() == EOF {
 		return "EOF"
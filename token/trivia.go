@@ -0,0 +1,16 @@
+package token
+
+// A Comment is a `//` comment. The scanner doesn't pass comments to the
+// parser, but keeps them as trivia on nearby tokens so that tools such as
+// the formatter can reproduce them.
+type Comment struct {
+	Text string // the comment, including the leading "//"
+	Pos  Pos
+}
+
+// Trivia holds the comments attached to a token: those on lines of their
+// own just before it, and one that follows it on the same line.
+type Trivia struct {
+	Leading  []Comment
+	Trailing *Comment
+}
@@ -22,41 +22,44 @@ func _() {
 	_ = x[Star-11]
 	_ = x[Slash-12]
 	_ = x[Semicolon-13]
-	_ = x[Bang-14]
-	_ = x[BangEqual-15]
-	_ = x[Equal-16]
-	_ = x[EqualEqual-17]
-	_ = x[Less-18]
-	_ = x[LessEqual-19]
-	_ = x[Greater-20]
-	_ = x[GreaterEqual-21]
-	_ = x[And-22]
-	_ = x[Class-23]
-	_ = x[Else-24]
-	_ = x[False-25]
-	_ = x[For-26]
-	_ = x[Fun-27]
-	_ = x[If-28]
-	_ = x[Nil-29]
-	_ = x[Or-30]
-	_ = x[Print-31]
-	_ = x[Return-32]
-	_ = x[Super-33]
-	_ = x[This-34]
-	_ = x[True-35]
-	_ = x[Var-36]
-	_ = x[While-37]
-	_ = x[String-38]
-	_ = x[InvalidString-39]
-	_ = x[Number-40]
-	_ = x[InvalidNumber-41]
-	_ = x[Identifier-42]
-	_ = x[Other-43]
+	_ = x[Colon-14]
+	_ = x[Bang-15]
+	_ = x[BangEqual-16]
+	_ = x[Equal-17]
+	_ = x[EqualEqual-18]
+	_ = x[Less-19]
+	_ = x[LessEqual-20]
+	_ = x[Greater-21]
+	_ = x[GreaterEqual-22]
+	_ = x[And-23]
+	_ = x[Class-24]
+	_ = x[Else-25]
+	_ = x[False-26]
+	_ = x[For-27]
+	_ = x[Fun-28]
+	_ = x[If-29]
+	_ = x[Nil-30]
+	_ = x[Or-31]
+	_ = x[Print-32]
+	_ = x[Return-33]
+	_ = x[Super-34]
+	_ = x[This-35]
+	_ = x[Trait-36]
+	_ = x[True-37]
+	_ = x[Var-38]
+	_ = x[While-39]
+	_ = x[With-40]
+	_ = x[String-41]
+	_ = x[InvalidString-42]
+	_ = x[Number-43]
+	_ = x[InvalidNumber-44]
+	_ = x[Identifier-45]
+	_ = x[Other-46]
 }
 
-const _Type_name = "EOFLeftParenRightParenLeftBrackRightBrackLeftBraceRightBraceCommaDotMinusPlusStarSlashSemicolonBangBangEqualEqualEqualEqualLessLessEqualGreaterGreaterEqualAndClassElseFalseForFunIfNilOrPrintReturnSuperThisTrueVarWhileStringInvalidStringNumberInvalidNumberIdentifierOther"
+const _Type_name = "EOFLeftParenRightParenLeftBrackRightBrackLeftBraceRightBraceCommaDotMinusPlusStarSlashSemicolonColonBangBangEqualEqualEqualEqualLessLessEqualGreaterGreaterEqualAndClassElseFalseForFunIfNilOrPrintReturnSuperThisTraitTrueVarWhileWithStringInvalidStringNumberInvalidNumberIdentifierOther"
 
-var _Type_index = [...]uint16{0, 3, 12, 22, 31, 41, 50, 60, 65, 68, 73, 77, 81, 86, 95, 99, 108, 113, 123, 127, 136, 143, 155, 158, 163, 167, 172, 175, 178, 180, 183, 185, 190, 196, 201, 205, 209, 212, 217, 223, 236, 242, 255, 265, 270}
+var _Type_index = [...]uint16{0, 3, 12, 22, 31, 41, 50, 60, 65, 68, 73, 77, 81, 86, 95, 100, 104, 113, 118, 128, 132, 141, 148, 160, 163, 168, 172, 177, 180, 183, 185, 188, 190, 195, 201, 206, 210, 215, 219, 222, 227, 231, 237, 250, 256, 269, 279, 284}
 
 func (i Type) String() string {
 	if i < 0 || i >= Type(len(_Type_index)-1) {
@@ -21,6 +21,18 @@ type Value interface {
 	equalsObject(ObjectValue) bool
 }
 
+// IsTruthy reports whether Lox treats a value as true: every value is, except
+// nil and false.
+func IsTruthy(value Value) bool {
+	switch value := value.(type) {
+	case NilValue:
+		return false
+	case BooleanValue:
+		return value.V
+	}
+	return true
+}
+
 type StringValue struct {
 	V string
 }
@@ -124,6 +136,12 @@ type Object interface {
 	EqualsObject(o Object) bool
 }
 
+// An Object may also report its own type name, such as "function" or
+// "instance"; objects that don't are of type "object".
+type TypeNamer interface {
+	TypeName() string
+}
+
 type ObjectValue struct {
 	V Object
 }
@@ -133,7 +151,13 @@ var _ Value = ObjectValue{}
 func (x ObjectValue) Show() string   { return x.V.Show() }
 func (x ObjectValue) String() string { return x.V.String() }
 
-func (x ObjectValue) TypeName() string                  { return "object" }
+func (x ObjectValue) TypeName() string {
+	if namer, ok := x.V.(TypeNamer); ok {
+		return namer.TypeName()
+	}
+	return "object"
+}
+
 func (x ObjectValue) IsNumber() bool                    { return false }
 func (x ObjectValue) IsString() bool                    { return false }
 func (x ObjectValue) IsBoolean() bool                   { return false }
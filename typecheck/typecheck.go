@@ -0,0 +1,420 @@
+// Package typecheck checks the optional type annotations of a Lox program
+// before it runs:
+//
+//	var count: number = 0;
+//	fun greet(name: string, times: number): nil { ... }
+//	class Point { class origin: Point = nil; }
+//
+// An annotation names a basic type (number, string, bool or nil), `any`,
+// `function`, or a class, whose instances (and its subclasses' instances)
+// it accepts; nil is accepted as an instance or function too.
+//
+// The checking is gradual: a mismatch is reported only if one of the types
+// in it comes from an annotation, so unannotated code runs as it always
+// has, reporting its errors when (and if) it reaches them. A type comes
+// from an annotation if it is the declared type of a variable, parameter
+// or result, or was computed from one: a variable without an annotation
+// that is never assigned to after its declaration takes the type of its
+// initializer, and an operator's result is computed from its operands'.
+// Calls to functions and classes declared by name are checked against
+// their parameters, and their numbers, if they have any annotations.
+// Operators are checked only when both operands have basic types, since an
+// instance may overload them.
+package typecheck
+
+import (
+	"fmt"
+
+	"github.com/perlmonger42/go-lox/ast"
+	"github.com/perlmonger42/go-lox/lox"
+	"github.com/perlmonger42/go-lox/resolve"
+	"github.com/perlmonger42/go-lox/token"
+)
+
+// Check checks a resolved program, reporting each type mismatch through
+// lox.Error. bindings must be those the program was resolved with.
+func Check(lox *lox.T, stmts []ast.Stmt, bindings *resolve.Bindings) {
+	c := &checker{
+		lox:         lox,
+		bindings:    bindings,
+		types:       make(map[*resolve.Declaration]typed),
+		classes:     make(map[string]*Class),
+		classOf:     make(map[*ast.Class]*Class),
+		funcs:       make(map[*ast.Function]*Func),
+		annotations: make(map[token.T]Type),
+	}
+	c.declare(stmts)
+	for _, stmt := range stmts {
+		c.stmt(stmt)
+	}
+}
+
+// A typed is the type of a variable or expression, and whether it comes
+// from an annotation.
+type typed struct {
+	t         Type
+	annotated bool
+}
+
+// unknown is the type of whatever the checker doesn't know the type of.
+var unknown = typed{Any, false}
+
+type checker struct {
+	lox      *lox.T
+	bindings *resolve.Bindings
+
+	types       map[*resolve.Declaration]typed // missing means Any
+	classes     map[string]*Class              // by name
+	classOf     map[*ast.Class]*Class
+	funcs       map[*ast.Function]*Func
+	annotations map[token.T]Type
+
+	function *Func // the function being checked, or nil
+	this     Type  // the type of `this` where it is being checked
+}
+
+// declare gives the functions and classes of a program, and the variables
+// and parameters with annotations, their types before any is checked, so
+// that they may be used before their declarations.
+func (c *checker) declare(stmts []ast.Stmt) {
+	var classes []*ast.Class
+	var functions []*ast.Function
+	assigned := make(map[*resolve.Declaration]bool)
+	for _, stmt := range stmts {
+		ast.Inspect(stmt, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.Class:
+				classes = append(classes, n)
+				class := &Class{Name: n.Name.Lexeme()}
+				c.classes[class.Name] = class
+				c.classOf[n] = class
+			case *ast.Function:
+				functions = append(functions, n)
+			case *ast.Assign:
+				assigned[c.bindings.DeclarationOf(n.Name)] = true
+			}
+			return true
+		})
+	}
+
+	for _, fn := range functions {
+		f := &Func{Params: make([]Type, len(fn.Params)), Result: c.annotation(fn.ReturnType)}
+		f.annotated = fn.ReturnType != nil
+		for i := range fn.Params {
+			f.Params[i] = Any
+			if i < len(fn.ParamTypes) && fn.ParamTypes[i] != nil {
+				f.Params[i] = c.annotation(fn.ParamTypes[i])
+				f.annotated = true
+			}
+		}
+		c.funcs[fn] = f
+	}
+	for _, n := range classes {
+		class := c.classOf[n]
+		for _, method := range n.Methods {
+			if method.Name.Lexeme() == "init" && method.Kind == ast.OrdinaryFunction {
+				class.Init = c.funcs[method]
+			}
+		}
+		if class.Init == nil && n.Superclass == nil && len(n.Traits) == 0 {
+			class.Init = &Func{Params: []Type{}, Result: Any}
+		}
+	}
+
+	for _, decl := range c.bindings.Declarations {
+		switch node := decl.Node.(type) {
+		case *ast.Function:
+			if decl.Kind == resolve.ParameterDeclaration {
+				for i, param := range node.Params {
+					if param == decl.Name {
+						c.types[decl] = typed{c.funcs[node].Params[i], true}
+					}
+				}
+			} else if !assigned[decl] {
+				c.types[decl] = typed{c.funcs[node], false}
+			}
+		case *ast.Class:
+			if !assigned[decl] {
+				c.types[decl] = typed{c.classOf[node], false}
+			}
+		case *ast.VarInitialized:
+			if node.Type != nil {
+				c.types[decl] = typed{c.annotation(node.Type), true}
+			} else if assigned[decl] {
+				c.types[decl] = unknown
+			}
+		case *ast.VarUninitialized:
+			c.types[decl] = typed{c.annotation(node.Type), true}
+		}
+	}
+}
+
+// annotation returns the type an annotation names, reporting it (once) if
+// it names none. A missing annotation names Any.
+func (c *checker) annotation(tok token.T) Type {
+	if tok == nil {
+		return Any
+	}
+	if t, ok := c.annotations[tok]; ok {
+		return t
+	}
+	t := c.named(tok.Lexeme())
+	if t == nil {
+		c.lox.Error(tok, fmt.Sprintf("Unknown type `%s`.", tok.Lexeme()))
+		t = Any
+	}
+	c.annotations[tok] = t
+	return t
+}
+
+// typeOf returns the type of the variable a name refers to.
+func (c *checker) typeOf(name token.T) typed {
+	if t, ok := c.types[c.bindings.DeclarationOf(name)]; ok {
+		return t
+	}
+	return unknown
+}
+
+func (c *checker) stmts(stmts []ast.Stmt) {
+	for _, stmt := range stmts {
+		c.stmt(stmt)
+	}
+}
+
+func (c *checker) stmt(stmt ast.Stmt) {
+	switch stmt := stmt.(type) {
+	case *ast.Expression:
+		c.expr(stmt.Expression)
+	case *ast.Print:
+		c.expr(stmt.Expression)
+	case *ast.Return:
+		result := typed{Nil, false}
+		if stmt.Value != nil {
+			result = c.expr(stmt.Value)
+		}
+		if c.function != nil && !assignable(result.t, c.function.Result) {
+			c.lox.Error(stmt.Keyword, fmt.Sprintf(
+				"Cannot return %s from a function returning %s.", result.t, c.function.Result))
+		}
+	case *ast.VarInitialized:
+		c.varInitialized(stmt)
+	case *ast.Function:
+		c.body(stmt, c.this)
+	case *ast.If:
+		c.expr(stmt.Condition)
+		c.stmt(stmt.ThenBranch)
+		if stmt.ElseBranch != nil {
+			c.stmt(stmt.ElseBranch)
+		}
+	case *ast.Block:
+		c.stmts(stmt.Statements)
+	case *ast.While:
+		c.expr(stmt.Condition)
+		c.stmt(stmt.Body)
+	case *ast.Class:
+		class := c.classOf[stmt]
+		if stmt.Superclass != nil {
+			super, ok := c.expr(stmt.Superclass).t.(*Class)
+			if ok {
+				class.Super = super
+				if class.Init == nil && len(stmt.Traits) == 0 {
+					class.Init = super.Init
+				}
+			}
+		}
+		for _, field := range stmt.StaticFields {
+			c.varInitialized(field)
+		}
+		for _, method := range stmt.StaticMethods {
+			c.body(method, class)
+		}
+		for _, method := range stmt.Methods {
+			c.body(method, &Instance{class})
+		}
+	case *ast.Trait:
+		for _, method := range stmt.Methods {
+			c.body(method, Any)
+		}
+	}
+}
+
+func (c *checker) varInitialized(stmt *ast.VarInitialized) {
+	t := c.expr(stmt.Initializer)
+	decl := c.bindings.DeclarationOf(stmt.Name)
+	declared, ok := c.types[decl]
+	if stmt.Type != nil {
+		declared, ok = typed{c.annotation(stmt.Type), true}, true
+	}
+	if ok {
+		if !assignable(t.t, declared.t) && (t.annotated || declared.annotated) {
+			c.lox.Error(stmt.Name, fmt.Sprintf(
+				"Cannot initialize `%s` of type %s with %s.",
+				stmt.Name.Lexeme(), declared.t, t.t))
+		}
+	} else if decl != nil {
+		c.types[decl] = t
+	}
+}
+
+// body checks the body of a function, where `this` has type this.
+func (c *checker) body(fn *ast.Function, this Type) {
+	function, outerThis := c.function, c.this
+	c.function, c.this = c.funcs[fn], this
+	c.stmts(fn.Body)
+	c.function, c.this = function, outerThis
+}
+
+// expr checks an expression and returns its type.
+func (c *checker) expr(expr ast.Expr) typed {
+	switch expr := expr.(type) {
+	case *ast.Grouping:
+		return c.expr(expr.Expression)
+	case *ast.This:
+		if c.this != nil {
+			return typed{c.this, false}
+		}
+	case *ast.Variable:
+		return c.typeOf(expr.Name)
+	case *ast.Literal:
+		switch expr.Value.(type) {
+		case token.NumberValue:
+			return typed{Number, false}
+		case token.StringValue:
+			return typed{String, false}
+		case token.BooleanValue:
+			return typed{Bool, false}
+		case token.NilValue:
+			return typed{Nil, false}
+		}
+	case *ast.Call:
+		return c.call(expr)
+	case *ast.Get:
+		c.expr(expr.Object)
+	case *ast.Unary:
+		return c.unary(expr)
+	case *ast.Binary:
+		return c.binary(expr)
+	case *ast.Logical:
+		left, right := c.expr(expr.Left), c.expr(expr.Right)
+		return typed{join(left.t, right.t), left.annotated || right.annotated}
+	case *ast.Set:
+		c.expr(expr.Object)
+		return c.expr(expr.Value)
+	case *ast.Assign:
+		t := c.expr(expr.Value)
+		declared := c.typeOf(expr.Name)
+		if !assignable(t.t, declared.t) && (t.annotated || declared.annotated) {
+			c.lox.Error(expr.Name, fmt.Sprintf(
+				"Cannot assign %s to `%s` of type %s.", t.t, expr.Name.Lexeme(), declared.t))
+		}
+		return t
+	case *ast.Index:
+		c.expr(expr.Object)
+		c.expr(expr.Index)
+	case *ast.SetIndex:
+		c.expr(expr.Object)
+		c.expr(expr.Index)
+		return c.expr(expr.Value)
+	}
+	return unknown
+}
+
+func (c *checker) call(call *ast.Call) typed {
+	callee := c.expr(call.Callee)
+	args := make([]typed, len(call.Arguments))
+	for i, arg := range call.Arguments {
+		args[i] = c.expr(arg)
+	}
+
+	var f *Func
+	result := unknown
+	switch t := callee.t.(type) {
+	case *Func:
+		f, result = t, typed{t.Result, t.Result != Any}
+	case *Class:
+		f, result = t.Init, typed{&Instance{t}, false}
+	case basic:
+		if t != Any && callee.annotated {
+			c.lox.Error(call.Paren, fmt.Sprintf(
+				"Can only call functions and classes, not %s.", t))
+		}
+		return unknown
+	}
+	if f == nil || f.Params == nil || !f.annotated {
+		return result
+	}
+	if len(args) != len(f.Params) {
+		c.lox.Error(call.Paren, fmt.Sprintf(
+			"Expected %d arguments but got %d.", len(f.Params), len(args)))
+		return result
+	}
+	for i, arg := range args {
+		if !assignable(arg.t, f.Params[i]) {
+			c.lox.Error(call.Paren, fmt.Sprintf(
+				"Argument %d must be %s, not %s.", i+1, f.Params[i], arg.t))
+		}
+	}
+	return result
+}
+
+func (c *checker) unary(expr *ast.Unary) typed {
+	right := c.expr(expr.Right)
+	switch expr.Operator.Type() {
+	case token.Minus:
+		if right.t == Number {
+			return right
+		}
+		if isBasic(right.t) && right.annotated {
+			c.lox.Error(expr.Operator, fmt.Sprintf(
+				"Operand of `-` must be a number, not %s.", right.t))
+		}
+	case token.Bang:
+		if right.t == Bool || right.t == Nil {
+			return typed{Bool, right.annotated}
+		}
+		if isBasic(right.t) && right.annotated {
+			c.lox.Error(expr.Operator, fmt.Sprintf(
+				"Operand of `!` must be bool or nil, not %s.", right.t))
+		}
+	}
+	return unknown
+}
+
+func (c *checker) binary(expr *ast.Binary) typed {
+	left, right := c.expr(expr.Left), c.expr(expr.Right)
+	if !isBasic(left.t) || !isBasic(right.t) {
+		return unknown
+	}
+	annotated := left.annotated || right.annotated
+	op := expr.Operator
+	var message string
+	switch op.Type() {
+	case token.Minus, token.Slash, token.Star:
+		if left.t == Number && right.t == Number {
+			return typed{Number, annotated}
+		}
+		message = fmt.Sprintf("Operands of `%s` must be numbers, not %s and %s.",
+			op.Lexeme(), left.t, right.t)
+	case token.Plus:
+		switch {
+		case left.t == Number && right.t == Number:
+			return typed{Number, annotated}
+		case left.t == String && (right.t == String || right.t == Nil):
+			return typed{String, annotated}
+		}
+		message = fmt.Sprintf("Operands of `+` must be two numbers or two strings, not %s and %s.",
+			left.t, right.t)
+	case token.Greater, token.GreaterEqual, token.Less, token.LessEqual:
+		if left.t == right.t && (left.t == Number || left.t == String) {
+			return typed{Bool, annotated}
+		}
+		message = fmt.Sprintf("Operands of `%s` must be two numbers or two strings, not %s and %s.",
+			op.Lexeme(), left.t, right.t)
+	case token.EqualEqual, token.BangEqual:
+		return typed{Bool, annotated}
+	}
+	if message != "" && annotated {
+		c.lox.Error(op, message)
+	}
+	return unknown
+}
@@ -0,0 +1,92 @@
+package typecheck
+
+import (
+	"github.com/perlmonger42/go-lox/ast"
+	"github.com/perlmonger42/go-lox/config"
+	"github.com/perlmonger42/go-lox/lox"
+	"github.com/perlmonger42/go-lox/parse"
+	"github.com/perlmonger42/go-lox/resolve"
+	"github.com/perlmonger42/go-lox/scan"
+	"github.com/perlmonger42/go-lox/token"
+)
+
+type noLocals struct{}
+
+func (noLocals) Resolve(expr ast.Expr, name token.T, depth int) {}
+
+func check(text string) {
+	lox := lox.New(config.New())
+	stmts := parse.New(lox, scan.New(lox, text).ScanTokens()).Parse()
+	resolver := resolve.New(lox, noLocals{})
+	resolver.Bindings = resolve.NewBindings()
+	resolver.ResolveStmtList(stmts)
+	Check(lox, stmts, resolver.Bindings)
+}
+
+func ExampleCheck() {
+	check(`
+var count: number = "none";
+var name: string = "Lox";
+var total;
+total = name - 1;
+fun greet(who: string, times: number): string {
+  if (times < 1) return nil;
+  return "Hello, " + who;
+}
+greet(name);
+greet(1, 2);
+var n = greet(name, 3) * 2;
+-name;
+name();
+`)
+	// Output:
+	// [line 2] Error at 'count': Cannot initialize `count` of type number with string.
+	// [line 5] Error at '-': Operands of `-` must be numbers, not string and number.
+	// [line 7] Error at 'return': Cannot return nil from a function returning string.
+	// [line 10] Error at ')': Expected 2 arguments but got 1.
+	// [line 11] Error at ')': Argument 1 must be string, not number.
+	// [line 12] Error at '*': Operands of `*` must be numbers, not string and number.
+	// [line 13] Error at '-': Operand of `-` must be a number, not string.
+	// [line 14] Error at ')': Can only call functions and classes, not string.
+}
+
+func ExampleCheck_classes() {
+	check(`
+class Shape {}
+class Circle < Shape {
+  init(radius: number) { this.radius = radius; }
+}
+class Square {}
+var s: Shape = Circle(1);
+s = Square();
+s = nil;
+var c: Circle = Circle("big");
+var t: Triangle;
+fun area(shape: Shape): number { return 0; }
+area(Circle(2));
+area(3);
+`)
+	// Output:
+	// [line 11] Error at 'Triangle': Unknown type `Triangle`.
+	// [line 8] Error at 's': Cannot assign Square to `s` of type Shape.
+	// [line 10] Error at ')': Argument 1 must be number, not string.
+	// [line 14] Error at ')': Argument 1 must be Shape, not number.
+}
+
+func ExampleCheck_unannotated() {
+	check(`
+var x = 1;
+x = "one";
+print x + "!";
+fun f(a, b) { return a + b; }
+print f(1, "two") - 1;
+class Vector { add(other) { return this; } }
+print Vector() + 1;
+fun g() { return -"s"; }
+if (false) print x - "a";
+class A { init(a) {} }
+fun mk() { return A(); }
+print 1 - "x";
+`)
+	// Output:
+}
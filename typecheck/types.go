@@ -0,0 +1,115 @@
+package typecheck
+
+// A Type is the static type of a value.
+type Type interface {
+	String() string
+}
+
+// A basic is a type named by one word.
+type basic string
+
+func (b basic) String() string { return string(b) }
+
+// The basic types. Any is the type of a value that could be anything: the
+// type of unannotated variables, parameters and results, and of whatever the
+// checker can't work out, which it never complains about.
+var (
+	Any    Type = basic("any")
+	Number Type = basic("number")
+	String Type = basic("string")
+	Bool   Type = basic("bool")
+	Nil    Type = basic("nil")
+)
+
+// A Func is the type of a function. Params is nil if its parameters are
+// unknown, as for the `function` annotation.
+type Func struct {
+	Params []Type
+	Result Type
+
+	annotated bool // whether any parameter or the result has an annotation
+}
+
+func (f *Func) String() string { return "function" }
+
+// A Class is the type of a class itself, as a value.
+type Class struct {
+	Name  string
+	Super *Class
+	Init  *Func // its initializer, or nil
+}
+
+func (c *Class) String() string { return "class " + c.Name }
+
+// An Instance is the type of the instances of a class (and its
+// subclasses); an annotation names it by the class's name.
+type Instance struct {
+	Class *Class
+}
+
+func (i *Instance) String() string { return i.Class.Name }
+
+// named returns the type an annotation names, or nil if it names none.
+func (c *checker) named(name string) Type {
+	switch name {
+	case "any":
+		return Any
+	case "number":
+		return Number
+	case "string":
+		return String
+	case "bool":
+		return Bool
+	case "nil":
+		return Nil
+	case "function":
+		return &Func{Result: Any}
+	}
+	if class, ok := c.classes[name]; ok {
+		return &Instance{class}
+	}
+	return nil
+}
+
+// assignable reports whether a value of type from can be stored where type
+// to is declared.
+func assignable(from, to Type) bool {
+	if from == Any || to == Any || from == to {
+		return true
+	}
+	switch to := to.(type) {
+	case *Func:
+		switch from.(type) {
+		case *Func, *Class:
+			return true
+		}
+		return from == Nil
+	case *Instance:
+		if from == Nil {
+			return true
+		}
+		if from, ok := from.(*Instance); ok {
+			for c := from.Class; c != nil; c = c.Super {
+				if c == to.Class {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// isBasic reports whether t is one of the basic types other than Any: a
+// type whose operators can't be overloaded.
+func isBasic(t Type) bool {
+	_, ok := t.(basic)
+	return ok && t != Any
+}
+
+// join returns the type of a value that is of type a or of type b.
+func join(a, b Type) Type {
+	if a == b {
+		return a
+	}
+	return Any
+}
@@ -2,7 +2,7 @@
 while true; do
   sleep .25
   #echo === Scanning... ===
-  if [[  ( ! -f ./go-lox || -n "$(find . -name '*.go' -newer ./go-lox -print | head -n 1)" ) ]] ; then
+  if [[  ( ! -f ./go-lox || -n "$(find . \( -name '*.go' -o -name '*.schema' \) -newer ./go-lox -print | head -n 1)" ) ]] ; then
     clear
 
     # This is a terminal control sequence, proprietary to iTerm,
@@ -15,9 +15,6 @@ while true; do
       # find . -name '*.go' -newer ./go-lox -print -exec go fmt '{}' \;
       echo Generating ...   &&
         (go generate ./...       || (echo Generating FAILED && false)) &&
-      echo Tooling...       &&
-          (go build cmd/generate-ast/generate-ast.go &&
-           ./generate-ast -d ast || (echo Tooling FAILED && false)) &&
       echo Reformatting ... &&
         (go fmt ./...            || (echo Reformatting FAILED && false)) &&
       echo Building ...     &&
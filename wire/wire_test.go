@@ -0,0 +1,35 @@
+package wire
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+func ExampleWriteMessage() {
+	var buf bytes.Buffer
+	WriteMessage(&buf, map[string]interface{}{"seq": 1, "command": "threads"})
+	fmt.Printf("%q\n", buf.String())
+	// Output:
+	// "Content-Length: 29\r\n\r\n{\"command\":\"threads\",\"seq\":1}"
+}
+
+func ExampleReadMessage() {
+	r := bufio.NewReader(strings.NewReader(
+		"content-length: 2\r\nContent-Type: application/json\r\n\r\n{}" +
+			"Content-Length: 4\r\n\r\nnull" +
+			"Content-Type: application/json\r\n\r\n{}"))
+	for {
+		content, err := ReadMessage(r)
+		if err != nil {
+			fmt.Println(err)
+			break
+		}
+		fmt.Println(string(content))
+	}
+	// Output:
+	// {}
+	// null
+	// message has no Content-Length header
+}